@@ -1,54 +1,173 @@
 package main
 
 import (
+	"bytes"
+	_ "embed"
 	"encoding/xml"
 	"flag"
 	"fmt"
+	"go/format"
 	"log"
 	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"text/template"
 )
 
 var (
-	trimPrefix = flag.String("trim-prefix", "", "trim this prefix from interface names")
+	trimPrefix   = flag.String("trim-prefix", "", "trim these comma-separated prefixes from interface names")
+	imports      = make(importMap)
+	pkg          = flag.String("pkg", "", "name of the generated package (default the protocol name)")
+	output       = flag.String("o", "", "write the generated code to this file instead of stdout")
+	templateFile = flag.String("template", "", "generate with this template file instead of the built-in one")
 )
 
+//go:embed objects.tpl
+var objectsTemplate string
+
+func init() {
+	flag.Var(imports, "import", "take the interfaces of `protocol=package` from the Go package with that import path (may be repeated)")
+}
+
+// importMap maps protocol names to the import paths of the Go packages
+// generated from them.
+type importMap map[string]string
+
+func (m importMap) String() string {
+	var s []string
+	for p, pkg := range m {
+		s = append(s, p+"="+pkg)
+	}
+	sort.Strings(s)
+	return strings.Join(s, ",")
+}
+
+func (m importMap) Set(v string) error {
+	i := strings.IndexByte(v, '=')
+	if i <= 0 || i == len(v)-1 {
+		return fmt.Errorf("%q is not protocol=package", v)
+	}
+	m[v[:i]] = v[i+1:]
+	return nil
+}
+
+// Import is a Go package the generated code refers to for interfaces of
+// another protocol.
+type Import struct {
+	Name string
+	Path string
+
+	used bool
+}
+
+// ref is an interface as generated code names it: by its Go name,
+// qualified with the package it was generated into unless that is the
+// package being generated.
+type ref struct {
+	imp  *Import
+	name string
+}
+
+// qualifier returns the prefix for Go identifiers from r's package and
+// marks the package as used.
+func (r ref) qualifier() string {
+	if r.imp == nil {
+		return ""
+	}
+	r.imp.used = true
+	return r.imp.Name + "."
+}
+
+// trimName trims the first matching -trim-prefix from an interface name.
+func trimName(name string) string {
+	for _, prefix := range strings.Split(*trimPrefix, ",") {
+		if prefix != "" && strings.HasPrefix(name, prefix) {
+			return strings.TrimPrefix(name, prefix)
+		}
+	}
+	return name
+}
+
 type Protocol struct {
 	Name       string      `xml:"name,attr"`
 	Interfaces []Interface `xml:"interface"`
+
+	// HasFdEvents is set if an event carries an fd
+	HasFdEvents bool
+	// Imports are the packages of other protocols the protocol refers to
+	Imports []*Import
+	// Package is the name of the generated package
+	Package string
 }
 
-func (p Protocol) analyze() {
+// analyze resolves interface references against names, which holds the
+// interfaces of the protocol and of the ones it imports, keyed by the
+// name they have in the XML.
+func (p *Protocol) analyze(names map[string]ref) error {
 	for i := range p.Interfaces {
-		p.Interfaces[i].analyze()
+		if err := p.Interfaces[i].analyze(names); err != nil {
+			return fmt.Errorf("%s: %s", p.Interfaces[i].FullName, err)
+		}
+		for _, e := range p.Interfaces[i].Events {
+			for _, a := range e.Args {
+				if a.Type == "fd" {
+					p.HasFdEvents = true
+				}
+			}
+		}
 	}
+	return nil
 }
 
 type Interface struct {
 	Name        string `xml:"name,attr"`
+	FullName    string
 	Version     int    `xml:"version,attr"`
 	Description string `xml:"description"`
 
 	Requests []Message `xml:"request"`
 	Events   []Message `xml:"event"`
 	Enums    []Enum    `xml:"enum"`
+
+	// HasDestructor is set if a request destroys the object
+	HasDestructor bool
+	// Global is set if objects of the interface are bound through the
+	// registry: no typed new_id creates them, and it is not wl_display,
+	// which exists from the start
+	Global bool
 }
 
-func (i *Interface) analyze() {
-	if strings.HasPrefix(i.Name, *trimPrefix) {
-		i.Name = i.Name[len(*trimPrefix):]
-	}
+func (i *Interface) analyze(names map[string]ref) error {
+	i.FullName = i.Name
+	i.Name = names[i.FullName].name
 	for j := range i.Requests {
 		i.Requests[j].Kind = "Request"
 		i.Requests[j].Opcode = uint16(j)
 		i.Requests[j].Interface = i.Name
+		if err := i.Requests[j].analyze(names); err != nil {
+			return err
+		}
+		if i.Requests[j].Destructor() {
+			i.HasDestructor = true
+		}
 	}
 	for j := range i.Events {
 		i.Events[j].Kind = "Event"
 		i.Events[j].Opcode = uint16(j)
 		i.Events[j].Interface = i.Name
+		if err := i.Events[j].analyze(names); err != nil {
+			return err
+		}
+	}
+	for j := range i.Enums {
+		if err := i.Enums[j].analyze(i.Name); err != nil {
+			return err
+		}
 	}
+	return nil
 }
 
 type Message struct {
@@ -56,39 +175,160 @@ type Message struct {
 	Interface   string
 	Opcode      uint16
 	Name        string `xml:"name,attr"`
+	Type        string `xml:"type,attr"`
+	Since       int    `xml:"since,attr"`
 	Description string `xml:"description"`
 	Args        []Arg  `xml:"arg"`
+
+	// NewIdType is the Go name of the interface of the object the
+	// message creates, if it has a new_id argument of a known interface,
+	// and NewIdPackage qualifies it like Arg.Package
+	NewIdType    string
+	NewIdPackage string
+}
+
+func (m Message) Destructor() bool {
+	return m.Type == "destructor"
+}
+
+// analyze defaults the version a message appeared in to the first one
+// and resolves the interfaces and enums its arguments refer to.
+func (m *Message) analyze(names map[string]ref) error {
+	if m.Since == 0 {
+		m.Since = 1
+	}
+	for i := range m.Args {
+		if err := m.Args[i].analyze(m.Interface, names); err != nil {
+			return fmt.Errorf("%s %s: %s", strings.ToLower(m.Kind), m.Name, err)
+		}
+		if m.Args[i].NewIdType != "" {
+			m.NewIdType = m.Args[i].NewIdType
+			m.NewIdPackage = m.Args[i].Package
+		}
+	}
+	return nil
 }
 
 type Arg struct {
 	Name      string `xml:"name,attr"`
 	Type      string `xml:"type,attr"`
 	Interface string `xml:"interface,attr"`
+	AllowNull bool   `xml:"allow-null,attr"`
+	Enum      string `xml:"enum,attr"`
+
+	// EnumType is the Go type of the enum the argument holds, if any
+	EnumType string
+	// NewIdType is the Go name of the interface of a new_id argument
+	NewIdType string
+	// ObjectType is the Go name of the interface of an object argument
+	ObjectType string
+	// Package qualifies NewIdType and ObjectType, such as "wayland.",
+	// if the interface belongs to an imported protocol
+	Package string
+}
+
+// analyze resolves the interfaces of new_id and object arguments, and
+// the enum of a, which is either local to the interface the argument
+// belongs to or qualified with its interface name.
+func (a *Arg) analyze(iface string, names map[string]ref) error {
+	if (a.Type == "new_id" || a.Type == "object") && a.Interface != "" {
+		r, ok := names[a.Interface]
+		if !ok {
+			return fmt.Errorf("argument %s: unknown interface %s", a.Name, a.Interface)
+		}
+		if a.Type == "new_id" {
+			a.NewIdType = Exported(r.name)
+		} else {
+			a.ObjectType = Exported(r.name)
+		}
+		a.Package = r.qualifier()
+	}
+	if a.Enum == "" {
+		return nil
+	}
+	enum, pkg := a.Enum, ""
+	if dot := strings.IndexByte(enum, '.'); dot >= 0 {
+		r, ok := names[enum[:dot]]
+		if !ok {
+			return fmt.Errorf("argument %s: unknown interface in enum %s", a.Name, a.Enum)
+		}
+		iface, pkg, enum = r.name, r.qualifier(), enum[dot+1:]
+	}
+	a.EnumType = pkg + Exported(iface, enum)
+	return nil
 }
 
 type Enum struct {
 	Name        string      `xml:"name,attr"`
+	Bitfield    bool        `xml:"bitfield,attr"`
 	Description string      `xml:"description"`
 	Entries     []EnumEntry `xml:"entry"`
+
+	// Type is the name of the Go type of the enum
+	Type string
+}
+
+// analyze parses the entry values and marks entries that repeat the value
+// of an earlier one, which String cannot tell apart.
+func (e *Enum) analyze(iface string) error {
+	e.Type = Exported(iface, e.Name)
+	seen := make(map[uint32]bool)
+	for i := range e.Entries {
+		entry := &e.Entries[i]
+		v, err := strconv.ParseUint(entry.Value, 0, 32)
+		if err != nil {
+			return fmt.Errorf("enum %s: entry %s: %s", e.Name, entry.Name, err)
+		}
+		entry.Number = uint32(v)
+		entry.Alias = seen[entry.Number]
+		seen[entry.Number] = true
+	}
+	return nil
 }
 
 type EnumEntry struct {
 	Name    string `xml:"name,attr"`
-	Value   uint32 `xml:"value,attr"`
+	Value   string `xml:"value,attr"`
+	Since   int    `xml:"since,attr"`
 	Summary string `xml:"summary,attr"`
+
+	Number uint32
+	Alias  bool
 }
 
 var typemap = map[string][2]string{
-	"new_id": {"ObjectId", "proto.ObjectId"},
+	"new_id": {"NewId", "proto.ObjectId"},
 	"object": {"ObjectId", "proto.ObjectId"},
 	"uint":   {"Uint", "uint32"},
 	"string": {"String", "string"},
 	"int":    {"Int", "int32"},
 	"fd":     {"Fd", "uintptr"},
-	"fixed":  {"Fixed", "float32"},
+	"fixed":  {"Fixed", "proto.Fixed"},
 	"array":  {"Array", "[]byte"},
 }
 
+var argTypes = map[string]string{
+	"new_id": "proto.ArgNewId",
+	"object": "proto.ArgObject",
+	"uint":   "proto.ArgUint",
+	"string": "proto.ArgString",
+	"int":    "proto.ArgInt",
+	"fd":     "proto.ArgFd",
+	"fixed":  "proto.ArgFixed",
+	"array":  "proto.ArgArray",
+}
+
+func argGoType(a Arg) string {
+	if a.EnumType != "" {
+		return a.EnumType
+	}
+	t, ok := typemap[a.Type]
+	if !ok {
+		panic(fmt.Errorf("unknown type: %s", a.Type))
+	}
+	return t[1]
+}
+
 func Exported(parts ...string) string {
 	for i := range parts {
 		parts[i] = strings.Replace(strings.Title(strings.Replace(parts[i], "_", " ", -1)), " ", "", -1)
@@ -121,6 +361,33 @@ var funcs = template.FuncMap{
 		}
 		return strings.Join(lines, "\n")
 	},
+	"ArgType": func(typename string) string {
+		t, ok := argTypes[typename]
+		if !ok {
+			panic(fmt.Errorf("unknown type: %s", typename))
+		}
+		return t
+	},
+	// InType and OutType return the Go type of an argument received or
+	// sent by the given side
+	"InType": func(side string, a Arg) string {
+		switch {
+		case a.ObjectType != "":
+			return "*" + a.Package + side + a.ObjectType
+		case a.Type == "object":
+			return "proto.Object"
+		}
+		return argGoType(a)
+	},
+	"OutType": func(side string, a Arg) string {
+		switch {
+		case a.ObjectType != "":
+			return "*" + a.Package + side + a.ObjectType
+		case a.Type == "object", a.Type == "new_id" && a.Interface == "":
+			return "proto.Proxy"
+		}
+		return argGoType(a)
+	},
 	"GoType": func(typename string) string {
 		t, ok := typemap[typename]
 		if !ok {
@@ -137,65 +404,155 @@ var funcs = template.FuncMap{
 	},
 }
 
+// readProtocol parses a protocol XML file.
+func readProtocol(name string) (*Protocol, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	p := new(Protocol)
+	if err := xml.NewDecoder(f).Decode(p); err != nil {
+		return nil, fmt.Errorf("%s: %s", name, err)
+	}
+	return p, nil
+}
+
+// loadProtocols reads the protocol files and merges the ones not in the
+// import map into the protocol to generate. The others only provide the
+// interfaces it may refer to.
+func loadProtocols(files []string) (*Protocol, error) {
+	var (
+		gen     *Protocol
+		names   = make(map[string]ref)
+		pkgs    = make(map[string]*Import)
+		created = map[string]bool{"wl_display": true}
+	)
+	for _, file := range files {
+		p, err := readProtocol(file)
+		if err != nil {
+			return nil, err
+		}
+		for _, i := range p.Interfaces {
+			for _, m := range append(i.Requests, i.Events...) {
+				for _, a := range m.Args {
+					if a.Type == "new_id" && a.Interface != "" {
+						created[a.Interface] = true
+					}
+				}
+			}
+		}
+		var imp *Import
+		if pkg, ok := imports[p.Name]; ok {
+			if imp = pkgs[pkg]; imp == nil {
+				imp = &Import{Name: path.Base(pkg), Path: pkg}
+				pkgs[pkg] = imp
+			}
+		}
+		for i := range p.Interfaces {
+			name := p.Interfaces[i].Name
+			if _, ok := names[name]; ok {
+				return nil, fmt.Errorf("%s: interface %s defined twice", file, name)
+			}
+			names[name] = ref{imp, trimName(name)}
+		}
+		switch {
+		case imp != nil:
+		case gen == nil:
+			gen = p
+		default:
+			gen.Interfaces = append(gen.Interfaces, p.Interfaces...)
+		}
+	}
+	if gen == nil {
+		return nil, fmt.Errorf("all protocols are imported, nothing to generate")
+	}
+
+	if err := gen.analyze(names); err != nil {
+		return nil, err
+	}
+	for i := range gen.Interfaces {
+		gen.Interfaces[i].Global = !created[gen.Interfaces[i].FullName]
+	}
+	for _, imp := range pkgs {
+		if imp.used {
+			gen.Imports = append(gen.Imports, imp)
+		}
+	}
+	sort.Slice(gen.Imports, func(i, j int) bool {
+		return gen.Imports[i].Path < gen.Imports[j].Path
+	})
+	return gen, nil
+}
+
+// generate executes tpl for p and formats the result. Output that is not
+// valid Go is returned unformatted along with the error, so that it can
+// be inspected.
+func generate(tpl *template.Template, p *Protocol, sources []string) ([]byte, error) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by proto-generator from %s. DO NOT EDIT.\n\n", strings.Join(sources, ", "))
+	if err := tpl.Execute(&b, p); err != nil {
+		return nil, err
+	}
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return b.Bytes(), fmt.Errorf("generated invalid Go: %s", err)
+	}
+	return src, nil
+}
+
 func main() {
+	log.SetFlags(0)
+	log.SetPrefix("proto-generator: ")
 	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage: %s <template> <protocol.xml> [param=value ...]\nFlags:\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Usage: %s [flags] <protocol.xml> ...\n"+
+			"Generates the protocols not named by -import into one package; interfaces\n"+
+			"of the imported ones are referred to in the package given for them.\nFlags:\n", os.Args[0])
 		flag.PrintDefaults()
 	}
 	flag.Parse()
-	if flag.NArg() != 2 {
+	if flag.NArg() < 1 {
 		flag.Usage()
 		os.Exit(2)
 	}
 
-	t := template.New("main")
-	t.Funcs(funcs)
-
-	tpl := template.Must(template.New("main").Funcs(funcs).ParseFiles(flag.Arg(0)))
-
-	//if _, err := template.ParseFiles(flag.Arg(0)); err != nil {
-	//    log.Fatal(err)
-	//}
-
-	//for _, t := range t.Templates() {
-	//    t.Funcs(funcs)
-	//}
+	tpl := template.New("objects").Funcs(funcs)
+	if *templateFile != "" {
+		b, err := os.ReadFile(*templateFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		tpl = template.Must(tpl.Parse(string(b)))
+	} else {
+		tpl = template.Must(tpl.Parse(objectsTemplate))
+	}
 
-	f, err := os.Open(flag.Arg(1))
+	p, err := loadProtocols(flag.Args())
 	if err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+		log.Fatal(err)
 	}
-	defer f.Close()
-
-	var p Protocol
-	d := xml.NewDecoder(f)
-	if err := d.Decode(&p); err != nil {
-		fmt.Println(err)
-		os.Exit(3)
+	p.Package = p.Name
+	if *pkg != "" {
+		p.Package = *pkg
 	}
-	p.analyze()
 
-	if err := tpl.ExecuteTemplate(os.Stdout, flag.Arg(0), p); err != nil {
-		log.Fatal(err)
+	sources := make([]string, flag.NArg())
+	for i, file := range flag.Args() {
+		sources[i] = filepath.Base(file)
+	}
+	src, genErr := generate(tpl, p, sources)
+	if src != nil {
+		if *output == "" {
+			_, err = os.Stdout.Write(src)
+		} else {
+			err = os.WriteFile(*output, src, 0644)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	if genErr != nil {
+		log.Fatal(genErr)
 	}
-
-	//go func(w io.WriteCloser) {
-	//    if err := p.Go(w); err != nil {
-	//        fmt.Println(err)
-	//        os.Exit(4)
-	//    }
-	//    w.Close()
-	//}(pw)
-
-	//fset := token.NewFileSet()
-	//gf, err := parser.ParseFile(fset, "messages.go", pr, 0)
-	//if err != nil {
-	//    panic(err)
-	//}
-
-	//if err := printer.Fprint(os.Stdout, fset, gf); err != nil {
-	//    fmt.Println(err)
-	//    os.Exit(5)
-	//}
 }
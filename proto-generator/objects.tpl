@@ -1,9 +1,11 @@
-package {{.Name}}
+package {{.Package}}
 
 import (
-	"fmt"
+	"fmt"{{if .HasFdEvents}}
+	"syscall"{{end}}
 
-	"github.com/vasiliyl/playwand/proto"
+	"github.com/vasiliyl/playwand/proto"{{range .Imports}}
+	"{{.Path}}"{{end}}
 )
 
 type Client struct {
@@ -30,77 +32,254 @@ func NewServer(c *proto.Conn) Server {
 {{$iname := .Name}}
 {{range .Enums}}
 {{$ename := .Name}}
+{{$type := .Type}}
 {{Comment .Description}}
+type {{$type}} uint32
+
 const (
 {{range .Entries}}
 	{{Comment .Summary}}
-	{{Const $iname $ename .Name}} = {{.Value}}
+	{{Const $iname $ename .Name}} {{$type}} = {{.Value}}{{if .Since}}
+	{{Const $iname $ename .Name "since_version"}} = {{.Since}}{{end}}
 {{end}}
 )
+
+{{if .Bitfield}}
+// Has reports whether all flags set in v are set in e.
+func (e {{$type}}) Has(v {{$type}}) bool {
+	return e&v == v
+}
+
+// String returns the names of the flags set in e, separated by "|".
+func (e {{$type}}) String() string {
+	{{range .Entries}}{{if eq .Number 0}}if e == 0 {
+		return "{{.Name}}"
+	}
+	{{end}}{{end}}s := ""
+	for _, f := range []struct {
+		v    {{$type}}
+		name string
+	}{ {{range .Entries}}{{if and .Number (not .Alias)}}
+		{ {{Const $iname $ename .Name}}, "{{.Name}}"},{{end}}{{end}}
+	} {
+		if e&f.v == f.v {
+			if s != "" {
+				s += "|"
+			}
+			s += f.name
+			e &^= f.v
+		}
+	}
+	if e != 0 || s == "" {
+		if s != "" {
+			s += "|"
+		}
+		s += fmt.Sprintf("%#x", uint32(e))
+	}
+	return s
+}
+{{else}}
+func (e {{$type}}) String() string {
+	switch e { {{range .Entries}}{{if not .Alias}}
+	case {{Const $iname $ename .Name}}:
+		return "{{.Name}}"{{end}}{{end}}
+	}
+	return fmt.Sprintf("{{$type}}(%d)", uint32(e))
+}
 {{end}}
+{{end}}
+
+// Versions that introduced each request and event.
+const ({{range .Requests}}
+	{{Const $iname .Name "since_version"}} = {{.Since}}{{end}}{{range .Events}}
+	{{Const $iname .Name "since_version"}} = {{.Since}}{{end}}
+)
 
 {{$interfaceName := Exported .Name}}
+{{$fullName := .FullName}}
+{{$hasDestructor := .HasDestructor}}
+
+var {{$interfaceName}}Interface = &proto.Interface{
+	Name:    "{{.FullName}}",
+	Version: {{.Version}},
+	Requests: []proto.Signature{ {{range .Requests}}
+		{Name: "{{.Name}}", Since: {{.Since}}, Args: []proto.ArgDesc{ {{range .Args}}
+			{Name: "{{.Name}}", Type: {{ArgType .Type}}, Interface: "{{.Interface}}", Nullable: {{.AllowNull}}},{{end}}
+		}},{{end}}
+	},
+	Events: []proto.Signature{ {{range .Events}}
+		{Name: "{{.Name}}", Since: {{.Since}}, Args: []proto.ArgDesc{ {{range .Args}}
+			{Name: "{{.Name}}", Type: {{ArgType .Type}}, Interface: "{{.Interface}}", Nullable: {{.AllowNull}}},{{end}}
+		}},{{end}}
+	},
+}
+
+func init() {
+	proto.RegisterInterface({{$interfaceName}}Interface)
+}
 
 {{/* CLIENT */}}
 type Client{{$interfaceName}}Implementation interface {
 	{{range .Events}}
 	{{Comment .Description}}
-	{{Exported .Name}}({{range .Args}}{{Unexported .Name}} {{GoType .Type}},{{end}}) error
+	{{Exported .Name}}({{range .Args}}{{Unexported .Name}} {{InType "Client" .}},{{end}}) error
+	{{end}}
+}
+
+// Client{{$interfaceName}}Listener implements Client{{$interfaceName}}Implementation with a
+// function per event. Events whose function is nil are ignored, and fds
+// they carry are closed.
+type Client{{$interfaceName}}Listener struct {
+	{{range .Events}}On{{Exported .Name}} func({{range .Args}}{{Unexported .Name}} {{InType "Client" .}},{{end}}) error
 	{{end}}
 }
 
+{{range .Events}}
+func (l Client{{$interfaceName}}Listener) {{Exported .Name}}({{range .Args}}{{Unexported .Name}} {{InType "Client" .}},{{end}}) error {
+	if l.On{{Exported .Name}} == nil {
+		{{range .Args}}{{if eq .Type "fd"}}syscall.Close(int({{Unexported .Name}}))
+		{{end}}{{end}}return nil
+	}
+	return l.On{{Exported .Name}}({{range .Args}}{{Unexported .Name}},{{end}})
+}
+{{end}}
 
 type Client{{$interfaceName}} struct {
 	c *proto.Conn
 	id proto.ObjectId
-	i Client{{$interfaceName}}Implementation
+	version uint32
+	i Client{{$interfaceName}}Implementation{{if .HasDestructor}}
+	destroyed bool{{end}}
+}
+
+// New{{$interfaceName}} creates an object at the given version, which is the
+// version it was bound at or the version of the object that created it.
+func (c Client) New{{$interfaceName}}(version uint32, i Client{{$interfaceName}}Implementation) *Client{{$interfaceName}} {
+	return c.New{{$interfaceName}}WithId(c.c.NextId(), version, i)
 }
 
-func (c Client) New{{$interfaceName}}(i Client{{$interfaceName}}Implementation) Client{{$interfaceName}} {
-	o := Client{{$interfaceName}}{
+// New{{$interfaceName}}WithId registers an object created by the other end
+// of the connection under the id it has chosen.
+func (c Client) New{{$interfaceName}}WithId(id proto.ObjectId, version uint32, i Client{{$interfaceName}}Implementation) *Client{{$interfaceName}} {
+	o := &Client{{$interfaceName}}{
 		c: c.c,
-		id: c.c.NextId(),
+		id: id,
+		version: version,
 		i: i,
 	}
 	c.c.AddObject(o.id, o)
 	return o
 }
 
-func (o Client{{$interfaceName}}) Id() proto.ObjectId {
+func (o *Client{{$interfaceName}}) Id() proto.ObjectId {
 	return o.id
 }
 
-func (o Client{{$interfaceName}}) Handle(m *proto.Message) (err error) {
+func (o *Client{{$interfaceName}}) Version() uint32 {
+	return o.version
+}
+
+func (o *Client{{$interfaceName}}) Conn() *proto.Conn {
+	return o.c
+}
+
+func (o *Client{{$interfaceName}}) Interface() *proto.Interface {
+	return {{$interfaceName}}Interface
+}
+
+func (o *Client{{$interfaceName}}) Handle(m *proto.Message) (err error) {
 	switch m.Opcode() {
 		{{range .Events}}
 	case {{.Opcode}}:
+		{{$msg := .Name}}
 		var ({{range .Args}}
-			{{Unexported .Name}} {{GoType .Type}}
+			{{Unexported .Name}} {{InType "Client" .}}
 		{{end}})
 
 		{{range .Args}}
-		if {{Unexported .Name}}, err = m.Read{{WlType .Type}}(); err != nil {
+		{{if .EnumType}}var {{Unexported .Name}}Raw {{GoType .Type}}
+		if {{Unexported .Name}}Raw, err = m.Read{{WlType .Type}}(); err != nil {
 			return
 		}
+		{{Unexported .Name}} = {{.EnumType}}({{Unexported .Name}}Raw)
+		{{else if .ObjectType}}var {{Unexported .Name}}Obj proto.Object
+		if {{Unexported .Name}}Obj, err = m.ReadObject({{.AllowNull}}); err != nil {
+			return fmt.Errorf("{{$fullName}}.{{$msg}}: argument {{.Name}}: %w", err)
+		}
+		if {{Unexported .Name}}Obj != nil {
+			var ok bool
+			if {{Unexported .Name}}, ok = {{Unexported .Name}}Obj.({{InType "Client" .}}); !ok {
+				return fmt.Errorf("{{$fullName}}.{{$msg}}: argument {{.Name}}: %T is not a {{.Interface}}", {{Unexported .Name}}Obj)
+			}
+		}
+		{{else if eq .Type "object"}}if {{Unexported .Name}}, err = m.ReadObject({{.AllowNull}}); err != nil {
+			return fmt.Errorf("{{$fullName}}.{{$msg}}: argument {{.Name}}: %w", err)
+		}{{else}}if {{Unexported .Name}}, err = m.Read{{WlType .Type}}(); err != nil {
+			return
+		}{{end}}
 		{{end}}
 		return o.i.{{Exported .Name}}({{range .Args}}{{Unexported .Name}},{{end}})
 		{{end}}
 
 	default:
-		return fmt.Errorf("{{$interfaceName}}: invalid event opcode: %s", m.Opcode())
+		return fmt.Errorf("{{$interfaceName}}: invalid event opcode: %d", m.Opcode())
+	}
+}
+
+{{if .Global}}
+// Bind{{$interfaceName}} binds global, which must be a {{.FullName}}, at the
+// lower of its advertised version and maxVersion, and returns the proxy.
+// maxVersion is capped at {{.Version}}, the version known to this package.
+func Bind{{$interfaceName}}(registry proto.Registry, global proto.Global, maxVersion uint32, i Client{{$interfaceName}}Implementation) (*Client{{$interfaceName}}, error) {
+	if maxVersion > {{.Version}} {
+		maxVersion = {{.Version}}
 	}
+	return proto.BindGlobal(registry, global, maxVersion, func(version uint32) *Client{{$interfaceName}} {
+		return NewClient(registry.Conn()).New{{$interfaceName}}(version, i)
+	})
 }
+{{end}}
 
 {{range .Requests}}
+{{$nil := ""}}{{if .NewIdType}}{{$nil = "nil, "}}{{end}}
+{{$msg := .Name}}
 {{Comment .Description}}
-func (o Client{{$interfaceName}}) {{Exported .Name}}({{range .Args}}{{Unexported .Name}} {{GoType .Type}}, {{end}}) error {
-	m := proto.NewMessage(o.id, {{.Opcode}})
+func (o *Client{{$interfaceName}}) {{Exported .Name}}({{range .Args}}{{if .NewIdType}}i {{.Package}}Client{{.NewIdType}}Implementation{{else}}{{Unexported .Name}} {{OutType "Client" .}}{{end}}, {{end}}) {{if .NewIdType}}(*{{.NewIdPackage}}Client{{.NewIdType}}, error){{else}}error{{end}} {
+	{{if $hasDestructor}}if o.destroyed {
+		return {{$nil}}fmt.Errorf("{{$fullName}}@%d.{{.Name}}: %w", o.id, proto.ErrDestroyed)
+	}
+	{{end}}{{if gt .Since 1}}if o.version < {{Const $iname .Name "since_version"}} {
+		return {{$nil}}&proto.VersionError{
+			ObjectId: o.id,
+			Interface: "{{$fullName}}",
+			Request: "{{.Name}}",
+			Since: {{Const $iname .Name "since_version"}},
+			Version: o.version,
+		}
+	}
+	{{end}}{{range .Args}}{{if eq .Type "object"}}var {{Unexported .Name}}Id proto.ObjectId
+	if {{Unexported .Name}} != nil {
+		{{Unexported .Name}}Id = {{Unexported .Name}}.Id()
+	}{{if not .AllowNull}} else {
+		return {{$nil}}fmt.Errorf("{{$fullName}}.{{$msg}}: argument {{.Name}} is null")
+	}{{end}}
+	{{end}}{{end}}{{if .NewIdType}}obj := {{.NewIdPackage}}NewClient(o.c).New{{.NewIdType}}(o.version, i)
+	{{end}}m := proto.NewMessage(o.id, {{.Opcode}})
 	{{range .Args}}
-	if err := m.Write{{WlType .Type}}({{Unexported .Name}}); err != nil {
-		return err
+	if err := m.Write{{WlType .Type}}({{if .NewIdType}}obj.Id(){{else if eq .Type "new_id"}}{{Unexported .Name}}.Id(){{else if eq .Type "object"}}{{Unexported .Name}}Id{{else if .EnumType}}{{GoType .Type}}({{Unexported .Name}}){{else}}{{Unexported .Name}}{{end}}); err != nil {
+		{{if $nil}}o.c.DeleteObject(obj.Id())
+		{{end}}return {{$nil}}err
 	}
 	{{end}}
-	return o.c.WriteMessage(m)
+	{{if .NewIdType}}if err := o.c.WriteMessage(m); err != nil {
+		o.c.DeleteObject(obj.Id())
+		return nil, err
+	}
+	return obj, nil{{else if .Destructor}}if err := o.c.WriteMessage(m); err != nil {
+		return err
+	}
+	o.destroyed = true
+	return o.c.DestroyObject(o.id){{else}}return o.c.WriteMessage(m){{end}}
 }
 {{end}}
 
@@ -108,7 +287,7 @@ func (o Client{{$interfaceName}}) {{Exported .Name}}({{range .Args}}{{Unexported
 type Server{{$interfaceName}}Implementation interface {
 	{{range .Requests}}
 	{{Comment .Description}}
-	{{Exported .Name}}({{range .Args}}{{Unexported .Name}} {{GoType .Type}},{{end}}) error
+	{{Exported .Name}}({{range .Args}}{{Unexported .Name}} {{InType "Server" .}},{{end}}) error
 	{{end}}
 }
 
@@ -116,50 +295,113 @@ type Server{{$interfaceName}}Implementation interface {
 type Server{{$interfaceName}} struct {
 	c *proto.Conn
 	id proto.ObjectId
-	i Server{{$interfaceName}}Implementation
+	version uint32
+	i Server{{$interfaceName}}Implementation{{if .HasDestructor}}
+	destroyed bool{{end}}
+}
+
+// New{{$interfaceName}} creates an object at the given version, which is the
+// version it was bound at or the version of the object that created it.
+func (c Server) New{{$interfaceName}}(version uint32, i Server{{$interfaceName}}Implementation) *Server{{$interfaceName}} {
+	return c.New{{$interfaceName}}WithId(c.c.NextId(), version, i)
 }
 
-func (c Server) New{{$interfaceName}}(i Server{{$interfaceName}}Implementation) Server{{$interfaceName}} {
-	o := Server{{$interfaceName}}{
+// New{{$interfaceName}}WithId registers an object created by the other end
+// of the connection under the id it has chosen.
+func (c Server) New{{$interfaceName}}WithId(id proto.ObjectId, version uint32, i Server{{$interfaceName}}Implementation) *Server{{$interfaceName}} {
+	o := &Server{{$interfaceName}}{
 		c: c.c,
-		id: c.c.NextId(),
+		id: id,
+		version: version,
 		i: i,
 	}
 	c.c.AddObject(o.id, o)
 	return o
 }
 
-func (o Server{{$interfaceName}}) Id() proto.ObjectId {
+func (o *Server{{$interfaceName}}) Id() proto.ObjectId {
 	return o.id
 }
 
-func (o Server{{$interfaceName}}) Handle(m *proto.Message) (err error) {
+func (o *Server{{$interfaceName}}) Version() uint32 {
+	return o.version
+}
+
+func (o *Server{{$interfaceName}}) Conn() *proto.Conn {
+	return o.c
+}
+
+func (o *Server{{$interfaceName}}) Interface() *proto.Interface {
+	return {{$interfaceName}}Interface
+}
+
+func (o *Server{{$interfaceName}}) Handle(m *proto.Message) (err error) {
 	switch m.Opcode() {
 		{{range .Requests}}
 	case {{.Opcode}}:
+		{{$msg := .Name}}
 		var ({{range .Args}}
-			{{Unexported .Name}} {{GoType .Type}}
+			{{Unexported .Name}} {{InType "Server" .}}
 		{{end}})
 
 		{{range .Args}}
-		if {{Unexported .Name}}, err = m.Read{{WlType .Type}}(); err != nil {
+		{{if .EnumType}}var {{Unexported .Name}}Raw {{GoType .Type}}
+		if {{Unexported .Name}}Raw, err = m.Read{{WlType .Type}}(); err != nil {
 			return
 		}
+		{{Unexported .Name}} = {{.EnumType}}({{Unexported .Name}}Raw)
+		{{else if .ObjectType}}var {{Unexported .Name}}Obj proto.Object
+		if {{Unexported .Name}}Obj, err = m.ReadObject({{.AllowNull}}); err != nil {
+			return fmt.Errorf("{{$fullName}}.{{$msg}}: argument {{.Name}}: %w", err)
+		}
+		if {{Unexported .Name}}Obj != nil {
+			var ok bool
+			if {{Unexported .Name}}, ok = {{Unexported .Name}}Obj.({{InType "Server" .}}); !ok {
+				return fmt.Errorf("{{$fullName}}.{{$msg}}: argument {{.Name}}: %T is not a {{.Interface}}", {{Unexported .Name}}Obj)
+			}
+		}
+		{{else if eq .Type "object"}}if {{Unexported .Name}}, err = m.ReadObject({{.AllowNull}}); err != nil {
+			return fmt.Errorf("{{$fullName}}.{{$msg}}: argument {{.Name}}: %w", err)
+		}{{else}}if {{Unexported .Name}}, err = m.Read{{WlType .Type}}(); err != nil {
+			return
+		}{{end}}
 		{{end}}
-		return o.i.{{Exported .Name}}({{range .Args}}{{Unexported .Name}},{{end}})
+		{{if .Destructor}}err = o.i.{{Exported .Name}}({{range .Args}}{{Unexported .Name}},{{end}})
+		o.destroyed = true
+		if derr := o.c.DestroyObject(o.id); err == nil {
+			err = derr
+		}
+		return
+		{{else}}return o.i.{{Exported .Name}}({{range .Args}}{{Unexported .Name}},{{end}}){{end}}
 		{{end}}
 
 	default:
-		return fmt.Errorf("{{$interfaceName}}: invalid request opcode: %s", m.Opcode())
+		return fmt.Errorf("{{$interfaceName}}: invalid request opcode: %d", m.Opcode())
 	}
 }
 
 {{range .Events}}
+{{$nil := ""}}
+{{$msg := .Name}}
 {{Comment .Description}}
-func (o Server{{$interfaceName}}) {{Exported .Name}}({{range .Args}}{{Unexported .Name}} {{GoType .Type}}, {{end}}) error {
-	m := proto.NewMessage(o.id, {{.Opcode}})
+{{if gt .Since 1}}//
+// The event is not sent to clients that bound a version older than {{.Since}}.
+{{end}}func (o *Server{{$interfaceName}}) {{Exported .Name}}({{range .Args}}{{Unexported .Name}} {{OutType "Server" .}}, {{end}}) error {
+	{{if $hasDestructor}}if o.destroyed {
+		return fmt.Errorf("{{$fullName}}@%d.{{.Name}}: %w", o.id, proto.ErrDestroyed)
+	}
+	{{end}}{{if gt .Since 1}}if o.version < {{Const $iname .Name "since_version"}} {
+		return nil
+	}
+	{{end}}{{range .Args}}{{if eq .Type "object"}}var {{Unexported .Name}}Id proto.ObjectId
+	if {{Unexported .Name}} != nil {
+		{{Unexported .Name}}Id = {{Unexported .Name}}.Id()
+	}{{if not .AllowNull}} else {
+		return {{$nil}}fmt.Errorf("{{$fullName}}.{{$msg}}: argument {{.Name}} is null")
+	}{{end}}
+	{{end}}{{end}}m := proto.NewMessage(o.id, {{.Opcode}})
 	{{range .Args}}
-	if err := m.Write{{WlType .Type}}({{Unexported .Name}}); err != nil {
+	if err := m.Write{{WlType .Type}}({{if eq .Type "object"}}{{Unexported .Name}}Id{{else if and (eq .Type "new_id") (not .Interface)}}{{Unexported .Name}}.Id(){{else if .EnumType}}{{GoType .Type}}({{Unexported .Name}}){{else}}{{Unexported .Name}}{{end}}); err != nil {
 		return err
 	}
 	{{end}}
@@ -1,14 +1,19 @@
 package proto
 
 import (
-	"bytes"
+	"context"
 	"encoding/binary"
+	"errors"
 	"fmt"
-	"log"
+	"io"
 	"net"
 	"os"
-	"path"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"syscall"
+	"time"
 )
 
 var ByteOrder = binary.LittleEndian
@@ -42,175 +47,569 @@ func (h header) String() string {
 	return fmt.Sprintf("header{Object: %08x, Opcode: %d, Size: %d}", h.object(), h.opcode(), h.size())
 }
 
-func sockPath() string {
+// displayPath returns the socket path of the named display, following
+// libwayland: the name defaults to "wayland-0", an absolute name is used
+// as is, and a relative one is looked up in $XDG_RUNTIME_DIR.
+func displayPath(name string) (string, error) {
+	if name == "" {
+		name = "wayland-0"
+	}
+	if filepath.IsAbs(name) {
+		return name, nil
+	}
 	rt := os.Getenv("XDG_RUNTIME_DIR")
 	if rt == "" {
-		rt = os.Getenv("HOME")
-	}
-	wd := os.Getenv("WAYLAND_DISPLAY")
-	if wd == "" {
-		wd = "wayland-0"
+		return "", fmt.Errorf("XDG_RUNTIME_DIR is not set in the environment, cannot find display %q", name)
 	}
-	return path.Join(rt, wd)
+	return filepath.Join(rt, name), nil
 }
 
+const (
+	// outBufSize is the size of the outgoing buffer; messages are
+	// collected there until Flush or until the buffer is full.
+	outBufSize = 4096
+
+	// maxFdsOut limits the number of fds sent along with a single
+	// sendmsg, well below the kernel's SCM_MAX_FD (253).
+	maxFdsOut = 28
+
+	// maxFdsIn is the kernel's SCM_MAX_FD; the oob buffer is sized for it
+	// so that the kernel never has to drop fds it could not deliver.
+	maxFdsIn = 253
+
+	// inBufSize is the size of the input buffer. The header stores the
+	// message size in 16 bits, so any message fits into it.
+	inBufSize = 1 << 16
+)
+
+// Conn is a wayland connection. Its methods are safe for concurrent use,
+// except for ReadMessage and TakeFds, which must not be called
+// concurrently with each other or with EventQueue dispatching.
 type Conn struct {
-	c       *net.UnixConn
+	c  *net.UnixConn
+	rc syscall.RawConn
+
+	// server is set on the server end of the connection
+	server bool
+
+	// mu guards objects, ids, queues, the reading state and userData
+	mu      sync.Mutex
 	objects map[ObjectId]Object
-	curid   ObjectId
+	ids     idAllocator
+	queues  map[ObjectId]*EventQueue
+	defq    *EventQueue
+	reading bool
+	rcond   *sync.Cond
+	closed  bool
+
+	// rerr is the error that ended reading, or the protocol error the
+	// server reported
+	rerr error
+
+	userData interface{}
+
+	in       []byte
+	inr, inw int
+	msg      Message
+	oob      []byte
+	fds      fdQueue
+
+	// wmu guards the outgoing buffer
+	wmu    sync.Mutex
+	out    []byte
+	outFds []int
+
+	tracer atomic.Pointer[Tracer]
+
+	closeOnce sync.Once
+	closeErr  error
 }
 
+// Dial connects to the compositor the way libwayland does: through the
+// already connected socket fd in WAYLAND_SOCKET if set, or else through
+// the socket of the display named by WAYLAND_DISPLAY.
 func Dial() (*Conn, error) {
-	return DialPath(sockPath())
-}
+	if s := os.Getenv("WAYLAND_SOCKET"); s != "" {
+		// the socket is meant for this process only, not for its children
+		os.Unsetenv("WAYLAND_SOCKET")
+		fd, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid WAYLAND_SOCKET %q: %s", s, err)
+		}
+		syscall.CloseOnExec(fd)
+		return DialFD(fd)
+	}
 
-func DialPath(path string) (c *Conn, err error) {
-	c = new(Conn)
-	c.c, err = net.DialUnix("unix", nil, &net.UnixAddr{Net: "unix", Name: path})
-	if err == nil {
-		c.objects = make(map[ObjectId]Object)
-		c.curid = 1
+	path, err := displayPath(os.Getenv("WAYLAND_DISPLAY"))
+	if err != nil {
+		return nil, err
 	}
-	return
+	return DialPath(path)
 }
 
-type Listener struct {
-	l *net.UnixListener
+func DialPath(path string) (*Conn, error) {
+	uc, err := net.DialUnix("unix", nil, &net.UnixAddr{Net: "unix", Name: path})
+	if err != nil {
+		return nil, err
+	}
+	return newConn(uc, "client")
 }
 
-func Listen() (Listener, error) {
-	return ListenPath(sockPath())
+// DialFD makes a client connection out of the already connected socket
+// fd, which the returned Conn takes ownership of.
+func DialFD(fd int) (*Conn, error) {
+	f := os.NewFile(uintptr(fd), "wayland-socket")
+	fc, err := net.FileConn(f)
+	f.Close()
+	if err != nil {
+		return nil, err
+	}
+	uc, ok := fc.(*net.UnixConn)
+	if !ok {
+		fc.Close()
+		return nil, fmt.Errorf("fd %d is not a unix socket", fd)
+	}
+	return newConn(uc, "client")
 }
 
-func ListenPath(path string) (l Listener, err error) {
-	l.l, err = net.ListenUnix("unix", &net.UnixAddr{Net: "unix", Name: path})
-	return
+// newConn sets up a connection for the given side, "client" or "server".
+func newConn(uc *net.UnixConn, side string) (c *Conn, err error) {
+	c = &Conn{
+		c:       uc,
+		server:  side == "server",
+		objects: make(map[ObjectId]Object),
+		in:      make([]byte, inBufSize),
+		oob:     make([]byte, syscall.CmsgSpace(maxFdsIn*4)),
+		out:     make([]byte, 0, outBufSize),
+		queues:  make(map[ObjectId]*EventQueue),
+	}
+	c.SetTracer(debugTracerFromEnv(side))
+	if c.server {
+		c.ids = newIdAllocator(serverIdMin, serverIdMax)
+	} else {
+		c.ids = newIdAllocator(DisplayId+1, clientIdMax)
+	}
+	c.defq = c.NewEventQueue()
+	c.rcond = sync.NewCond(&c.mu)
+	if c.rc, err = uc.SyscallConn(); err != nil {
+		uc.Close()
+		return nil, err
+	}
+	return c, nil
 }
 
-func (l Listener) Accept() (c *Conn, err error) {
-	c = new(Conn)
-	c.c, err = l.l.AcceptUnix()
+// recv reads into p with a single recvmsg, queueing any fds that come
+// along with the data.
+func (c *Conn) recv(p []byte) (n int, err error) {
+	var oobn, flags int
+	var rerr error
+	err = c.rc.Read(func(fd uintptr) bool {
+		n, oobn, flags, _, rerr = syscall.Recvmsg(int(fd), p, c.oob, syscall.MSG_CMSG_CLOEXEC)
+		return rerr != syscall.EAGAIN
+	})
 	if err == nil {
-		c.objects = make(map[ObjectId]Object)
-		c.curid = 1
+		err = rerr
+	}
+	if err != nil {
+		return 0, err
 	}
-	return
-}
 
-func (l Listener) Close() error {
-	return l.l.Close()
+	if oobn != 0 {
+		scms, err := syscall.ParseSocketControlMessage(c.oob[:oobn])
+		if err != nil {
+			return n, err
+		}
+		for i := range scms {
+			fds, err := syscall.ParseUnixRights(&scms[i])
+			if err != nil {
+				return n, err
+			}
+			c.fds.push(fds...)
+		}
+	}
+	if flags&syscall.MSG_CTRUNC != 0 {
+		return n, fmt.Errorf("ancillary data truncated, fds lost")
+	}
+	if n == 0 && len(p) != 0 {
+		return 0, io.EOF
+	}
+	return n, nil
 }
 
-func (c *Conn) readHeader() (h header, err error) {
-	err = binary.Read(c.c, ByteOrder, &h)
+// ReadMessage returns the next message received on the connection. Data
+// is read with as few recvmsg calls as possible into the connection's
+// input buffer, and the returned message is a view into it: it remains
+// valid only until the next call to ReadMessage. Use Message.Retain to
+// keep it longer.
+//
+// The message claims its fds by the signature of the object it is
+// addressed to, so that object must be registered by the time it is
+// read: dispatch a message that creates an object before reading the
+// next one.
+func (c *Conn) ReadMessage() (m *Message, err error) {
+	m = &c.msg
+	m.closeOwnedFds()
+
+	var h header
+	for {
+		if c.inw-c.inr >= 8 {
+			h = header{
+				Object:     ObjectId(ByteOrder.Uint32(c.in[c.inr:])),
+				OpcodeSize: ByteOrder.Uint32(c.in[c.inr+4:]),
+			}
+			if h.OpcodeSize>>16 < 8 {
+				return nil, fmt.Errorf("invalid message size in %s", h)
+			}
+			if c.inw-c.inr >= 8+int(h.size()) {
+				break
+			}
+		}
+		if c.inr != 0 && c.inw == len(c.in) || c.inr == c.inw {
+			c.inw = copy(c.in, c.in[c.inr:c.inw])
+			c.inr = 0
+		}
+		n, err := c.recv(c.in[c.inw:])
+		if err != nil {
+			return nil, err
+		}
+		c.inw += n
+	}
+
+	start := c.inr + 8
+	c.inr = start + int(h.size())
+	*m = Message{
+		object: h.object(),
+		opcode: h.opcode(),
+		p:      c.in[start:c.inr:c.inr],
+		fds:    m.fds[:0],
+		c:      c,
+	}
+
+	// the sender attaches fds to the sendmsg carrying the first byte of
+	// the message at the latest, so by now they must be queued
+	m.iface, m.sig = c.describe(m, c.server)
+	if m.sig != nil {
+		n := m.sig.NumFds()
+		if c.fds.len() < n {
+			err = fmt.Errorf("%s@%d.%s expects %d fds, %d received", m.iface.Name, m.object, m.sig.Name, n, c.fds.len())
+			return
+		}
+		m.fds = c.fds.take(m.fds, n)
+		m.nown = len(m.fds)
+	}
+
+	c.trace(Received, m)
 	return
 }
 
-func (c *Conn) writeHeader(o ObjectId, opcode, size uint16) error {
-	return binary.Write(c.c, ByteOrder, newHeader(o, opcode, size))
+// describe looks up the interface of the object m is addressed to and
+// the signature of m, a request or an event.
+func (c *Conn) describe(m *Message, request bool) (*Interface, *Signature) {
+	c.mu.Lock()
+	obj := c.objects[m.object]
+	c.mu.Unlock()
+	d, ok := obj.(described)
+	if !ok || d.Interface() == nil {
+		return nil, nil
+	}
+	iface := d.Interface()
+	if request {
+		return iface, iface.request(m.opcode)
+	}
+	return iface, iface.event(m.opcode)
 }
 
-func (c *Conn) ReadMessage() (m *Message, err error) {
-	h, err := c.readHeader()
-	if err != nil {
-		return
+// buffered reports whether a complete message is waiting in the input
+// buffer, and returns the object it is addressed to.
+func (c *Conn) buffered() (ObjectId, bool) {
+	if c.inw-c.inr < 8 {
+		return 0, false
+	}
+	size := int(ByteOrder.Uint32(c.in[c.inr+4:]) >> 16)
+	return ObjectId(ByteOrder.Uint32(c.in[c.inr:])), c.inw-c.inr >= size
+}
+
+// registered reports whether an object with the given id is registered.
+// On clients, c handles wl_display events itself.
+func (c *Conn) registered(id ObjectId) bool {
+	if !c.server && id == DisplayId {
+		return true
 	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.objects[id]
+	return ok
+}
 
-	log.Printf("ReadMessage: header = %s", h)
+// TakeFds removes all received fds that no message has claimed yet and
+// passes their ownership to the caller. Proxies that forward messages
+// without knowing their signatures can send them on with the message
+// being forwarded: the peer queues fds in order as well.
+func (c *Conn) TakeFds() []int {
+	return c.fds.take(nil, c.fds.len())
+}
 
-	m = &Message{
-		object: h.object(),
-		opcode: h.opcode(),
+// WriteMessage queues m in the outgoing buffer. Messages are sent when
+// the buffer fills up or on Flush. Fds attached to m are duplicated, so
+// the caller keeps ownership of them.
+func (c *Conn) WriteMessage(m *Message) (err error) {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+
+	payload := m.p
+	if len(payload)+8 > 0xffff {
+		return fmt.Errorf("message of %d bytes is too large", len(payload)+8)
+	}
+	if len(m.fds) > maxFdsOut {
+		return fmt.Errorf("message carries %d fds, at most %d are allowed", len(m.fds), maxFdsOut)
 	}
 
-	if h.size() == 0 {
-		return
+	if len(c.out)+len(payload)+8 > outBufSize || len(c.outFds)+len(m.fds) > maxFdsOut {
+		if err = c.flush(); err != nil {
+			return
+		}
 	}
 
-	p := make([]byte, h.size())
-	oob := make([]byte, 32)
-	n, oobn, _, _, err := c.c.ReadMsgUnix(p, oob)
-	if err != nil {
-		return
+	nfds := len(c.outFds)
+	for _, fd := range m.fds {
+		var dup int
+		if dup, err = dupCloexec(fd); err != nil {
+			// m is not sent, so neither are the fds duplicated so far
+			for _, fd := range c.outFds[nfds:] {
+				syscall.Close(fd)
+			}
+			c.outFds = c.outFds[:nfds]
+			return
+		}
+		c.outFds = append(c.outFds, dup)
 	}
-	if uint16(n) != h.size() {
-		err = fmt.Errorf("expected %d bytes, got %d", h.size(), n)
-		return
+
+	if m.sig == nil {
+		m.iface, m.sig = c.describe(m, !c.server)
 	}
+	c.trace(Sent, m)
 
-	log.Printf("ReadMessage: n = %d, oobn = %d", n, oobn)
+	h := newHeader(m.object, m.opcode, uint16(len(payload)))
+	c.out = ByteOrder.AppendUint32(c.out, uint32(h.Object))
+	c.out = ByteOrder.AppendUint32(c.out, h.OpcodeSize)
+	c.out = append(c.out, payload...)
+	return nil
+}
 
-	m.p = bytes.NewBuffer(p)
+// Flush sends all buffered messages with a single sendmsg, unless the
+// socket accepts only part of them.
+func (c *Conn) Flush() error {
+	return c.FlushContext(context.Background())
+}
 
-	if oobn == 0 {
-		return
-	}
+// FlushContext is like Flush, but gives up once ctx is done. Data the
+// socket did not accept stays buffered.
+func (c *Conn) FlushContext(ctx context.Context) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
 
-	oob = oob[:oobn]
-	scms, err := syscall.ParseSocketControlMessage(oob)
-	if err != nil {
-		return
+	if len(c.out) == 0 {
+		return nil
 	}
-	if len(scms) != 1 {
-		err = fmt.Errorf("expected 1 SocketControlMessage, got %d", len(scms))
-		return
+	if ctx.Err() != nil {
+		return interrupted("flush", ctx)
 	}
-	scm := scms[0]
-	m.fds, err = syscall.ParseUnixRights(&scm)
-	return
+
+	deadline, _ := ctx.Deadline()
+	c.c.SetWriteDeadline(deadline)
+	stopped := make(chan struct{})
+	stop := context.AfterFunc(ctx, func() {
+		c.c.SetWriteDeadline(aLongTimeAgo)
+		close(stopped)
+	})
+	defer func() {
+		if !stop() {
+			<-stopped
+		}
+		c.c.SetWriteDeadline(time.Time{})
+	}()
+
+	err := c.flush()
+	if errors.Is(err, os.ErrDeadlineExceeded) {
+		return interrupted("flush", ctx)
+	}
+	return err
 }
 
-func (c *Conn) WriteMessage(m *Message) (err error) {
-	var payload []byte
-	if m.p != nil {
-		payload = m.p.Bytes()
+// interrupted returns the error for an operation given up because ctx
+// is done. The socket deadline set from ctx may pass slightly before
+// ctx notices, hence the fallback.
+func interrupted(op string, ctx context.Context) error {
+	err := ctx.Err()
+	if err == nil {
+		err = context.DeadlineExceeded
 	}
-	if err = c.writeHeader(m.object, m.opcode, uint16(len(payload))); err != nil {
-		return
+	return fmt.Errorf("%s interrupted: %w", op, err)
+}
+
+// aLongTimeAgo is a deadline in the past, used to interrupt blocked
+// socket operations.
+var aLongTimeAgo = time.Unix(1, 0)
+
+func (c *Conn) flush() error {
+	for len(c.out) > 0 {
+		var oob []byte
+		if len(c.outFds) != 0 {
+			oob = syscall.UnixRights(c.outFds...)
+		}
+		n, _, err := c.c.WriteMsgUnix(c.out, oob, nil)
+		if n > 0 {
+			// fds go out with the first byte written
+			c.closeOutFds()
+			c.out = c.out[:copy(c.out, c.out[n:])]
+		}
+		if err != nil {
+			return err
+		}
 	}
-	if len(payload) == 0 {
-		// message without payload wouldn't contain fds, so we can return here
-		return nil
+	return nil
+}
+
+func (c *Conn) closeOutFds() {
+	for _, fd := range c.outFds {
+		syscall.Close(fd)
 	}
-	var oob []byte
-	if len(m.fds) != 0 {
-		oob = syscall.UnixRights(m.fds...)
+	c.outFds = c.outFds[:0]
+}
+
+func dupCloexec(fd int) (int, error) {
+	r, _, errno := syscall.Syscall(syscall.SYS_FCNTL, uintptr(fd), syscall.F_DUPFD_CLOEXEC, 0)
+	if errno != 0 {
+		return -1, errno
 	}
-	_, _, err = c.c.WriteMsgUnix(payload, oob, nil)
-	return
+	return int(r), nil
 }
 
 func (c *Conn) AddObject(id ObjectId, o Object) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.objects[id] = o
 }
 
+// DeleteObject unregisters the object with the given id. Ids allocated
+// by this end of the connection become available for reuse by NextId.
+// Clients delete their objects once the server acknowledges their
+// destruction with wl_display.delete_id, which DispatchMessage handles.
 func (c *Conn) DeleteObject(id ObjectId) {
-	delete(c.objects, id)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.objects[id]; ok {
+		delete(c.objects, id)
+		c.ids.release(id)
+	}
+	delete(c.queues, id)
 }
 
-func (c *Conn) NextId() (id ObjectId) {
-	id = c.curid
-	c.curid++
-	return
+// NextId allocates an id for a new object created by this end of the
+// connection: from 2 upwards on clients, as 1 is DisplayId, and from
+// 0xff000000 upwards on servers. Released ids are reused first.
+func (c *Conn) NextId() ObjectId {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	id, err := c.ids.alloc()
+	if err != nil {
+		panic(err)
+	}
+	return id
 }
 
-func (c *Conn) Next() error {
-	m, err := c.ReadMessage()
-	if err != nil {
-		return err
+// checkNewId reports whether the peer may create an object with the
+// given id.
+func (c *Conn) checkNewId(id ObjectId) error {
+	min, max := serverIdMin, serverIdMax
+	if c.server {
+		min, max = clientIdMin, clientIdMax
+	}
+	if id < min || id > max {
+		return fmt.Errorf("new_id %d is outside of the peer's range %08x-%08x", id, min, max)
 	}
 
-	return c.Dispatch(m)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.objects[id]; ok {
+		return fmt.Errorf("new_id %d is already in use", id)
+	}
+	return nil
+}
+
+// Dispatch dispatches the default queue; see EventQueue.Dispatch.
+func (c *Conn) Dispatch(ctx context.Context) (int, error) {
+	return c.defq.Dispatch(ctx)
 }
 
-func (c *Conn) Dispatch(m *Message) error {
-	if obj, ok := c.objects[m.Object()]; ok {
+// DispatchPending dispatches messages already queued on the default
+// queue.
+func (c *Conn) DispatchPending() (int, error) {
+	return c.defq.DispatchPending()
+}
+
+// Roundtrip makes a roundtrip on the default queue; see
+// EventQueue.Roundtrip.
+func (c *Conn) Roundtrip(ctx context.Context) error {
+	return c.defq.Roundtrip(ctx)
+}
+
+// DispatchMessage passes m to the object it is addressed to. On clients,
+// the wl_display events are handled by c instead: error is returned as a
+// *ProtocolError, and delete_id deletes the object.
+func (c *Conn) DispatchMessage(m *Message) error {
+	if !c.server && m.Object() == DisplayId {
+		switch m.Opcode() {
+		case displayError:
+			return c.protocolError(m)
+		case displayDeleteId:
+			id, err := m.ReadUint()
+			if err != nil {
+				return err
+			}
+			c.DeleteObject(ObjectId(id))
+			return nil
+		}
+	}
+
+	c.mu.Lock()
+	obj, ok := c.objects[m.Object()]
+	c.mu.Unlock()
+	if ok {
+		defer m.closeOwnedFds()
 		return obj.Handle(m)
 	}
 
 	return fmt.Errorf("Object %d is not registered", m.Object())
 }
 
+// Close closes the connection, dropping queued messages and fds. It may
+// be called more than once, also concurrently, as when PostError closes
+// a connection its owner closes as well: later calls return the result
+// of the first.
 func (c *Conn) Close() error {
-	return c.c.Close()
+	c.closeOnce.Do(func() {
+		c.closeErr = c.close()
+	})
+	return c.closeErr
+}
+
+func (c *Conn) close() error {
+	c.wmu.Lock()
+	c.closeOutFds()
+	c.wmu.Unlock()
+
+	c.mu.Lock()
+	c.closed = true
+	c.defq.release()
+	for _, q := range c.queues {
+		q.release()
+	}
+	c.mu.Unlock()
+
+	// a goroutine still reading pushes fds until the socket is closed;
+	// the fd queue closes what arrives after closeAll
+	err := c.c.Close()
+	c.fds.closeAll()
+	return err
 }
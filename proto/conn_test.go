@@ -0,0 +1,442 @@
+package proto
+
+import (
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"testing"
+)
+
+// testConns returns the client and server ends of a connected socket
+// pair. Both are closed when the test ends.
+func testConns(t testing.TB) (client, server *Conn) {
+	fds, err := syscall.Socketpair(syscall.AF_UNIX, syscall.SOCK_STREAM|syscall.SOCK_CLOEXEC, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testConn(t, fds[0], "client"), testConn(t, fds[1], "server")
+}
+
+func testConn(t testing.TB, fd int, side string) *Conn {
+	f := os.NewFile(uintptr(fd), side)
+	fc, err := net.FileConn(f)
+	f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := newConn(fc.(*net.UnixConn), side)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+// testPipe returns both ends of a pipe, closed when the test ends.
+func testPipe(t testing.TB) (r, w int) {
+	var p [2]int
+	if err := syscall.Pipe2(p[:], syscall.O_CLOEXEC); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		syscall.Close(p[0])
+		syscall.Close(p[1])
+	})
+	return p[0], p[1]
+}
+
+// fdOpen reports whether fd is an open file descriptor.
+func fdOpen(fd int) bool {
+	_, _, errno := syscall.Syscall(syscall.SYS_FCNTL, uintptr(fd), syscall.F_GETFD, 0)
+	return errno == 0
+}
+
+// numFds returns the number of fds open in the process.
+func numFds(t testing.TB) int {
+	fds, err := os.ReadDir("/proc/self/fd")
+	if err != nil {
+		t.Skip(err)
+	}
+	return len(fds)
+}
+
+func TestWriteMessageBuffers(t *testing.T) {
+	client, server := testConns(t)
+
+	for i := uint32(0); i < 3; i++ {
+		m := NewMessage(3, uint16(i))
+		m.WriteUint(i)
+		if err := client.WriteMessage(m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(client.out) != 3*12 {
+		t.Fatalf("%d bytes buffered, want %d", len(client.out), 3*12)
+	}
+	if err := client.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if len(client.out) != 0 {
+		t.Fatalf("%d bytes buffered after Flush", len(client.out))
+	}
+
+	for i := uint32(0); i < 3; i++ {
+		m, err := server.ReadMessage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		v, err := m.ReadUint()
+		if m.Object() != 3 || m.Opcode() != uint16(i) || err != nil || v != i {
+			t.Errorf("message %d: %d:%d(%d, %v)", i, m.Object(), m.Opcode(), v, err)
+		}
+	}
+}
+
+func TestWriteMessageFds(t *testing.T) {
+	client, server := testConns(t)
+	r, w := testPipe(t)
+
+	m := NewMessage(3, 0)
+	m.WriteFd(uintptr(r))
+	m.WriteFd(uintptr(w))
+	if err := client.WriteMessage(m); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	// the caller keeps its fds
+	if !fdOpen(r) || !fdOpen(w) {
+		t.Fatal("WriteMessage closed the caller's fds")
+	}
+
+	if _, err := server.ReadMessage(); err != nil {
+		t.Fatal(err)
+	}
+	fds := server.TakeFds()
+	if len(fds) != 2 {
+		t.Fatalf("received %d fds, want 2", len(fds))
+	}
+	for _, fd := range fds {
+		syscall.Close(fd)
+	}
+}
+
+func TestWriteMessageFdLimits(t *testing.T) {
+	client, _ := testConns(t)
+	r, _ := testPipe(t)
+
+	tests := []struct {
+		name string
+		fds  []int
+	}{
+		{"too many fds", func() []int {
+			fds := make([]int, maxFdsOut+1)
+			for i := range fds {
+				fds[i] = r
+			}
+			return fds
+		}()},
+		// the first fd is duplicated before the second one fails
+		{"bad fd", []int{r, 1 << 30}},
+	}
+	for _, tt := range tests {
+		before := numFds(t)
+		m := NewMessage(3, 0)
+		for _, fd := range tt.fds {
+			m.WriteFd(uintptr(fd))
+		}
+		if err := client.WriteMessage(m); err == nil {
+			t.Errorf("%s: WriteMessage succeeded", tt.name)
+		}
+		if len(client.outFds) != 0 || len(client.out) != 0 {
+			t.Errorf("%s: %d fds and %d bytes left buffered", tt.name, len(client.outFds), len(client.out))
+		}
+		if after := numFds(t); after != before {
+			t.Errorf("%s: %d fds open before WriteMessage, %d after", tt.name, before, after)
+		}
+	}
+}
+
+// testInterface has a request and an event carrying two fds, and one of
+// each carrying none.
+var testInterface = &Interface{
+	Name:    "test",
+	Version: 1,
+	Requests: []Signature{
+		{Name: "fds", Since: 1, Args: []ArgDesc{{Name: "a", Type: ArgFd}, {Name: "b", Type: ArgFd}}},
+		{Name: "none", Since: 1},
+	},
+	Events: []Signature{
+		{Name: "fds", Since: 1, Args: []ArgDesc{{Name: "a", Type: ArgFd}, {Name: "b", Type: ArgFd}}},
+		{Name: "none", Since: 1},
+	},
+}
+
+// testObject records the opcodes of the messages it handles.
+type testObject struct {
+	handled []uint16
+}
+
+func (o *testObject) Handle(m *Message) error {
+	o.handled = append(o.handled, m.Opcode())
+	return nil
+}
+
+func (o *testObject) Interface() *Interface {
+	return testInterface
+}
+
+func TestReadMessageClosesUnreadFds(t *testing.T) {
+	client, server := testConns(t)
+	server.AddObject(3, new(testObject))
+	r, w := testPipe(t)
+
+	for _, opcode := range []uint16{0, 1, 1} {
+		m := NewMessage(3, opcode)
+		if opcode == 0 {
+			m.WriteFd(uintptr(r))
+			m.WriteFd(uintptr(w))
+		}
+		if err := client.WriteMessage(m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := client.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	// fds claimed by a message and not read are closed with the next one
+	m, err := server.ReadMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fd, err := m.ReadFd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer syscall.Close(int(fd))
+	unread := m.Fds()[1]
+
+	// fds attached to a received message, as proxies forwarding it do,
+	// belong to whoever attached them
+	m, err = server.ReadMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fdOpen(unread) {
+		t.Error("unread fd left open by ReadMessage")
+	}
+	if !fdOpen(int(fd)) {
+		t.Error("fd read by the handler closed by ReadMessage")
+	}
+	attached, _ := testPipe(t)
+	m.WriteFd(uintptr(attached))
+
+	if _, err = server.ReadMessage(); err != nil {
+		t.Fatal(err)
+	}
+	if !fdOpen(attached) {
+		t.Error("fd attached with WriteFd closed by ReadMessage")
+	}
+}
+
+// motion is a wl_pointer.motion event: time, surface_x, surface_y
+func motion(m *Message) {
+	m.WriteUint(12345)
+	m.WriteFixed(FixedFromFloat(10.5))
+	m.WriteFixed(FixedFromFloat(20.25))
+}
+
+// BenchmarkReadMessage reads and decodes pointer motion events fed
+// through a unix socket in batches.
+func BenchmarkReadMessage(b *testing.B) {
+	client, server := testConns(b)
+
+	const batch = 64
+	m := NewMessage(3, 2)
+	motion(m)
+	var msgs []byte
+	for i := 0; i < batch; i++ {
+		msgs = ByteOrder.AppendUint32(msgs, uint32(m.object))
+		msgs = ByteOrder.AppendUint32(msgs, newHeader(m.object, m.opcode, uint16(len(m.p))).OpcodeSize)
+		msgs = append(msgs, m.p...)
+	}
+	go func(total int) {
+		for n := 0; n < total; n += batch {
+			if _, err := server.c.Write(msgs); err != nil {
+				return
+			}
+		}
+	}(b.N)
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(msgs) / batch))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m, err := client.ReadMessage()
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := m.ReadUint(); err != nil {
+			b.Fatal(err)
+		}
+		if _, err := m.ReadFixed(); err != nil {
+			b.Fatal(err)
+		}
+		if _, err := m.ReadFixed(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkWriteMessage encodes and queues pointer motion events, which
+// go out whenever the outgoing buffer fills up.
+func BenchmarkWriteMessage(b *testing.B) {
+	client, server := testConns(b)
+	go io.Copy(io.Discard, server.c)
+
+	b.ReportAllocs()
+	b.SetBytes(8 + 12)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m := NewMessage(3, 2)
+		motion(m)
+		if err := client.WriteMessage(m); err != nil {
+			b.Fatal(err)
+		}
+	}
+	if err := client.Flush(); err != nil {
+		b.Fatal(err)
+	}
+}
+
+func TestDisplayPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		runtime string // XDG_RUNTIME_DIR, unset if empty
+		want    string
+		wantErr bool
+	}{
+		{"", "/run/user/1000", "/run/user/1000/wayland-0", false},
+		{"wayland-1", "/run/user/1000", "/run/user/1000/wayland-1", false},
+		{"/tmp/wayland-2", "/run/user/1000", "/tmp/wayland-2", false},
+		{"/tmp/wayland-2", "", "/tmp/wayland-2", false},
+		{"wayland-1", "", "", true},
+	}
+	for _, tt := range tests {
+		if tt.runtime == "" {
+			t.Setenv("XDG_RUNTIME_DIR", "")
+			os.Unsetenv("XDG_RUNTIME_DIR")
+		} else {
+			t.Setenv("XDG_RUNTIME_DIR", tt.runtime)
+		}
+		got, err := displayPath(tt.name)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("displayPath(%q) with XDG_RUNTIME_DIR %q = %q, %v", tt.name, tt.runtime, got, err)
+		}
+	}
+}
+
+func TestDial(t *testing.T) {
+	dir := t.TempDir()
+	l, err := ListenPath(filepath.Join(dir, "wayland-3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	tests := []struct {
+		name    string
+		runtime string
+		display string
+	}{
+		{"relative", dir, "wayland-3"},
+		{"absolute", "/nonexistent", filepath.Join(dir, "wayland-3")},
+	}
+	for _, tt := range tests {
+		t.Setenv("WAYLAND_SOCKET", "")
+		t.Setenv("XDG_RUNTIME_DIR", tt.runtime)
+		t.Setenv("WAYLAND_DISPLAY", tt.display)
+		c, err := Dial()
+		if err != nil {
+			t.Errorf("%s: Dial(): %s", tt.name, err)
+			continue
+		}
+		s, err := l.Accept()
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.Close()
+		c.Close()
+	}
+}
+
+func TestDialWaylandSocket(t *testing.T) {
+	tests := []struct {
+		name    string
+		socket  func(t *testing.T) (string, int) // WAYLAND_SOCKET, and the peer fd
+		wantErr bool
+	}{
+		{"socketpair", func(t *testing.T) (string, int) {
+			fds, err := syscall.Socketpair(syscall.AF_UNIX, syscall.SOCK_STREAM, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { syscall.Close(fds[1]) })
+			return strconv.Itoa(fds[0]), fds[1]
+		}, false},
+		{"not a number", func(t *testing.T) (string, int) {
+			return "three", -1
+		}, true},
+		{"not a socket", func(t *testing.T) (string, int) {
+			r, _ := testPipe(t)
+			fd, err := dupCloexec(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return strconv.Itoa(fd), -1
+		}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, peer := tt.socket(t)
+			// WAYLAND_DISPLAY is ignored while WAYLAND_SOCKET is set
+			t.Setenv("WAYLAND_DISPLAY", "/nonexistent")
+			t.Setenv("WAYLAND_SOCKET", s)
+
+			c, err := Dial()
+			if _, ok := os.LookupEnv("WAYLAND_SOCKET"); ok {
+				t.Error("WAYLAND_SOCKET left in the environment")
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Dial() error = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			defer c.Close()
+
+			// the socket is not inherited by child processes
+			fd, _ := strconv.Atoi(s)
+			flags, _, errno := syscall.Syscall(syscall.SYS_FCNTL, uintptr(fd), syscall.F_GETFD, 0)
+			if errno == 0 && flags&syscall.FD_CLOEXEC == 0 {
+				t.Error("WAYLAND_SOCKET fd is inherited by child processes")
+			}
+
+			if err := c.WriteMessage(NewMessage(DisplayId, 0)); err != nil {
+				t.Fatal(err)
+			}
+			if err := c.Flush(); err != nil {
+				t.Fatal(err)
+			}
+			var b [8]byte
+			if n, err := syscall.Read(peer, b[:]); n != 8 || err != nil {
+				t.Errorf("peer read %d bytes, %v, want a message header", n, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package proto
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// Credentials identify the process on the other end of a connection, as
+// reported by the kernel when the connection was made.
+type Credentials struct {
+	Pid int
+	Uid int
+	Gid int
+}
+
+// Executable returns the name of the peer's executable, or "" if it
+// cannot be determined, for instance because the process has exited.
+func (cr Credentials) Executable() string {
+	pid := strconv.Itoa(cr.Pid)
+	if exe, err := os.Readlink(filepath.Join("/proc", pid, "exe")); err == nil {
+		return filepath.Base(exe)
+	}
+	if comm, err := os.ReadFile(filepath.Join("/proc", pid, "comm")); err == nil {
+		return strings.TrimSpace(string(comm))
+	}
+	return ""
+}
+
+// Credentials returns the credentials of the peer, obtained with
+// SO_PEERCRED.
+func (c *Conn) Credentials() (cr Credentials, err error) {
+	var ucred *syscall.Ucred
+	var serr error
+	err = c.rc.Control(func(fd uintptr) {
+		ucred, serr = syscall.GetsockoptUcred(int(fd), syscall.SOL_SOCKET, syscall.SO_PEERCRED)
+	})
+	if err == nil {
+		err = serr
+	}
+	if err != nil {
+		return
+	}
+	return Credentials{Pid: int(ucred.Pid), Uid: int(ucred.Uid), Gid: int(ucred.Gid)}, nil
+}
+
+// SetUserData attaches v to the connection, for instance per-client
+// state kept by a server.
+func (c *Conn) SetUserData(v interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.userData = v
+}
+
+// UserData returns the value set with SetUserData.
+func (c *Conn) UserData() interface{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.userData
+}
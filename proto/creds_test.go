@@ -0,0 +1,55 @@
+package proto
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCredentials(t *testing.T) {
+	client, server := testConns(t)
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// both ends belong to the test process
+	for _, c := range []*Conn{client, server} {
+		cr, err := c.Credentials()
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := Credentials{Pid: os.Getpid(), Uid: os.Getuid(), Gid: os.Getgid()}
+		if cr != want {
+			t.Errorf("Credentials() = %+v, want %+v", cr, want)
+		}
+		if got := cr.Executable(); got != filepath.Base(exe) {
+			t.Errorf("Executable() = %q, want %q", got, filepath.Base(exe))
+		}
+	}
+
+	if got := (Credentials{Pid: -1}).Executable(); got != "" {
+		t.Errorf("Executable() of a missing process = %q, want none", got)
+	}
+}
+
+func TestUserData(t *testing.T) {
+	client, server := testConns(t)
+	if v := server.UserData(); v != nil {
+		t.Errorf("UserData() = %v before SetUserData", v)
+	}
+
+	type state struct{ n int }
+	s := &state{1}
+	server.SetUserData(s)
+	if v, ok := server.UserData().(*state); !ok || v != s {
+		t.Errorf("UserData() = %v, want %v", server.UserData(), s)
+	}
+	if v := client.UserData(); v != nil {
+		t.Errorf("UserData() of the other end = %v", v)
+	}
+	server.SetUserData(nil)
+	if v := server.UserData(); v != nil {
+		t.Errorf("UserData() = %v after SetUserData(nil)", v)
+	}
+}
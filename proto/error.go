@@ -0,0 +1,78 @@
+package proto
+
+import "fmt"
+
+const displayError uint16 = 0
+
+// ProtocolError is a fatal error the server reports with
+// wl_display.error. Once it arrives the connection is unusable, and
+// every further dispatch returns it.
+type ProtocolError struct {
+	ObjectId  ObjectId
+	Interface string
+	Code      uint32
+	Message   string
+}
+
+func (e *ProtocolError) Error() string {
+	return fmt.Sprintf("protocol error on %s@%d, code %d: %s", e.Interface, e.ObjectId, e.Code, e.Message)
+}
+
+// VersionError is returned for a request that is newer than the version
+// of the object it is sent on.
+type VersionError struct {
+	ObjectId  ObjectId
+	Interface string
+	Request   string
+	Since     uint32
+	Version   uint32
+}
+
+func (e *VersionError) Error() string {
+	return fmt.Sprintf("%s@%d.%s needs version %d, object has version %d", e.Interface, e.ObjectId, e.Request, e.Since, e.Version)
+}
+
+// protocolError decodes a wl_display.error event.
+func (c *Conn) protocolError(m *Message) error {
+	e := new(ProtocolError)
+	var err error
+	if e.ObjectId, err = m.ReadObjectId(); err != nil {
+		return err
+	}
+	if e.Code, err = m.ReadUint(); err != nil {
+		return err
+	}
+	if e.Message, err = m.ReadString(); err != nil {
+		return err
+	}
+
+	e.Interface = "[unknown]"
+	c.mu.Lock()
+	if d, ok := c.objects[e.ObjectId].(described); ok && d.Interface() != nil {
+		e.Interface = d.Interface().Name
+	}
+	c.rerr = e
+	c.mu.Unlock()
+	return e
+}
+
+// PostError reports a fatal protocol error on the object with the given
+// id to the client, flushes it and disconnects the client.
+func (c *Conn) PostError(id ObjectId, code uint32, msg string) error {
+	if !c.server {
+		return fmt.Errorf("PostError called on a client connection")
+	}
+
+	m := NewMessage(DisplayId, displayError)
+	m.WriteObjectId(id)
+	m.WriteUint(code)
+	m.WriteString(msg)
+	err := c.WriteMessage(m)
+	if err == nil {
+		err = c.Flush()
+	}
+	if cerr := c.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -0,0 +1,61 @@
+package proto
+
+import (
+	"sync"
+	"syscall"
+)
+
+// fdQueue holds fds received on a connection until messages claim them.
+// Fds travel out of band, so they are not tied to message boundaries:
+// the peer sends them with the first byte of a sendmsg, which may carry
+// many messages or only part of one.
+//
+// The reader pushes and takes fds while Close may close the queue from
+// another goroutine, hence the mutex. Fds pushed after closeAll are
+// closed right away.
+type fdQueue struct {
+	mu     sync.Mutex
+	fds    []int
+	closed bool
+}
+
+func (q *fdQueue) push(fds ...int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		for _, fd := range fds {
+			syscall.Close(fd)
+		}
+		return
+	}
+	q.fds = append(q.fds, fds...)
+}
+
+func (q *fdQueue) len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.fds)
+}
+
+// take removes the first n fds from the queue and appends them to dst.
+// If fewer are queued, as after closeAll, it takes all there are.
+func (q *fdQueue) take(dst []int, n int) []int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if n > len(q.fds) {
+		n = len(q.fds)
+	}
+	dst = append(dst, q.fds[:n]...)
+	q.fds = q.fds[:copy(q.fds, q.fds[n:])]
+	return dst
+}
+
+func (q *fdQueue) closeAll() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, fd := range q.fds {
+		syscall.Close(fd)
+	}
+	q.fds = q.fds[:0]
+	q.closed = true
+}
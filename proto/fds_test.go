@@ -0,0 +1,222 @@
+package proto
+
+import (
+	"reflect"
+	"syscall"
+	"testing"
+)
+
+func TestFdQueue(t *testing.T) {
+	tests := []struct {
+		take []int
+		want [][]int
+		left []int
+	}{
+		{[]int{0}, [][]int{{}}, []int{3, 4, 5}},
+		{[]int{1, 2}, [][]int{{3}, {4, 5}}, []int{}},
+		{[]int{2, 0, 1}, [][]int{{3, 4}, {}, {5}}, []int{}},
+		{[]int{1}, [][]int{{3}}, []int{4, 5}},
+	}
+	for _, tt := range tests {
+		var q fdQueue
+		q.push(3, 4)
+		q.push(5)
+		for i, n := range tt.take {
+			if got := q.take([]int{}, n); !reflect.DeepEqual(got, tt.want[i]) {
+				t.Errorf("take %v: take(%d) = %v, want %v", tt.take, n, got, tt.want[i])
+			}
+		}
+		if got := q.take([]int{}, q.len()); !reflect.DeepEqual(got, tt.left) {
+			t.Errorf("take %v: %v left, want %v", tt.take, got, tt.left)
+		}
+	}
+}
+
+// TestFdQueueClose closes the queue while fds are still being pushed
+// and taken, as Conn.Close does under a reading goroutine.
+func TestFdQueueClose(t *testing.T) {
+	r, _ := testPipe(t)
+	before := numFds(t)
+
+	var q fdQueue
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			fd, err := dupCloexec(r)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			q.push(fd)
+			for _, fd := range q.take(nil, 1) {
+				syscall.Close(fd)
+			}
+		}
+	}()
+	q.closeAll()
+	<-done
+
+	if n := q.len(); n != 0 {
+		t.Errorf("%d fds queued after closeAll", n)
+	}
+	if after := numFds(t); after != before {
+		t.Errorf("%d fds open before, %d after", before, after)
+	}
+}
+
+// sameFile reports whether fds a and b refer to the same file.
+func sameFile(t *testing.T, a, b int) bool {
+	var sa, sb syscall.Stat_t
+	if err := syscall.Fstat(a, &sa); err != nil {
+		t.Fatal(err)
+	}
+	if err := syscall.Fstat(b, &sb); err != nil {
+		t.Fatal(err)
+	}
+	return sa.Dev == sb.Dev && sa.Ino == sb.Ino
+}
+
+func TestReadMessageClaimsFds(t *testing.T) {
+	client, server := testConns(t)
+	server.AddObject(3, new(testObject))
+	r1, w1 := testPipe(t)
+	r2, w2 := testPipe(t)
+
+	// all fds arrive with the first message, and each message claims
+	// the ones its signature asks for
+	sent := [][]int{{r1, w1}, nil, {w2, r2}}
+	for _, fds := range sent {
+		m := NewMessage(3, 1)
+		if fds != nil {
+			m = NewMessage(3, 0)
+		}
+		for _, fd := range fds {
+			m.WriteFd(uintptr(fd))
+		}
+		if err := client.WriteMessage(m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := client.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, fds := range sent {
+		m, err := server.ReadMessage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(m.Fds()) != len(fds) {
+			t.Fatalf("message %d: %d fds, want %d", i, len(m.Fds()), len(fds))
+		}
+		for j, fd := range fds {
+			got, err := m.ReadFd()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !sameFile(t, int(got), fd) {
+				t.Errorf("message %d: fd %d is not the one sent", i, j)
+			}
+			syscall.Close(int(got))
+		}
+	}
+	if n := server.fds.len(); n != 0 {
+		t.Errorf("%d fds left in the queue", n)
+	}
+}
+
+func TestReadMessageMissingFds(t *testing.T) {
+	client, server := testConns(t)
+	server.AddObject(3, new(testObject))
+
+	if err := client.WriteMessage(NewMessage(3, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := server.ReadMessage(); err == nil {
+		t.Fatal("ReadMessage of a message without its fds succeeded")
+	}
+}
+
+// creator registers obj under the new id its message carries, the way
+// requests like wl_registry.bind create objects.
+type creator struct {
+	obj Object
+}
+
+func (o *creator) Handle(m *Message) error {
+	id, err := m.ReadNewId()
+	if err != nil {
+		return err
+	}
+	m.c.AddObject(id, o.obj)
+	return nil
+}
+
+// fdObject keeps the fds of the messages it handles.
+type fdObject struct {
+	handled int
+	fds     []int
+}
+
+func (o *fdObject) Handle(m *Message) error {
+	o.handled++
+	for range m.Fds() {
+		fd, err := m.ReadFd()
+		if err != nil {
+			return err
+		}
+		o.fds = append(o.fds, int(fd))
+	}
+	return nil
+}
+
+func (o *fdObject) Interface() *Interface {
+	return testInterface
+}
+
+func TestDispatchClaimsFdsOfNewObject(t *testing.T) {
+	client, server := testConns(t)
+	ctx := testContext(t)
+	obj := new(fdObject)
+	server.AddObject(3, &creator{obj: obj})
+	r, w := testPipe(t)
+
+	// like wl_registry.bind of wl_shm and wl_shm.create_pool in one flush
+	create := NewMessage(3, 0)
+	create.WriteNewId(4)
+	use := NewMessage(4, 0)
+	use.WriteFd(uintptr(r))
+	use.WriteFd(uintptr(w))
+	for _, m := range []*Message{create, use} {
+		if err := client.WriteMessage(m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := client.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	for obj.handled == 0 {
+		if _, err := server.Dispatch(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	defer func() {
+		for _, fd := range obj.fds {
+			syscall.Close(fd)
+		}
+	}()
+	if len(obj.fds) != 2 {
+		t.Fatalf("new object got %d fds, want 2", len(obj.fds))
+	}
+	if !sameFile(t, obj.fds[0], r) || !sameFile(t, obj.fds[1], w) {
+		t.Error("new object got fds other than the ones sent")
+	}
+	if n := server.fds.len(); n != 0 {
+		t.Errorf("%d fds left in the queue", n)
+	}
+}
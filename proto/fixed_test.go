@@ -0,0 +1,92 @@
+package proto
+
+import "testing"
+
+func TestFixedFromFloat(t *testing.T) {
+	tests := []struct {
+		f    float64
+		want Fixed
+	}{
+		{0, 0},
+		{1, 256},
+		{-1, -256},
+		{10.5, 2688},
+		{-10.5, -2688},
+		{1.0 / 256, 1},
+		{-1.0 / 256, -1},
+		// halfway between two steps rounds away from zero
+		{1.0 / 512, 1},
+		{-1.0 / 512, -1},
+		{0.4 / 256, 0},
+		{-0.4 / 256, 0},
+		{0.6 / 256, 1},
+		{-0.6 / 256, -1},
+		{8388607, 0x7fffff00},
+		{-8388608, -0x80000000},
+	}
+	for _, tt := range tests {
+		if got := FixedFromFloat(tt.f); got != tt.want {
+			t.Errorf("FixedFromFloat(%v) = %#x, want %#x", tt.f, int32(got), int32(tt.want))
+		}
+	}
+}
+
+func TestFixed(t *testing.T) {
+	tests := []struct {
+		f     Fixed
+		int   int32
+		float float64
+		str   string
+	}{
+		{0, 0, 0, "0"},
+		{FixedFromInt(3), 3, 3, "3"},
+		{FixedFromInt(-3), -3, -3, "-3"},
+		{384, 1, 1.5, "1.5"},
+		// Int truncates towards zero, not down
+		{-384, -1, -1.5, "-1.5"},
+		{1, 0, 0.00390625, "0.00390625"},
+		{-1, 0, -0.00390625, "-0.00390625"},
+		{0x7fffffff, 8388607, 8388607.99609375, "8388607.99609375"},
+		{-0x80000000, -8388608, -8388608, "-8388608"},
+	}
+	for _, tt := range tests {
+		if got := tt.f.Int(); got != tt.int {
+			t.Errorf("Fixed(%#x).Int() = %d, want %d", int32(tt.f), got, tt.int)
+		}
+		if got := tt.f.Float(); got != tt.float {
+			t.Errorf("Fixed(%#x).Float() = %v, want %v", int32(tt.f), got, tt.float)
+		}
+		if got := tt.f.String(); got != tt.str {
+			t.Errorf("Fixed(%#x).String() = %q, want %q", int32(tt.f), got, tt.str)
+		}
+		if got := FixedFromFloat(tt.f.Float()); got != tt.f {
+			t.Errorf("FixedFromFloat(%v) = %#x, want %#x", tt.f.Float(), int32(got), int32(tt.f))
+		}
+	}
+}
+
+func TestMessageFixed(t *testing.T) {
+	values := []Fixed{0, 1, -1, 384, -384, 0x7fffffff, -0x80000000}
+
+	m := NewMessage(1, 0)
+	for _, v := range values {
+		m.WriteFixed(v)
+	}
+	m.WriteFixedFloat(-20.25)
+
+	for _, want := range values {
+		got, err := m.ReadFixed()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("ReadFixed() = %#x, want %#x", int32(got), int32(want))
+		}
+	}
+	if got, err := m.ReadFixedFloat(); err != nil || got != -20.25 {
+		t.Errorf("ReadFixedFloat() = %v, %v, want -20.25", got, err)
+	}
+	if _, err := m.ReadFixed(); err == nil {
+		t.Error("ReadFixed() past the end of the payload succeeded")
+	}
+}
@@ -0,0 +1,50 @@
+package proto
+
+import "fmt"
+
+// Global is an object the server advertises with wl_registry.global.
+type Global struct {
+	Name      uint32
+	Interface string
+	Version   uint32
+}
+
+// Registry is the client side of a wl_registry, which generated Bind
+// functions bind globals with. The generated ClientRegistry implements it.
+// Bind sends wl_registry.bind as given; BindGlobal fills in the interface
+// and version from the proxy.
+type Registry interface {
+	Conn() *Conn
+	Bind(name uint32, iface string, version uint32, id Proxy) error
+}
+
+// BindGlobal binds global at the lower of its advertised version and
+// maxVersion. It creates the proxy with newProxy, typically a generated
+// New function of the global's interface, and returns it. The interface
+// and version sent are the proxy's, and the proxy must have the global's
+// interface.
+func BindGlobal[P Proxy](r Registry, global Global, maxVersion uint32, newProxy func(version uint32) P) (P, error) {
+	var none P
+	version := global.Version
+	if version > maxVersion {
+		version = maxVersion
+	}
+	if version == 0 {
+		return none, fmt.Errorf("%s: cannot bind version 0", global.Interface)
+	}
+
+	p := newProxy(version)
+	name := "[unknown]"
+	if iface := p.Interface(); iface != nil {
+		name = iface.Name
+	}
+	if name != global.Interface {
+		r.Conn().DeleteObject(p.Id())
+		return none, fmt.Errorf("global %d is %s, not %s", global.Name, global.Interface, name)
+	}
+	if err := r.Bind(global.Name, name, p.Version(), p); err != nil {
+		r.Conn().DeleteObject(p.Id())
+		return none, err
+	}
+	return p, nil
+}
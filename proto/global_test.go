@@ -0,0 +1,96 @@
+package proto
+
+import (
+	"errors"
+	"testing"
+)
+
+// testRegistry records the wl_registry.bind requests made on it.
+type testRegistry struct {
+	c     *Conn
+	binds []Global // name, interface and version of each bind
+	ids   []ObjectId
+	err   error
+}
+
+func (r *testRegistry) Conn() *Conn {
+	return r.c
+}
+
+func (r *testRegistry) Bind(name uint32, iface string, version uint32, id Proxy) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.binds = append(r.binds, Global{Name: name, Interface: iface, Version: version})
+	r.ids = append(r.ids, id.Id())
+	return nil
+}
+
+// testProxy is a proxy of testInterface.
+type testProxy struct {
+	testObject
+	id      ObjectId
+	version uint32
+}
+
+func (p *testProxy) Id() ObjectId {
+	return p.id
+}
+
+func (p *testProxy) Version() uint32 {
+	return p.version
+}
+
+func newTestProxy(c *Conn, version uint32) *testProxy {
+	p := &testProxy{id: c.NextId(), version: version}
+	c.AddObject(p.id, p)
+	return p
+}
+
+func TestBindGlobal(t *testing.T) {
+	errBind := errors.New("bind failed")
+	tests := []struct {
+		name        string
+		global      Global
+		maxVersion  uint32
+		bindErr     error
+		wantVersion uint32 // 0 if binding fails
+	}{
+		{"advertised version", Global{7, "test", 2}, 5, nil, 2},
+		{"capped version", Global{7, "test", 5}, 3, nil, 3},
+		{"version 0", Global{7, "test", 5}, 0, nil, 0},
+		{"other interface", Global{7, "wl_seat", 5}, 5, nil, 0},
+		{"bind fails", Global{7, "test", 5}, 5, errBind, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, _ := testConns(t)
+			r := &testRegistry{c: client, err: tt.bindErr}
+			var created *testProxy
+
+			p, err := BindGlobal(r, tt.global, tt.maxVersion, func(version uint32) *testProxy {
+				created = newTestProxy(client, version)
+				return created
+			})
+			if tt.wantVersion == 0 {
+				if err == nil {
+					t.Fatalf("BindGlobal() = %v, want error", p)
+				}
+				if created != nil && client.registered(created.id) {
+					t.Errorf("proxy %d left registered after a failed bind", created.id)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p != created || p.Version() != tt.wantVersion {
+				t.Errorf("BindGlobal() = proxy at version %d, want the created one at %d", p.Version(), tt.wantVersion)
+			}
+			want := Global{Name: tt.global.Name, Interface: "test", Version: tt.wantVersion}
+			if len(r.binds) != 1 || r.binds[0] != want || r.ids[0] != p.Id() {
+				t.Errorf("bound %v with ids %v, want %v with id %d", r.binds, r.ids, want, p.Id())
+			}
+		})
+	}
+}
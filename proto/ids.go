@@ -0,0 +1,56 @@
+package proto
+
+import "fmt"
+
+// Object ids are split between the two ends of a connection: the client
+// allocates ids for the objects it creates from the lower range, the
+// server from the upper one.
+const (
+	clientIdMin ObjectId = 1
+	clientIdMax ObjectId = 0xfeffffff
+	serverIdMin ObjectId = 0xff000000
+	serverIdMax ObjectId = 0xffffffff
+)
+
+// idAllocator hands out ids from [min, max], reusing released ids first
+// the way libwayland does.
+type idAllocator struct {
+	min, max  ObjectId
+	next      ObjectId
+	exhausted bool
+	free      []ObjectId
+}
+
+func newIdAllocator(min, max ObjectId) idAllocator {
+	return idAllocator{min: min, max: max, next: min}
+}
+
+func (a *idAllocator) contains(id ObjectId) bool {
+	return a.min <= id && id <= a.max
+}
+
+func (a *idAllocator) alloc() (ObjectId, error) {
+	if n := len(a.free); n != 0 {
+		id := a.free[n-1]
+		a.free = a.free[:n-1]
+		return id, nil
+	}
+	if a.exhausted {
+		return 0, fmt.Errorf("object ids %08x-%08x exhausted", a.min, a.max)
+	}
+	id := a.next
+	if id == a.max {
+		a.exhausted = true
+	} else {
+		a.next++
+	}
+	return id, nil
+}
+
+// release makes id available for reuse. Ids the allocator has not
+// handed out are ignored.
+func (a *idAllocator) release(id ObjectId) {
+	if a.contains(id) && (a.exhausted || id < a.next) {
+		a.free = append(a.free, id)
+	}
+}
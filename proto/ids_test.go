@@ -0,0 +1,81 @@
+package proto
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIdAllocator(t *testing.T) {
+	tests := []struct {
+		name     string
+		min, max ObjectId
+		// ops are allocations, written as 0, and releases of ids
+		ops  []ObjectId
+		want []ObjectId
+	}{
+		{"sequential", 2, 10, []ObjectId{0, 0, 0}, []ObjectId{2, 3, 4}},
+		{"reuse last released first", 2, 10, []ObjectId{0, 0, 0, 3, 2, 0, 0, 0}, []ObjectId{2, 3, 4, 2, 3, 5}},
+		{"ignore ids not handed out", 2, 10, []ObjectId{0, 5, 1, 11, 0}, []ObjectId{2, 3}},
+		{"server range", serverIdMin, serverIdMax, []ObjectId{0, 0, serverIdMin, 0}, []ObjectId{serverIdMin, serverIdMin + 1, serverIdMin}},
+		{"exhausted", 2, 3, []ObjectId{0, 0, 0}, []ObjectId{2, 3}},
+		{"reuse when exhausted", 2, 3, []ObjectId{0, 0, 3, 0, 0}, []ObjectId{2, 3, 3}},
+		{"range end", serverIdMax - 1, serverIdMax, []ObjectId{0, 0, 0, serverIdMax, 0}, []ObjectId{serverIdMax - 1, serverIdMax, serverIdMax}},
+	}
+	for _, tt := range tests {
+		a := newIdAllocator(tt.min, tt.max)
+		var got []ObjectId
+		for _, op := range tt.ops {
+			if op != 0 {
+				a.release(op)
+				continue
+			}
+			if id, err := a.alloc(); err == nil {
+				got = append(got, id)
+			}
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: allocated %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNextIdSkipsDisplay(t *testing.T) {
+	client, server := testConns(t)
+	if id := client.NextId(); id != DisplayId+1 {
+		t.Errorf("client NextId() = %d, want %d", id, DisplayId+1)
+	}
+	if id := server.NextId(); id != serverIdMin {
+		t.Errorf("server NextId() = %#x, want %#x", id, serverIdMin)
+	}
+}
+
+func TestDeleteIdReleasesId(t *testing.T) {
+	client, server := testConns(t)
+	ctx := testContext(t)
+
+	id := client.NextId()
+	client.AddObject(id, new(testObject))
+	next := client.NextId()
+
+	m := NewMessage(DisplayId, displayDeleteId)
+	m.WriteUint(uint32(id))
+	if err := server.WriteMessage(m); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Dispatch(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	client.mu.Lock()
+	_, ok := client.objects[id]
+	client.mu.Unlock()
+	if ok {
+		t.Errorf("object %d still registered after delete_id", id)
+	}
+	if got := client.NextId(); got != id {
+		t.Errorf("NextId() = %d after delete_id of %d (last allocated %d)", got, id, next)
+	}
+}
@@ -0,0 +1,338 @@
+package proto
+
+import (
+	"fmt"
+	"sync"
+	"syscall"
+)
+
+// ArgType is the type of a message argument, written with the letters
+// libwayland uses in message signatures.
+type ArgType byte
+
+const (
+	ArgInt    ArgType = 'i'
+	ArgUint   ArgType = 'u'
+	ArgFixed  ArgType = 'f'
+	ArgString ArgType = 's'
+	ArgObject ArgType = 'o'
+	ArgNewId  ArgType = 'n'
+	ArgArray  ArgType = 'a'
+	ArgFd     ArgType = 'h'
+)
+
+func (t ArgType) String() string {
+	switch t {
+	case ArgInt:
+		return "int"
+	case ArgUint:
+		return "uint"
+	case ArgFixed:
+		return "fixed"
+	case ArgString:
+		return "string"
+	case ArgObject:
+		return "object"
+	case ArgNewId:
+		return "new_id"
+	case ArgArray:
+		return "array"
+	case ArgFd:
+		return "fd"
+	}
+	return fmt.Sprintf("ArgType(%q)", byte(t))
+}
+
+// ArgDesc describes a message argument. Interface names the interface
+// of object and new_id arguments, if the protocol fixes it.
+type ArgDesc struct {
+	Name      string
+	Type      ArgType
+	Interface string
+	Nullable  bool
+}
+
+// Signature describes a request or an event. Since is the interface
+// version that introduced it.
+type Signature struct {
+	Name  string
+	Since int
+	Args  []ArgDesc
+}
+
+// NumFds returns the number of fd arguments.
+func (s *Signature) NumFds() (n int) {
+	for i := range s.Args {
+		if s.Args[i].Type == ArgFd {
+			n++
+		}
+	}
+	return
+}
+
+// Interface describes a protocol interface. Requests and Events are
+// indexed by opcode.
+type Interface struct {
+	Name     string
+	Version  int
+	Requests []Signature
+	Events   []Signature
+}
+
+func (i *Interface) request(opcode uint16) *Signature {
+	if int(opcode) < len(i.Requests) {
+		return &i.Requests[opcode]
+	}
+	return nil
+}
+
+func (i *Interface) event(opcode uint16) *Signature {
+	if int(opcode) < len(i.Events) {
+		return &i.Events[opcode]
+	}
+	return nil
+}
+
+// described is implemented by objects that know their interface, which
+// all generated objects do.
+type described interface {
+	Interface() *Interface
+}
+
+// Proxy is an object created by this end of the connection, as all
+// generated objects are. Requests creating objects of an interface the
+// protocol leaves open, like wl_registry.bind, take one.
+type Proxy interface {
+	Id() ObjectId
+	Version() uint32
+	Interface() *Interface
+}
+
+var registry = struct {
+	sync.RWMutex
+	m map[string]*Interface
+}{m: make(map[string]*Interface)}
+
+// RegisterInterface adds i to the registry of known interfaces. Generated
+// protocol packages register their interfaces on initialization.
+func RegisterInterface(i *Interface) {
+	registry.Lock()
+	defer registry.Unlock()
+	if _, ok := registry.m[i.Name]; ok {
+		panic(fmt.Errorf("interface %s registered twice", i.Name))
+	}
+	registry.m[i.Name] = i
+}
+
+// LookupInterface returns the registered interface with the given name,
+// or nil.
+func LookupInterface(name string) *Interface {
+	registry.RLock()
+	defer registry.RUnlock()
+	return registry.m[name]
+}
+
+// Arg is a decoded message argument. Value holds an int32, uint32,
+// Fixed, string, ObjectId, []byte or, for fds, an int; a null string
+// is nil, a null object ObjectId(0).
+type Arg struct {
+	*ArgDesc
+	Value interface{}
+}
+
+func (a Arg) String() string {
+	switch a.Type {
+	case ArgString:
+		if a.Value == nil {
+			return "nil"
+		}
+		return fmt.Sprintf("%q", a.Value)
+	case ArgObject, ArgNewId:
+		id := a.Value.(ObjectId)
+		if id == 0 {
+			return "nil"
+		}
+		iface := a.Interface
+		if iface == "" {
+			iface = "[unknown]"
+		}
+		if a.Type == ArgNewId {
+			return fmt.Sprintf("new id %s@%d", iface, id)
+		}
+		return fmt.Sprintf("%s@%d", iface, id)
+	case ArgArray:
+		return fmt.Sprintf("array[%d]", len(a.Value.([]byte)))
+	case ArgFd:
+		return fmt.Sprintf("fd %d", a.Value)
+	}
+	return fmt.Sprint(a.Value)
+}
+
+// Decode reads all arguments of m as described by sig. Fds are read from
+// the ones attached to m, and ownership passes to the caller.
+func (m *Message) Decode(sig *Signature) ([]Arg, error) {
+	args := make([]Arg, len(sig.Args))
+	for i := range sig.Args {
+		d := &sig.Args[i]
+		args[i].ArgDesc = d
+
+		var (
+			v    interface{}
+			null bool
+			err  error
+		)
+		switch d.Type {
+		case ArgInt:
+			v, err = m.ReadInt()
+		case ArgUint:
+			v, err = m.ReadUint()
+		case ArgFixed:
+			v, err = m.ReadFixed()
+		case ArgString:
+			var s string
+			if s, null, err = m.readString(); err == nil && !null {
+				v = s
+			}
+		case ArgObject:
+			var id ObjectId
+			id, err = m.ReadObjectId()
+			v, null = id, id == 0
+		case ArgNewId:
+			var id ObjectId
+			id, err = m.ReadNewId()
+			v, null = id, id == 0
+		case ArgArray:
+			v, err = m.ReadArray()
+		case ArgFd:
+			var fd uintptr
+			fd, err = m.ReadFd()
+			v = int(fd)
+		default:
+			err = fmt.Errorf("unknown argument type %s", d.Type)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: argument %s: %s", sig.Name, d.Name, err)
+		}
+		if null && !d.Nullable {
+			return nil, fmt.Errorf("%s: argument %s: null %s", sig.Name, d.Name, d.Type)
+		}
+		args[i].Value = v
+	}
+	return args, nil
+}
+
+// Encode appends args to m. Values have the types Decode returns, and fd
+// arguments are attached as by WriteFd.
+func (m *Message) Encode(args []Arg) error {
+	for i := range args {
+		if err := m.encodeArg(args[i]); err != nil {
+			return err
+		}
+		if args[i].Type == ArgFd {
+			m.WriteFd(uintptr(args[i].Value.(int)))
+		}
+	}
+	return nil
+}
+
+// encodeArg appends the payload of a, checking the type of its value.
+// Fds have no payload.
+func (m *Message) encodeArg(a Arg) error {
+	ok, null := true, false
+	switch a.Type {
+	case ArgInt:
+		var v int32
+		v, ok = a.Value.(int32)
+		m.WriteInt(v)
+	case ArgUint:
+		var v uint32
+		v, ok = a.Value.(uint32)
+		m.WriteUint(v)
+	case ArgFixed:
+		var v Fixed
+		v, ok = a.Value.(Fixed)
+		m.WriteFixed(v)
+	case ArgString:
+		if null = a.Value == nil; null {
+			m.WriteUint(0)
+			break
+		}
+		var v string
+		v, ok = a.Value.(string)
+		m.WriteString(v)
+	case ArgObject, ArgNewId:
+		var v ObjectId
+		v, ok = a.Value.(ObjectId)
+		null = v == 0
+		m.WriteObjectId(v)
+	case ArgArray:
+		var v []byte
+		v, ok = a.Value.([]byte)
+		m.WriteArray(v)
+	case ArgFd:
+		_, ok = a.Value.(int)
+	default:
+		return fmt.Errorf("unknown argument type %s", a.Type)
+	}
+	if !ok {
+		return fmt.Errorf("argument %s: %T is not a valid %s", a.Name, a.Value, a.Type)
+	}
+	if null && !a.Nullable {
+		return fmt.Errorf("argument %s: null %s", a.Name, a.Type)
+	}
+	return nil
+}
+
+// Set replaces argument i of m with v and re-encodes m, which must have a
+// signature. v has the type Decode returns for the argument. Replacing
+// an fd m owns, one received or duplicated that nobody read, closes it
+// and passes ownership of v to m. Other fds, such as those attached to
+// outgoing messages with WriteFd, stay with their owners, and so does v.
+// Set rewinds m.
+func (m *Message) Set(i int, v interface{}) error {
+	if m.sig == nil {
+		return fmt.Errorf("message %d:%d: signature unknown", m.object, m.opcode)
+	}
+	if i < 0 || i >= len(m.sig.Args) {
+		return fmt.Errorf("%s: no argument %d", m.sig.Name, i)
+	}
+
+	// decode a copy, leaving m's fds and new id checks alone
+	d := *m
+	d.off, d.fdi, d.c = 0, 0, nil
+	args, err := d.Decode(m.sig)
+	if err != nil {
+		return err
+	}
+	args[i].Value = v
+
+	pp := payloadPool.Get().(*[]byte)
+	e := Message{p: (*pp)[:0]}
+	for j := range args {
+		if err = e.encodeArg(args[j]); err != nil {
+			*pp = e.p[:0]
+			payloadPool.Put(pp)
+			return fmt.Errorf("%s: %s", m.sig.Name, err)
+		}
+	}
+	if m.pooled != nil {
+		*m.pooled = m.p[:0]
+		payloadPool.Put(m.pooled)
+	}
+	m.p, m.pooled = e.p, pp
+
+	if args[i].Type == ArgFd {
+		k := 0
+		for j := 0; j < i; j++ {
+			if args[j].Type == ArgFd {
+				k++
+			}
+		}
+		if m.owns(k) {
+			syscall.Close(m.fds[k])
+		}
+		m.fds[k] = v.(int)
+	}
+	m.Rewind()
+	return nil
+}
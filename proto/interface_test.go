@@ -0,0 +1,164 @@
+package proto
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	r, _ := testPipe(t)
+	tests := []struct {
+		desc ArgDesc
+		v    interface{}
+		str  string
+	}{
+		{ArgDesc{Name: "i", Type: ArgInt}, int32(-7), "-7"},
+		{ArgDesc{Name: "u", Type: ArgUint}, uint32(0xffffffff), "4294967295"},
+		{ArgDesc{Name: "f", Type: ArgFixed}, FixedFromFloat(-1.5), "-1.5"},
+		{ArgDesc{Name: "s", Type: ArgString}, "hello", `"hello"`},
+		{ArgDesc{Name: "s", Type: ArgString}, "", `""`},
+		{ArgDesc{Name: "s", Type: ArgString, Nullable: true}, nil, "nil"},
+		{ArgDesc{Name: "o", Type: ArgObject, Interface: "wl_surface"}, ObjectId(3), "wl_surface@3"},
+		{ArgDesc{Name: "o", Type: ArgObject}, ObjectId(3), "[unknown]@3"},
+		{ArgDesc{Name: "o", Type: ArgObject, Nullable: true}, ObjectId(0), "nil"},
+		{ArgDesc{Name: "n", Type: ArgNewId, Interface: "wl_callback"}, ObjectId(4), "new id wl_callback@4"},
+		{ArgDesc{Name: "a", Type: ArgArray}, []byte{1, 2, 3}, "array[3]"},
+		{ArgDesc{Name: "a", Type: ArgArray}, []byte{}, "array[0]"},
+		{ArgDesc{Name: "h", Type: ArgFd}, r, fmt.Sprintf("fd %d", r)},
+	}
+	for _, tt := range tests {
+		m := NewMessage(3, 0)
+		if err := m.Encode([]Arg{{&tt.desc, tt.v}}); err != nil {
+			t.Errorf("Encode(%s %v): %s", tt.desc.Type, tt.v, err)
+			continue
+		}
+		m.WriteUint(0xdeadbeef)
+
+		sig := &Signature{Name: "test", Args: []ArgDesc{tt.desc}}
+		args, err := m.Decode(sig)
+		if err != nil {
+			t.Errorf("Decode(%s %v): %s", tt.desc.Type, tt.v, err)
+			continue
+		}
+		if !reflect.DeepEqual(args[0].Value, tt.v) {
+			t.Errorf("Decode(%s %v) = %#v", tt.desc.Type, tt.v, args[0].Value)
+		}
+		if got := args[0].String(); got != tt.str {
+			t.Errorf("%s %v formats as %s, want %s", tt.desc.Type, tt.v, got, tt.str)
+		}
+		// the argument was read whole, padding included
+		if v, err := m.ReadUint(); err != nil || v != 0xdeadbeef {
+			t.Errorf("%s %v: ReadUint() after Decode = %#x, %v", tt.desc.Type, tt.v, v, err)
+		}
+	}
+}
+
+func TestDecodeNull(t *testing.T) {
+	types := []ArgType{ArgString, ArgObject, ArgNewId}
+	for _, typ := range types {
+		for _, nullable := range []bool{false, true} {
+			desc := ArgDesc{Name: "x", Type: typ, Nullable: nullable}
+			m := NewMessage(3, 0)
+			m.WriteUint(0)
+
+			_, err := m.Decode(&Signature{Name: "test", Args: []ArgDesc{desc}})
+			if (err == nil) != nullable {
+				t.Errorf("Decode of null %s, nullable %v: error %v", typ, nullable, err)
+			}
+
+			var v interface{}
+			if typ != ArgString {
+				v = ObjectId(0)
+			}
+			err = NewMessage(3, 0).Encode([]Arg{{&desc, v}})
+			if (err == nil) != nullable {
+				t.Errorf("Encode of null %s, nullable %v: error %v", typ, nullable, err)
+			}
+		}
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		args []ArgDesc
+		p    []byte
+	}{
+		{"short payload", []ArgDesc{{Name: "u", Type: ArgUint}}, []byte{1, 2}},
+		{"string beyond payload", []ArgDesc{{Name: "s", Type: ArgString}}, []byte{9, 0, 0, 0, 'a', 0, 0, 0}},
+		{"missing fd", []ArgDesc{{Name: "h", Type: ArgFd}}, nil},
+		{"unknown type", []ArgDesc{{Name: "x", Type: 'x'}}, []byte{0, 0, 0, 0}},
+	}
+	for _, tt := range tests {
+		m := &Message{p: tt.p}
+		if args, err := m.Decode(&Signature{Name: "test", Args: tt.args}); err == nil {
+			t.Errorf("%s: Decode() = %v, want error", tt.name, args)
+		}
+	}
+}
+
+func TestEncodeTypeMismatch(t *testing.T) {
+	tests := []Arg{
+		{&ArgDesc{Name: "i", Type: ArgInt}, uint32(1)},
+		{&ArgDesc{Name: "u", Type: ArgUint}, 1},
+		{&ArgDesc{Name: "f", Type: ArgFixed}, 1.5},
+		{&ArgDesc{Name: "s", Type: ArgString}, []byte("s")},
+		{&ArgDesc{Name: "o", Type: ArgObject}, uint32(3)},
+		{&ArgDesc{Name: "a", Type: ArgArray}, "a"},
+		{&ArgDesc{Name: "h", Type: ArgFd}, uintptr(3)},
+	}
+	for _, a := range tests {
+		if err := NewMessage(3, 0).Encode([]Arg{a}); err == nil {
+			t.Errorf("Encode of %T as %s succeeded", a.Value, a.Type)
+		}
+	}
+}
+
+func TestDecodeNewIdRange(t *testing.T) {
+	client, server := testConns(t)
+	client.AddObject(serverIdMin, new(testObject))
+	server.AddObject(2, new(testObject))
+
+	tests := []struct {
+		name    string
+		c       *Conn // the end receiving the new_id
+		id      ObjectId
+		wantErr bool
+	}{
+		{"client id on server", server, 3, false},
+		{"server id on server", server, serverIdMin, true},
+		{"client id in use", server, 2, true},
+		{"server id on client", client, serverIdMin + 1, false},
+		{"client id on client", client, 3, true},
+		{"server id in use", client, serverIdMin, true},
+	}
+	desc := ArgDesc{Name: "id", Type: ArgNewId}
+	for _, tt := range tests {
+		m := NewMessage(3, 0)
+		m.WriteNewId(tt.id)
+		m.c = tt.c
+		_, err := m.Decode(&Signature{Name: "test", Args: []ArgDesc{desc}})
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: Decode() error = %v, want error %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestRegisterInterface(t *testing.T) {
+	i := &Interface{Name: "test_register", Version: 1}
+	RegisterInterface(i)
+	if got := LookupInterface("test_register"); got != i {
+		t.Errorf("LookupInterface() = %v, want %v", got, i)
+	}
+	if got := LookupInterface("test_unregistered"); got != nil {
+		t.Errorf("LookupInterface() of an unregistered name = %v, want nil", got)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("registering an interface twice did not panic")
+		}
+	}()
+	RegisterInterface(&Interface{Name: "test_register"})
+}
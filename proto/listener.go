@@ -0,0 +1,174 @@
+package proto
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+)
+
+// maxAutoDisplays is how many wayland-N names ListenAuto tries, as in
+// libwayland.
+const maxAutoDisplays = 32
+
+// Listener accepts client connections on a wayland socket. Sockets it
+// creates are guarded by a "<socket>.lock" file held with flock, as
+// libwayland does, and removed together with it on Close.
+type Listener struct {
+	l    *net.UnixListener
+	name string
+	path string
+	lock *os.File
+}
+
+// Listen listens on the display named by WAYLAND_DISPLAY, or on
+// "wayland-0".
+func Listen() (*Listener, error) {
+	return ListenName(os.Getenv("WAYLAND_DISPLAY"))
+}
+
+// ListenName listens on the named display; see Dial for how display
+// names map to socket paths.
+func ListenName(name string) (*Listener, error) {
+	path, err := displayPath(name)
+	if err != nil {
+		return nil, err
+	}
+	return ListenPath(path)
+}
+
+// ListenAuto listens on the first free display out of wayland-0 to
+// wayland-31.
+func ListenAuto() (*Listener, error) {
+	for i := 0; i < maxAutoDisplays; i++ {
+		path, err := displayPath("wayland-" + strconv.Itoa(i))
+		if err != nil {
+			return nil, err
+		}
+		l, err := ListenPath(path)
+		if err == nil {
+			return l, nil
+		}
+		if !errors.Is(err, errSocketInUse) {
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("no free display name, wayland-0 to wayland-%d are in use", maxAutoDisplays-1)
+}
+
+var errSocketInUse = errors.New("socket is in use by another server")
+
+// ListenPath listens on the socket at path. It takes the socket's lock
+// file first and fails if another server holds it; a socket left over
+// by a server that no longer holds the lock is removed.
+func ListenPath(path string) (*Listener, error) {
+	lock, err := os.OpenFile(path+".lock", os.O_RDWR|os.O_CREATE, 0660)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Flock(int(lock.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		lock.Close()
+		if err == syscall.EWOULDBLOCK {
+			return nil, fmt.Errorf("%s: %w", path, errSocketInUse)
+		}
+		return nil, fmt.Errorf("locking %s.lock: %s", path, err)
+	}
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		unlock(lock)
+		return nil, fmt.Errorf("removing stale socket: %s", err)
+	}
+
+	ul, err := net.ListenUnix("unix", &net.UnixAddr{Net: "unix", Name: path})
+	if err != nil {
+		unlock(lock)
+		return nil, err
+	}
+	return &Listener{l: ul, name: filepath.Base(path), path: path, lock: lock}, nil
+}
+
+func unlock(lock *os.File) {
+	os.Remove(lock.Name())
+	lock.Close()
+}
+
+// ListenFD listens on the already bound and listening socket fd, which
+// the returned Listener takes ownership of.
+func ListenFD(fd int) (*Listener, error) {
+	f := os.NewFile(uintptr(fd), "wayland-listener")
+	fl, err := net.FileListener(f)
+	f.Close()
+	if err != nil {
+		return nil, err
+	}
+	ul, ok := fl.(*net.UnixListener)
+	if !ok {
+		fl.Close()
+		return nil, fmt.Errorf("fd %d is not a unix socket", fd)
+	}
+	ul.SetUnlinkOnClose(false)
+	return &Listener{l: ul, name: ul.Addr().String()}, nil
+}
+
+// ListenActivated returns listeners for the sockets passed by a service
+// manager using the systemd socket activation protocol, or none if the
+// process was not socket activated.
+func ListenActivated() ([]*Listener, error) {
+	const listenFdsStart = 3
+	return listenActivated(listenFdsStart)
+}
+
+// listenActivated is ListenActivated with the passed fds starting at
+// start instead of 3.
+func listenActivated(start int) ([]*Listener, error) {
+	if pid, err := strconv.Atoi(os.Getenv("LISTEN_PID")); err != nil || pid != os.Getpid() {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid LISTEN_FDS: %s", err)
+	}
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+
+	ls := make([]*Listener, 0, n)
+	for fd := start; fd < start+n; fd++ {
+		syscall.CloseOnExec(fd)
+		l, err := ListenFD(fd)
+		if err != nil {
+			for _, l := range ls {
+				l.Close()
+			}
+			return nil, err
+		}
+		ls = append(ls, l)
+	}
+	return ls, nil
+}
+
+// Name returns the display name clients use to connect, such as
+// "wayland-1", or the socket address for listeners made with ListenFD.
+func (l *Listener) Name() string {
+	return l.name
+}
+
+func (l *Listener) Accept() (*Conn, error) {
+	uc, err := l.l.AcceptUnix()
+	if err != nil {
+		return nil, err
+	}
+	return newConn(uc, "server")
+}
+
+// Close stops listening and removes the socket and its lock file.
+func (l *Listener) Close() error {
+	err := l.l.Close()
+	if l.lock != nil {
+		unlock(l.lock)
+	}
+	return err
+}
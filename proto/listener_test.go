@@ -0,0 +1,230 @@
+package proto
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"testing"
+)
+
+// exists reports whether there is a file at path.
+func exists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
+
+// dialable reports whether a client can connect to l.
+func dialable(t *testing.T, l *Listener, path string) bool {
+	c, err := DialPath(path)
+	if err != nil {
+		return false
+	}
+	defer c.Close()
+	s, err := l.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+	return true
+}
+
+func TestListenPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		prepare func(t *testing.T, path string)
+		wantErr error
+	}{
+		{"fresh", func(t *testing.T, path string) {}, nil},
+		{"stale socket", func(t *testing.T, path string) {
+			// left behind by a server that died without cleaning up
+			ul, err := net.ListenUnix("unix", &net.UnixAddr{Net: "unix", Name: path})
+			if err != nil {
+				t.Fatal(err)
+			}
+			ul.SetUnlinkOnClose(false)
+			ul.Close()
+			os.WriteFile(path+".lock", nil, 0660)
+		}, nil},
+		{"in use", func(t *testing.T, path string) {
+			l, err := ListenPath(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { l.Close() })
+		}, errSocketInUse},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "wayland-0")
+			tt.prepare(t, path)
+
+			l, err := ListenPath(path)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ListenPath() = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if l.Name() != "wayland-0" {
+				t.Errorf("Name() = %q, want wayland-0", l.Name())
+			}
+			if !dialable(t, l, path) {
+				t.Error("cannot connect to the listener")
+			}
+
+			if err := l.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if exists(path) || exists(path+".lock") {
+				t.Error("socket or lock file left behind by Close")
+			}
+			// the display is free again
+			l, err = ListenPath(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			l.Close()
+		})
+	}
+}
+
+func TestListenAuto(t *testing.T) {
+	tests := []struct {
+		name     string
+		taken    int // displays wayland-0 to wayland-<taken-1> are in use
+		wantName string
+	}{
+		{"first", 0, "wayland-0"},
+		{"next free", 2, "wayland-2"},
+		{"last", maxAutoDisplays - 1, "wayland-31"},
+		{"all taken", maxAutoDisplays, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			t.Setenv("XDG_RUNTIME_DIR", dir)
+			for i := 0; i < tt.taken; i++ {
+				l, err := ListenName("wayland-" + strconv.Itoa(i))
+				if err != nil {
+					t.Fatal(err)
+				}
+				t.Cleanup(func() { l.Close() })
+			}
+
+			l, err := ListenAuto()
+			if tt.wantName == "" {
+				if err == nil {
+					t.Errorf("ListenAuto() = %s, want error", l.Name())
+					l.Close()
+				}
+				if exists(filepath.Join(dir, "wayland-32")) || exists(filepath.Join(dir, "wayland-32.lock")) {
+					t.Error("ListenAuto tried wayland-32")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer l.Close()
+			if l.Name() != tt.wantName {
+				t.Errorf("ListenAuto() listens on %s, want %s", l.Name(), tt.wantName)
+			}
+			if !dialable(t, l, filepath.Join(dir, tt.wantName)) {
+				t.Error("cannot connect to the listener")
+			}
+		})
+	}
+}
+
+func TestListen(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_RUNTIME_DIR", dir)
+	t.Setenv("WAYLAND_DISPLAY", "wayland-5")
+
+	l, err := Listen()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	if l.Name() != "wayland-5" || !exists(filepath.Join(dir, "wayland-5")) {
+		t.Errorf("Listen() listens on %s, want wayland-5 in XDG_RUNTIME_DIR", l.Name())
+	}
+}
+
+// activationFd is where TestListenActivated places the passed sockets,
+// well above the fds the test binary has open.
+const activationFd = 200
+
+func TestListenActivated(t *testing.T) {
+	dir := t.TempDir()
+	var paths []string
+	for i := 0; i < 2; i++ {
+		fd := activationFd + i
+		if fdOpen(fd) {
+			t.Skipf("fd %d is in use", fd)
+		}
+		path := filepath.Join(dir, "wayland-"+strconv.Itoa(i))
+		ul, err := net.ListenUnix("unix", &net.UnixAddr{Net: "unix", Name: path})
+		if err != nil {
+			t.Fatal(err)
+		}
+		f, err := ul.File()
+		ul.SetUnlinkOnClose(false)
+		ul.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = syscall.Dup3(int(f.Fd()), fd, 0)
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { syscall.Close(fd) })
+		paths = append(paths, path)
+	}
+	pid := strconv.Itoa(os.Getpid())
+
+	tests := []struct {
+		name      string
+		pid, fds  string
+		wantCount int
+		wantErr   bool
+	}{
+		{"not activated", "", "", 0, false},
+		{"other process", "1", "2", 0, false},
+		{"invalid LISTEN_FDS", pid, "two", 0, true},
+		{"activated", pid, "2", 2, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("LISTEN_PID", tt.pid)
+			t.Setenv("LISTEN_FDS", tt.fds)
+
+			ls, err := listenActivated(activationFd)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ListenActivated() error = %v, want error %v", err, tt.wantErr)
+			}
+			if len(ls) != tt.wantCount {
+				t.Fatalf("ListenActivated() returned %d listeners, want %d", len(ls), tt.wantCount)
+			}
+			for i, l := range ls {
+				if !dialable(t, l, paths[i]) {
+					t.Errorf("cannot connect to listener %d", i)
+				}
+				l.Close()
+				// the socket belongs to the service manager
+				if !exists(paths[i]) {
+					t.Errorf("Close removed %s", paths[i])
+				}
+			}
+			if tt.wantCount != 0 {
+				if _, ok := os.LookupEnv("LISTEN_FDS"); ok {
+					t.Error("LISTEN_FDS left in the environment")
+				}
+			}
+		})
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"encoding/binary"
 	"fmt"
+	"sync"
+	"syscall"
 )
 
 var HostOrder = binary.LittleEndian
@@ -14,16 +16,34 @@ func (o ObjectId) NewMessage(opcode uint16) *Message {
 	return NewMessage(o, opcode)
 }
 
+// Message is a single wayland request or event. Messages returned by
+// Conn.ReadMessage are views into the connection's input buffer; see
+// Retain for keeping one around.
 type Message struct {
 	object ObjectId
 	opcode uint16
-	p      *bytes.Buffer
+	p      []byte
+	off    int
 	fds    []int
 	fdi    int
+
+	// m owns fds[nread:nown]: the fds ReadMessage claimed for it, or
+	// that it took over or duplicated, which nobody has read. Fds
+	// attached with WriteFd stay with whoever attached them. nread only
+	// grows, so that Rewind does not hand read fds back to m.
+	nread, nown int
+
+	// c is the connection a received message came from
+	c      *Conn
+	pooled *[]byte
+
+	// iface and sig describe the message when its object is known
+	iface *Interface
+	sig   *Signature
 }
 
 func NewMessage(object ObjectId, opcode uint16) *Message {
-	return &Message{object: object, opcode: opcode, p: new(bytes.Buffer)}
+	return &Message{object: object, opcode: opcode}
 }
 
 func (m *Message) Object() ObjectId {
@@ -34,57 +54,213 @@ func (m *Message) Opcode() uint16 {
 	return m.opcode
 }
 
+// Interface returns the interface of the object m is addressed to, and
+// Signature the signature of m. Both are known for messages sent or
+// received by a Conn that has the object registered, and nil otherwise.
+func (m *Message) Interface() *Interface {
+	return m.iface
+}
+
+func (m *Message) Signature() *Signature {
+	return m.sig
+}
+
+// Fds returns the fds attached to m.
+func (m *Message) Fds() []int {
+	return m.fds
+}
+
 func (m *Message) String() string {
-	var (
-		len   int
-		bytes []byte
-	)
-	if m.p != nil {
-		len = m.p.Len()
-		bytes = m.p.Bytes()[:len]
+	b := new(bytes.Buffer)
+	m.format(b)
+	return b.String()
+}
+
+// format writes m the way libwayland traces messages, with its arguments
+// decoded if the signature is known.
+func (m *Message) format(b *bytes.Buffer) {
+	iface, name := "[unknown]", fmt.Sprintf("opcode %d", m.Opcode())
+	if m.iface != nil {
+		iface = m.iface.Name
+	}
+	if m.sig != nil {
+		name = m.sig.Name
 	}
+	fmt.Fprintf(b, "%s@%d.%s(", iface, m.Object(), name)
 
-	return fmt.Sprintf("Message{obj:%d, opcode:%d, fds: %+v, payload(%d): %+v}", m.object, m.opcode, m.fds, len, bytes)
+	if m.sig != nil {
+		// decode a copy, leaving m unread
+		d := *m
+		d.off, d.fdi, d.c = 0, 0, nil
+		args, err := d.Decode(m.sig)
+		for i := range args {
+			if i != 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString(args[i].String())
+		}
+		if err != nil {
+			fmt.Fprintf(b, "undecodable: %s", err)
+		}
+	} else {
+		fmt.Fprintf(b, "%d bytes", len(m.p))
+		for _, fd := range m.Fds() {
+			fmt.Fprintf(b, ", fd %d", fd)
+		}
+	}
+	b.WriteString(")")
 }
 
-func (m *Message) ReadInt() (v int32, err error) {
-	err = binary.Read(m.p, HostOrder, &v)
-	return
+var payloadPool = sync.Pool{
+	New: func() interface{} {
+		p := make([]byte, 0, 64)
+		return &p
+	},
+}
+
+// Retain returns a copy of m that stays valid after the next
+// Conn.ReadMessage. The copy's payload comes from a pool and the copy
+// takes over the fds m owns. Call Release once done with it.
+func (m *Message) Retain() *Message {
+	pp := payloadPool.Get().(*[]byte)
+	*pp = append((*pp)[:0], m.p...)
+	r := &Message{
+		object: m.object,
+		opcode: m.opcode,
+		p:      *pp,
+		off:    m.off,
+		fds:    append([]int(nil), m.fds...),
+		fdi:    m.fdi,
+		nread:  m.nread,
+		nown:   m.nown,
+		c:      m.c,
+		pooled: pp,
+		iface:  m.iface,
+		sig:    m.sig,
+	}
+	m.nown = 0
+	return r
+}
+
+// Clone returns a copy of m, positioned at its first argument, that
+// owns duplicates of m's fds. Either message can then be read, forwarded
+// or released independently. Clone fails once fds have been read from m,
+// as their ownership has passed on. Call Release once done with the copy.
+func (m *Message) Clone() (*Message, error) {
+	if m.nread != 0 {
+		return nil, fmt.Errorf("message %d:%d: cannot clone after reading fds", m.object, m.opcode)
+	}
+	fds := make([]int, 0, len(m.fds))
+	for _, fd := range m.fds {
+		dup, err := dupCloexec(fd)
+		if err != nil {
+			for _, fd := range fds {
+				syscall.Close(fd)
+			}
+			return nil, err
+		}
+		fds = append(fds, dup)
+	}
+	pp := payloadPool.Get().(*[]byte)
+	*pp = append((*pp)[:0], m.p...)
+	return &Message{
+		object: m.object,
+		opcode: m.opcode,
+		p:      *pp,
+		fds:    fds,
+		nown:   len(fds),
+		c:      m.c,
+		pooled: pp,
+		iface:  m.iface,
+		sig:    m.sig,
+	}, nil
+}
+
+// Rewind moves m back to its first argument, so it can be read again or
+// forwarded whole. Fds read so far stay with whoever read them: reading
+// them again returns the same fds, which m does not close.
+func (m *Message) Rewind() {
+	m.off, m.fdi = 0, 0
+}
+
+// Release returns the payload of a retained or cloned message to the
+// pool and closes the fds it owns. m must not be used afterwards.
+func (m *Message) Release() {
+	m.closeOwnedFds()
+	if m.pooled != nil {
+		*m.pooled = m.p[:0]
+		payloadPool.Put(m.pooled)
+		m.pooled, m.p = nil, nil
+	}
+}
+
+// next consumes n bytes of payload.
+func (m *Message) next(n int) ([]byte, error) {
+	if len(m.p)-m.off < n {
+		return nil, fmt.Errorf("message %d:%d: payload too short", m.object, m.opcode)
+	}
+	b := m.p[m.off : m.off+n]
+	m.off += n
+	return b, nil
+}
+
+func (m *Message) ReadInt() (int32, error) {
+	v, err := m.ReadUint()
+	return int32(v), err
 }
 
 func (m *Message) WriteInt(v int32) error {
-	return binary.Write(m.p, HostOrder, v)
+	return m.WriteUint(uint32(v))
 }
 
-func (m *Message) ReadUint() (v uint32, err error) {
-	err = binary.Read(m.p, HostOrder, &v)
-	return
+func (m *Message) ReadUint() (uint32, error) {
+	b, err := m.next(4)
+	if err != nil {
+		return 0, err
+	}
+	return HostOrder.Uint32(b), nil
 }
 
 func (m *Message) WriteUint(v uint32) error {
-	return binary.Write(m.p, HostOrder, v)
+	m.p = HostOrder.AppendUint32(m.p, v)
+	return nil
 }
 
-func (m *Message) ReadFixed() (v float32, err error) {
-	var u uint32
-	if err = binary.Read(m.p, HostOrder, &u); err != nil {
-		return
-	}
-	// TODO: make float32
-	return
+func (m *Message) ReadFixed() (Fixed, error) {
+	v, err := m.ReadUint()
+	return Fixed(v), err
 }
-func (m *Message) WriteFixed(v float32) error {
-	var u uint32 // TODO: make uint32
-	return binary.Write(m.p, HostOrder, u)
+
+func (m *Message) WriteFixed(f Fixed) error {
+	return m.WriteUint(uint32(f))
+}
+
+func (m *Message) ReadFixedFloat() (v float64, err error) {
+	f, err := m.ReadFixed()
+	return f.Float(), err
+}
+
+func (m *Message) WriteFixedFloat(v float64) error {
+	return m.WriteFixed(FixedFromFloat(v))
 }
 
 func (m *Message) ReadString() (s string, err error) {
-	var l uint32
-	if err = binary.Read(m.p, HostOrder, &l); err != nil {
+	s, _, err = m.readString()
+	return
+}
+
+// readString reads a string argument and reports whether it is null.
+func (m *Message) readString() (s string, null bool, err error) {
+	l, err := m.ReadUint()
+	if err != nil || l == 0 {
+		return "", err == nil, err
+	}
+	if l > uint32(len(m.p)-m.off) {
+		err = fmt.Errorf("string of %d bytes exceeds message payload", l)
 		return
 	}
-	b := make([]byte, stringWireLen(l))
-	if _, err = m.p.Read(b); err != nil {
+	b, err := m.next(int(wireLen(l)))
+	if err != nil {
 		return
 	}
 	s = string(b[:l-1])
@@ -94,52 +270,146 @@ func (m *Message) ReadString() (s string, err error) {
 func (m *Message) WriteString(s string) (err error) {
 	// TODO: do we need to handle multibyte strings?
 	l := uint32(len(s)) + 1
-	if err = binary.Write(m.p, HostOrder, l); err != nil {
-		return
-	}
-	d := make([]byte, stringWireLen(l))
-	copy(d, s)
-	_, err = m.p.Write(d)
-	return
+	m.WriteUint(l)
+	m.p = append(m.p, s...)
+	m.p = append(m.p, make([]byte, wireLen(l)-l+1)...)
+	return nil
 }
 
-func stringWireLen(l uint32) uint32 {
+// wireLen returns l padded to a 32-bit boundary.
+func wireLen(l uint32) uint32 {
 	if r := l % 4; r != 0 {
 		l += 4 - r
 	}
 	return l
 }
 
-func (m *Message) ReadObjectId() (oid ObjectId, err error) {
-	err = binary.Read(m.p, HostOrder, &oid)
-	return
+func (m *Message) ReadObjectId() (ObjectId, error) {
+	v, err := m.ReadUint()
+	return ObjectId(v), err
 }
 
 func (m *Message) WriteObjectId(oid ObjectId) error {
-	return binary.Write(m.p, HostOrder, oid)
+	return m.WriteUint(uint32(oid))
+}
+
+// ReadObject reads an object argument and returns the object with that
+// id registered on the connection the message came from. A null id,
+// which is an error unless nullable, and an object this end has
+// destroyed yield nil.
+func (m *Message) ReadObject(nullable bool) (Object, error) {
+	id, err := m.ReadObjectId()
+	if err != nil {
+		return nil, err
+	}
+	if id == 0 {
+		if !nullable {
+			return nil, fmt.Errorf("null object")
+		}
+		return nil, nil
+	}
+	if m.c == nil {
+		return nil, fmt.Errorf("object %d: message was not received on a connection", id)
+	}
+	m.c.mu.Lock()
+	obj, ok := m.c.objects[id]
+	m.c.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown object %d", id)
+	}
+	if _, ok := obj.(zombie); ok {
+		return nil, nil
+	}
+	return obj, nil
+}
+
+// ReadNewId reads the id of an object created by the message's sender.
+// For received messages the id must lie in the sender's range and must
+// not be in use.
+func (m *Message) ReadNewId() (ObjectId, error) {
+	id, err := m.ReadObjectId()
+	if err != nil || m.c == nil {
+		return id, err
+	}
+	return id, m.c.checkNewId(id)
+}
+
+func (m *Message) WriteNewId(id ObjectId) error {
+	return m.WriteObjectId(id)
 }
 
 func (m *Message) ReadArray() (a []byte, err error) {
-	panic(fmt.Errorf("NOT IMPLEMENTED"))
-	//	var l uint32
-	//	if err = binary.Read(m.p, HostOrder, &l); err != nil {
-	//		return err
-	//	}
-	//	a = make([]byte, l)
-	//	_, err = m.p.Read(a)
-	//	return
-}
-
-func (m *Message) WriteArray(a []byte) error {
-	panic(fmt.Errorf("NOT IMPLEMENTED"))
-	// l := len(a)
-	// if err := binary.Write(c, HostOrder, uint32(l)); err != nil {
-	// 	return err
-	// }
-	// d := make([]byte, l)
-	// copy(d, a)
-	// _, err := c.Write(d)
-	// return err
+	l, err := m.ReadUint()
+	if err != nil {
+		return
+	}
+	if l > uint32(len(m.p)-m.off) {
+		err = fmt.Errorf("array of %d bytes exceeds message payload (%d bytes left)", l, len(m.p)-m.off)
+		return
+	}
+	b, err := m.next(int(wireLen(l)))
+	if err != nil {
+		return
+	}
+	a = make([]byte, l)
+	copy(a, b)
+	return
+}
+
+func (m *Message) WriteArray(a []byte) (err error) {
+	l := uint32(len(a))
+	m.WriteUint(l)
+	m.p = append(m.p, a...)
+	m.p = append(m.p, make([]byte, wireLen(l)-l)...)
+	return nil
+}
+
+// ReadUint32Array reads an array argument holding 32-bit unsigned values,
+// such as wl_keyboard.enter keys.
+func (m *Message) ReadUint32Array() (v []uint32, err error) {
+	a, err := m.ReadArray()
+	if err != nil {
+		return
+	}
+	if len(a)%4 != 0 {
+		err = fmt.Errorf("array of %d bytes is not a multiple of 4", len(a))
+		return
+	}
+	v = make([]uint32, len(a)/4)
+	for i := range v {
+		v[i] = HostOrder.Uint32(a[i*4:])
+	}
+	return
+}
+
+func (m *Message) WriteUint32Array(v []uint32) error {
+	a := make([]byte, len(v)*4)
+	for i := range v {
+		HostOrder.PutUint32(a[i*4:], v[i])
+	}
+	return m.WriteArray(a)
+}
+
+// ReadInt32Array reads an array argument holding 32-bit signed values,
+// such as xdg_toplevel.configure states.
+func (m *Message) ReadInt32Array() (v []int32, err error) {
+	u, err := m.ReadUint32Array()
+	if err != nil {
+		return
+	}
+	v = make([]int32, len(u))
+	for i := range u {
+		v[i] = int32(u[i])
+	}
+	return
+}
+
+func (m *Message) WriteInt32Array(v []int32) error {
+	u := make([]uint32, len(v))
+	for i := range v {
+		u[i] = uint32(v[i])
+	}
+	return m.WriteUint32Array(u)
 }
 
 func (m *Message) ReadFd() (fd uintptr, err error) {
@@ -149,9 +419,26 @@ func (m *Message) ReadFd() (fd uintptr, err error) {
 	}
 	fd = uintptr(m.fds[m.fdi])
 	m.fdi++
+	if m.fdi > m.nread {
+		m.nread = m.fdi
+	}
 	return
 }
 
+// owns reports whether m owns fds[k].
+func (m *Message) owns(k int) bool {
+	return m.nread <= k && k < m.nown
+}
+
+// closeOwnedFds closes the fds m owns, those received or duplicated that
+// nobody read, and leaves m owning none.
+func (m *Message) closeOwnedFds() {
+	for k := m.nread; k < m.nown; k++ {
+		syscall.Close(m.fds[k])
+	}
+	m.nown = 0
+}
+
 func (m *Message) WriteFd(fd uintptr) error {
 	m.fds = append(m.fds, int(fd))
 	return nil
@@ -0,0 +1,300 @@
+package proto
+
+import (
+	"bytes"
+	"reflect"
+	"syscall"
+	"testing"
+)
+
+func TestMessageArray(t *testing.T) {
+	tests := []struct {
+		a       []byte
+		wireLen int
+	}{
+		{nil, 4},
+		{[]byte{1}, 8},
+		{[]byte{1, 2, 3}, 8},
+		{[]byte{1, 2, 3, 4}, 8},
+		{[]byte{1, 2, 3, 4, 5}, 12},
+	}
+	for _, tt := range tests {
+		m := NewMessage(1, 0)
+		m.WriteArray(tt.a)
+		m.WriteUint(0xdeadbeef)
+		if got := len(m.p) - 4; got != tt.wireLen {
+			t.Errorf("WriteArray(%v) wrote %d bytes, want %d", tt.a, got, tt.wireLen)
+		}
+		if pad := m.p[4+len(tt.a) : tt.wireLen]; !bytes.Equal(pad, make([]byte, len(pad))) {
+			t.Errorf("WriteArray(%v) padding is %v, want zeros", tt.a, pad)
+		}
+
+		a, err := m.ReadArray()
+		if err != nil {
+			t.Errorf("ReadArray() of %v: %s", tt.a, err)
+			continue
+		}
+		if !bytes.Equal(a, tt.a) {
+			t.Errorf("ReadArray() = %v, want %v", a, tt.a)
+		}
+		// the padding is skipped
+		if v, err := m.ReadUint(); err != nil || v != 0xdeadbeef {
+			t.Errorf("ReadUint() after array %v = %#x, %v", tt.a, v, err)
+		}
+	}
+}
+
+func TestMessageArrayBounds(t *testing.T) {
+	tests := []struct {
+		name string
+		p    []byte
+	}{
+		{"no length", []byte{1, 0}},
+		{"length beyond payload", []byte{5, 0, 0, 0, 1, 2, 3, 4}},
+		{"padding beyond payload", []byte{3, 0, 0, 0, 1, 2, 3}},
+		{"huge length", []byte{0xff, 0xff, 0xff, 0xff, 1, 2, 3, 4}},
+	}
+	for _, tt := range tests {
+		m := &Message{p: tt.p}
+		if a, err := m.ReadArray(); err == nil {
+			t.Errorf("%s: ReadArray() = %v, want error", tt.name, a)
+		}
+	}
+}
+
+func TestMessageTypedArrays(t *testing.T) {
+	u := []uint32{0, 1, 0xffffffff}
+	i := []int32{0, -1, 2147483647, -2147483648}
+
+	m := NewMessage(1, 0)
+	m.WriteUint32Array(u)
+	m.WriteInt32Array(i)
+	m.WriteArray([]byte{1, 2, 3})
+
+	if got, err := m.ReadUint32Array(); err != nil || !reflect.DeepEqual(got, u) {
+		t.Errorf("ReadUint32Array() = %v, %v, want %v", got, err, u)
+	}
+	if got, err := m.ReadInt32Array(); err != nil || !reflect.DeepEqual(got, i) {
+		t.Errorf("ReadInt32Array() = %v, %v, want %v", got, err, i)
+	}
+	if got, err := m.ReadUint32Array(); err == nil {
+		t.Errorf("ReadUint32Array() of 3 bytes = %v, want error", got)
+	}
+}
+
+// receivedMessage returns a retained request to a testObject, received
+// with the two fds of a fresh pipe, which the message owns.
+func receivedMessage(t *testing.T) *Message {
+	client, server := testConns(t)
+	server.AddObject(3, new(testObject))
+
+	var p [2]int
+	if err := syscall.Pipe2(p[:], syscall.O_CLOEXEC); err != nil {
+		t.Fatal(err)
+	}
+	m := NewMessage(3, 0)
+	m.WriteFd(uintptr(p[0]))
+	m.WriteFd(uintptr(p[1]))
+	err := client.WriteMessage(m)
+	syscall.Close(p[0])
+	syscall.Close(p[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if m, err = server.ReadMessage(); err != nil {
+		t.Fatal(err)
+	}
+	return m.Retain()
+}
+
+// readFd reads the next fd of m.
+func readFd(t *testing.T, m *Message) int {
+	fd, err := m.ReadFd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return int(fd)
+}
+
+// newFd returns a fresh fd.
+func newFd(t *testing.T) int {
+	fd, err := dupCloexec(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fd
+}
+
+func TestMessageFdOwnership(t *testing.T) {
+	tests := []struct {
+		name string
+		// op works on a received message that owns two fds, and returns
+		// the fds the caller owns afterwards
+		op func(t *testing.T, m *Message) []int
+	}{
+		{"release", func(t *testing.T, m *Message) []int {
+			return nil
+		}},
+		{"read", func(t *testing.T, m *Message) []int {
+			return []int{readFd(t, m)}
+		}},
+		{"read and rewind", func(t *testing.T, m *Message) []int {
+			fd := readFd(t, m)
+			m.Rewind()
+			return []int{fd}
+		}},
+		{"read again after rewind", func(t *testing.T, m *Message) []int {
+			fd := readFd(t, m)
+			m.Rewind()
+			if again := readFd(t, m); again != fd {
+				t.Errorf("fd %d read again as %d", fd, again)
+			}
+			return []int{fd, readFd(t, m)}
+		}},
+		{"retain", func(t *testing.T, m *Message) []int {
+			r := m.Retain()
+			fd := readFd(t, r)
+			r.Release()
+			return []int{fd}
+		}},
+		{"clone", func(t *testing.T, m *Message) []int {
+			c, err := m.Clone()
+			if err != nil {
+				t.Fatal(err)
+			}
+			fd := readFd(t, c)
+			c.Release()
+			return []int{fd}
+		}},
+		{"clone after read", func(t *testing.T, m *Message) []int {
+			fd := readFd(t, m)
+			m.Rewind()
+			if _, err := m.Clone(); err == nil {
+				t.Error("Clone after reading an fd succeeded")
+			}
+			return []int{fd}
+		}},
+		{"set unread fd", func(t *testing.T, m *Message) []int {
+			if err := m.Set(1, newFd(t)); err != nil {
+				t.Fatal(err)
+			}
+			return nil
+		}},
+		{"set read fd", func(t *testing.T, m *Message) []int {
+			fd, v := readFd(t, m), newFd(t)
+			if err := m.Set(0, v); err != nil {
+				t.Fatal(err)
+			}
+			if got := readFd(t, m); got != v {
+				t.Errorf("fd argument is %d after Set, want %d", got, v)
+			}
+			return []int{fd, v}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := receivedMessage(t)
+			before := numFds(t) - len(m.Fds())
+
+			kept := tt.op(t, m)
+			m.Release()
+			for _, fd := range kept {
+				if !fdOpen(fd) {
+					t.Errorf("fd %d owned by the caller was closed", fd)
+				}
+				syscall.Close(fd)
+			}
+			if after := numFds(t); after != before {
+				t.Errorf("%d fds open before, %d after", before, after)
+			}
+		})
+	}
+}
+
+// rewinder reads the first fd of the messages it handles and rewinds
+// them, as interceptors inspecting messages before forwarding them do.
+type rewinder struct {
+	fd uintptr
+}
+
+func (o *rewinder) Handle(m *Message) (err error) {
+	o.fd, err = m.ReadFd()
+	m.Rewind()
+	return
+}
+
+func TestDispatchMessageRewound(t *testing.T) {
+	m := receivedMessage(t)
+	o := new(rewinder)
+	m.c.AddObject(3, o)
+
+	if err := m.c.DispatchMessage(m); err != nil {
+		t.Fatal(err)
+	}
+	if !fdOpen(int(o.fd)) {
+		t.Fatal("fd read by the handler closed by DispatchMessage")
+	}
+	syscall.Close(int(o.fd))
+	if fd := m.Fds()[1]; fdOpen(fd) {
+		t.Errorf("fd %d nobody read left open", fd)
+	}
+}
+
+func TestMessageOutgoingFds(t *testing.T) {
+	client, _ := testConns(t)
+	r, w := testPipe(t)
+	v := newFd(t)
+	defer syscall.Close(v)
+	sig := &testInterface.Requests[0]
+
+	tests := []struct {
+		name string
+		op   func(m *Message) error
+	}{
+		{"release", func(m *Message) error {
+			m.Release()
+			return nil
+		}},
+		{"write", client.WriteMessage},
+		{"set", func(m *Message) error {
+			return m.Set(0, v)
+		}},
+		{"clone", func(m *Message) error {
+			c, err := m.Clone()
+			if err == nil {
+				c.Release()
+			}
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		// attached with WriteFd and with Encode, the fds stay the caller's
+		for _, encode := range []bool{false, true} {
+			m := NewMessage(3, 0)
+			m.sig = sig
+			if encode {
+				if err := m.Encode([]Arg{{&sig.Args[0], r}, {&sig.Args[1], w}}); err != nil {
+					t.Fatal(err)
+				}
+			} else {
+				m.WriteFd(uintptr(r))
+				m.WriteFd(uintptr(w))
+			}
+			if got := m.Fds(); !reflect.DeepEqual(got, []int{r, w}) {
+				t.Fatalf("%s: fds %v attached, want %v", tt.name, got, []int{r, w})
+			}
+			if err := tt.op(m); err != nil {
+				t.Fatalf("%s: %s", tt.name, err)
+			}
+			if !fdOpen(r) || !fdOpen(w) {
+				t.Fatalf("%s (encoded %v): the caller's fds were closed", tt.name, encode)
+			}
+		}
+	}
+	if !fdOpen(v) {
+		t.Error("fd passed to Set on an outgoing message was closed")
+	}
+}
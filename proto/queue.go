@@ -0,0 +1,248 @@
+package proto
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"time"
+)
+
+// EventQueue holds received messages until they are dispatched. Every
+// object belongs to a queue, the connection's default queue unless
+// assigned otherwise with Conn.SetQueue, and a goroutine dispatching a
+// queue handles only the messages for that queue's objects.
+//
+// Any number of goroutines may dispatch queues of the same Conn at once:
+// one of them reads from the socket and routes what arrives to the
+// queues, the others wait for it.
+type EventQueue struct {
+	c      *Conn
+	events []*Message
+}
+
+func (c *Conn) NewEventQueue() *EventQueue {
+	return &EventQueue{c: c}
+}
+
+// DefaultQueue returns the queue objects belong to unless assigned to
+// another one.
+func (c *Conn) DefaultQueue() *EventQueue {
+	return c.defq
+}
+
+// SetQueue assigns the object with the given id to q. Messages already
+// queued for the object stay where they are, so assign the queue before
+// sending the request that causes the object to receive events.
+func (c *Conn) SetQueue(id ObjectId, q *EventQueue) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if q == nil || q == c.defq {
+		delete(c.queues, id)
+	} else {
+		c.queues[id] = q
+	}
+}
+
+// queue returns the queue of the object with the given id. c.mu must be
+// held.
+func (c *Conn) queue(id ObjectId) *EventQueue {
+	if q, ok := c.queues[id]; ok {
+		return q
+	}
+	return c.defq
+}
+
+// Dispatch flushes outgoing messages, waits until at least one message
+// is queued on q, reading from the socket if no other goroutine is, and
+// then dispatches all of q's messages. It returns the number of messages
+// dispatched. If ctx is done before anything arrives, Dispatch returns
+// an error wrapping ctx.Err(); partially read data stays buffered.
+func (q *EventQueue) Dispatch(ctx context.Context) (int, error) {
+	c := q.c
+	if err := c.FlushContext(ctx); err != nil {
+		return 0, err
+	}
+
+	c.mu.Lock()
+	for len(q.events) == 0 {
+		if ctx.Err() != nil {
+			c.mu.Unlock()
+			return 0, interrupted("dispatch", ctx)
+		}
+		if c.rerr != nil {
+			c.mu.Unlock()
+			return 0, c.rerr
+		}
+		if c.reading {
+			stop := context.AfterFunc(ctx, func() {
+				c.mu.Lock()
+				c.rcond.Broadcast()
+				c.mu.Unlock()
+			})
+			c.rcond.Wait()
+			stop()
+			continue
+		}
+
+		c.reading = true
+		c.mu.Unlock()
+		err := c.readEvents(ctx)
+		c.mu.Lock()
+		c.reading = false
+		c.rcond.Broadcast()
+		if errors.Is(err, os.ErrDeadlineExceeded) {
+			c.mu.Unlock()
+			return 0, interrupted("dispatch", ctx)
+		}
+		if err != nil && c.rerr == nil {
+			c.rerr = err
+		}
+	}
+	c.mu.Unlock()
+
+	return q.DispatchPending()
+}
+
+// Roundtrip sends a wl_display.sync request and dispatches q until the
+// server answers it, so that all requests sent before have been handled
+// by the server and all resulting events assigned to q dispatched.
+func (q *EventQueue) Roundtrip(ctx context.Context) error {
+	c := q.c
+	id := c.NextId()
+	cb := &syncCallback{c: c, id: id}
+	c.AddObject(id, cb)
+	c.SetQueue(id, q)
+
+	m := NewMessage(DisplayId, displaySync)
+	m.WriteObjectId(id)
+	if err := c.WriteMessage(m); err != nil {
+		return err
+	}
+
+	for !cb.done {
+		if _, err := q.Dispatch(ctx); err != nil {
+			// done may still arrive; the zombie drops it
+			c.DestroyObject(id)
+			return err
+		}
+	}
+	return nil
+}
+
+// DisplayId is the id of the wl_display object, which exists from the
+// start on both ends of a connection. Clients never allocate it.
+const DisplayId ObjectId = 1
+
+const displaySync uint16 = 0
+
+// syncCallback is the wl_callback of a roundtrip. Like every
+// wl_callback it is destroyed by its done event.
+type syncCallback struct {
+	c    *Conn
+	id   ObjectId
+	done bool
+}
+
+func (cb *syncCallback) Handle(m *Message) error {
+	cb.done = true
+	return cb.c.DestroyObject(cb.id)
+}
+
+// DispatchPending dispatches messages already queued on q without
+// reading from the socket.
+func (q *EventQueue) DispatchPending() (n int, err error) {
+	c := q.c
+	for {
+		c.mu.Lock()
+		if perr, ok := c.rerr.(*ProtocolError); ok {
+			c.mu.Unlock()
+			return n, perr
+		}
+		if len(q.events) == 0 {
+			c.mu.Unlock()
+			return
+		}
+		m := q.events[0]
+		q.events[0] = nil
+		q.events = q.events[1:]
+		c.mu.Unlock()
+
+		err = c.DispatchMessage(m)
+		m.Release()
+		if err != nil {
+			return
+		}
+		n++
+	}
+}
+
+// readEvents reads at least one message, and then every message that is
+// already buffered and addressed to a registered object, and routes them
+// to their queues. The read is
+// interrupted with os.ErrDeadlineExceeded once ctx is done.
+//
+// On clients, wl_display.error is not queued: it ends reading, so that
+// every queue's Dispatch returns the *ProtocolError. wl_display.delete_id
+// goes to the queue of the deleted object, behind the events the object
+// received before it.
+func (c *Conn) readEvents(ctx context.Context) error {
+	deadline, _ := ctx.Deadline()
+	c.c.SetReadDeadline(deadline)
+	stopped := make(chan struct{})
+	stop := context.AfterFunc(ctx, func() {
+		c.c.SetReadDeadline(aLongTimeAgo)
+		close(stopped)
+	})
+	defer func() {
+		if !stop() {
+			<-stopped
+		}
+		c.c.SetReadDeadline(time.Time{})
+	}()
+
+	for {
+		m, err := c.ReadMessage()
+		if err != nil {
+			return err
+		}
+		target := m.Object()
+		if !c.server && target == DisplayId {
+			switch m.Opcode() {
+			case displayError:
+				return c.protocolError(m)
+			case displayDeleteId:
+				if id, err := m.ReadUint(); err == nil {
+					target = ObjectId(id)
+				}
+				m.Rewind()
+			}
+		}
+		r := m.Retain()
+
+		c.mu.Lock()
+		if c.closed {
+			c.mu.Unlock()
+			r.Release()
+			return net.ErrClosed
+		}
+		q := c.queue(target)
+		q.events = append(q.events, r)
+		c.mu.Unlock()
+
+		// objects are registered when the message creating them is
+		// dispatched, and only then can messages to them claim fds
+		next, ok := c.buffered()
+		if !ok || !c.registered(next) {
+			return nil
+		}
+	}
+}
+
+// release drops all messages queued on q.
+func (q *EventQueue) release() {
+	for _, m := range q.events {
+		m.Release()
+	}
+	q.events = nil
+}
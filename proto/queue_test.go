@@ -0,0 +1,446 @@
+package proto
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// serveSync answers the wl_display.sync requests the client end sends
+// to server, until the connection is closed.
+func serveSync(server *Conn) {
+	go func() {
+		for {
+			m, err := server.ReadMessage()
+			if err != nil {
+				return
+			}
+			if m.Object() != DisplayId || m.Opcode() != displaySync {
+				continue
+			}
+			id, err := m.ReadObjectId()
+			if err != nil {
+				return
+			}
+			done := NewMessage(id, 0)
+			done.WriteUint(0)
+			if server.WriteMessage(done) != nil || server.DestroyObject(id) != nil || server.Flush() != nil {
+				return
+			}
+		}
+	}()
+}
+
+// sendEvents sends an event without arguments to each of the objects.
+func sendEvents(t *testing.T, server *Conn, objects ...ObjectId) {
+	t.Helper()
+	for _, id := range objects {
+		if err := server.WriteMessage(NewMessage(id, 1)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := server.Flush(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testContext(t *testing.T) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+// fillSocket writes junk to c's socket until it accepts no more, so that
+// the next flush blocks.
+func fillSocket(t *testing.T, c *Conn) {
+	junk := make([]byte, 4096)
+	for {
+		c.c.SetWriteDeadline(time.Now().Add(10 * time.Millisecond))
+		if _, err := c.c.Write(junk); errors.Is(err, os.ErrDeadlineExceeded) {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+	}
+	c.c.SetWriteDeadline(time.Time{})
+}
+
+func TestContextEnds(t *testing.T) {
+	contexts := []struct {
+		name string
+		ctx  func() (context.Context, context.CancelFunc)
+		want error
+	}{
+		{"cancelled", func() (context.Context, context.CancelFunc) {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			return ctx, cancel
+		}, context.Canceled},
+		{"cancelled while waiting", func() (context.Context, context.CancelFunc) {
+			ctx, cancel := context.WithCancel(context.Background())
+			time.AfterFunc(20*time.Millisecond, cancel)
+			return ctx, cancel
+		}, context.Canceled},
+		{"deadline", func() (context.Context, context.CancelFunc) {
+			return context.WithTimeout(context.Background(), 20*time.Millisecond)
+		}, context.DeadlineExceeded},
+	}
+	ops := []struct {
+		name string
+		// usable is set if the connection is expected to work afterwards
+		usable bool
+		op     func(t *testing.T, client *Conn, ctx context.Context) error
+	}{
+		{"Dispatch", true, func(t *testing.T, client *Conn, ctx context.Context) error {
+			_, err := client.Dispatch(ctx)
+			return err
+		}},
+		{"Roundtrip", true, func(t *testing.T, client *Conn, ctx context.Context) error {
+			return client.Roundtrip(ctx)
+		}},
+		{"FlushContext", false, func(t *testing.T, client *Conn, ctx context.Context) error {
+			fillSocket(t, client)
+			if err := client.WriteMessage(NewMessage(3, 1)); err != nil {
+				t.Fatal(err)
+			}
+			return client.FlushContext(ctx)
+		}},
+	}
+	for _, op := range ops {
+		for _, tt := range contexts {
+			t.Run(op.name+"/"+tt.name, func(t *testing.T) {
+				client, server := testConns(t)
+				goroutines := runtime.NumGoroutine()
+
+				ctx, cancel := tt.ctx()
+				defer cancel()
+				err := op.op(t, client, ctx)
+				if !errors.Is(err, tt.want) || !strings.Contains(fmt.Sprint(err), "interrupted") {
+					t.Errorf("%s = %v, want an interrupted error wrapping %v", op.name, err, tt.want)
+				}
+
+				// context.AfterFunc goroutines may take a moment to exit
+				for i := 0; runtime.NumGoroutine() > goroutines; i++ {
+					if i == 100 {
+						t.Fatalf("%d goroutines before, %d after", goroutines, runtime.NumGoroutine())
+					}
+					time.Sleep(time.Millisecond)
+				}
+
+				if op.usable {
+					serveSync(server)
+					if err := client.Roundtrip(testContext(t)); err != nil {
+						t.Errorf("Roundtrip after %s: %s", op.name, err)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestQueueRouting(t *testing.T) {
+	tests := []struct {
+		name     string
+		queued   []ObjectId // objects assigned to q
+		unqueued []ObjectId // objects then assigned back to the default queue
+		events   []ObjectId
+	}{
+		{"default queue only", nil, nil, []ObjectId{3, 4, 3}},
+		{"q only", []ObjectId{3, 4}, nil, []ObjectId{4, 3, 4}},
+		{"interleaved", []ObjectId{4}, nil, []ObjectId{3, 4, 5, 4, 3}},
+		{"assigned back", []ObjectId{4, 5}, []ObjectId{5}, []ObjectId{5, 4, 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := testConns(t)
+			serveSync(server)
+			ctx := testContext(t)
+
+			objects := map[ObjectId]*testObject{3: {}, 4: {}, 5: {}}
+			for id, o := range objects {
+				client.AddObject(id, o)
+			}
+			q := client.NewEventQueue()
+			onQ := make(map[ObjectId]bool)
+			for _, id := range tt.queued {
+				client.SetQueue(id, q)
+				onQ[id] = true
+			}
+			for _, id := range tt.unqueued {
+				client.SetQueue(id, nil)
+				delete(onQ, id)
+			}
+
+			// the roundtrip reads all events, but dispatches only q's
+			sendEvents(t, server, tt.events...)
+			if err := q.Roundtrip(ctx); err != nil {
+				t.Fatal(err)
+			}
+			want := make(map[ObjectId]int)
+			for _, id := range tt.events {
+				if onQ[id] {
+					want[id]++
+				}
+			}
+			if got := handledCounts(objects); !reflect.DeepEqual(got, want) {
+				t.Errorf("after q.Roundtrip handled %v, want %v", got, want)
+			}
+
+			if _, err := client.DispatchPending(); err != nil {
+				t.Fatal(err)
+			}
+			for _, id := range tt.events {
+				if !onQ[id] {
+					want[id]++
+				}
+			}
+			if got := handledCounts(objects); !reflect.DeepEqual(got, want) {
+				t.Errorf("after DispatchPending handled %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func handledCounts(objects map[ObjectId]*testObject) map[ObjectId]int {
+	counts := make(map[ObjectId]int)
+	for id, o := range objects {
+		if len(o.handled) != 0 {
+			counts[id] = len(o.handled)
+		}
+	}
+	return counts
+}
+
+func TestConcurrentDispatch(t *testing.T) {
+	client, server := testConns(t)
+	serveSync(server)
+	ctx := testContext(t)
+
+	const n = 8
+	objects := make([]*testObject, n)
+	queues := make([]*EventQueue, n)
+	for i := range objects {
+		id := ObjectId(3 + i)
+		objects[i], queues[i] = new(testObject), client.NewEventQueue()
+		client.AddObject(id, objects[i])
+		client.SetQueue(id, queues[i])
+		sendEvents(t, server, id, id)
+	}
+
+	var wg sync.WaitGroup
+	for i := range queues {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for len(objects[i].handled) < 2 {
+				if _, err := queues[i].Dispatch(ctx); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestCloseConcurrent(t *testing.T) {
+	client, _ := testConns(t)
+
+	var wg sync.WaitGroup
+	errs := make([]error, 4)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = client.Close()
+		}(i)
+	}
+	wg.Wait()
+	for i := range errs {
+		if errs[i] != errs[0] {
+			t.Errorf("Close returned %v, then %v", errs[0], errs[i])
+		}
+	}
+}
+
+// TestCloseWhileDispatching closes the connection under a goroutine
+// reading fds, as PostError does while the server dispatches.
+func TestCloseWhileDispatching(t *testing.T) {
+	client, server := testConns(t)
+	ctx := testContext(t)
+	client.AddObject(3, new(testObject))
+	r, w := testPipe(t)
+	before := numFds(t)
+
+	sent := make(chan struct{})
+	go func() {
+		defer close(sent)
+		for {
+			m := NewMessage(3, 0)
+			m.WriteFd(uintptr(r))
+			m.WriteFd(uintptr(w))
+			if server.WriteMessage(m) != nil || server.Flush() != nil {
+				return
+			}
+		}
+	}()
+	dispatched := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		for i := 0; ; i++ {
+			if _, err := client.Dispatch(ctx); err != nil {
+				done <- err
+				return
+			}
+			if i == 0 {
+				close(dispatched)
+			}
+		}
+	}()
+
+	<-dispatched
+	client.Close()
+	if err := <-done; ctx.Err() != nil {
+		t.Fatalf("Dispatch did not end with Close: %v", err)
+	}
+	<-sent
+	server.Close()
+	if after := numFds(t); after != before-2 {
+		t.Errorf("%d fds open before, %d after closing both ends, want %d", before, after, before-2)
+	}
+}
+
+type countingTracer struct {
+	mu sync.Mutex
+	n  int
+}
+
+func (t *countingTracer) TraceMessage(dir Direction, tm time.Time, m *Message) {
+	t.mu.Lock()
+	t.n++
+	t.mu.Unlock()
+}
+
+func TestSetTracerConcurrent(t *testing.T) {
+	client, _ := testConns(t)
+	tr := new(countingTracer)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			if i%2 == 0 {
+				client.SetTracer(tr)
+			} else {
+				client.SetTracer(nil)
+			}
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		if err := client.WriteMessage(NewMessage(3, 1)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	<-done
+
+	client.SetTracer(tr)
+	tr.n = 0
+	client.WriteMessage(NewMessage(3, 1))
+	if tr.n != 1 {
+		t.Errorf("tracer saw %d messages, want 1", tr.n)
+	}
+}
+
+func TestProtocolErrorOnEveryQueue(t *testing.T) {
+	tests := []struct {
+		name  string
+		queue func(c *Conn) *EventQueue // the queue doing the roundtrip
+	}{
+		{"default queue", (*Conn).DefaultQueue},
+		{"other queue", (*Conn).NewEventQueue},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := testConns(t)
+			ctx := testContext(t)
+			client.AddObject(3, new(testObject))
+
+			m := NewMessage(DisplayId, displayError)
+			m.WriteObjectId(3)
+			m.WriteUint(7)
+			m.WriteString("broken")
+			if err := server.WriteMessage(m); err != nil {
+				t.Fatal(err)
+			}
+			if err := server.Flush(); err != nil {
+				t.Fatal(err)
+			}
+
+			err := tt.queue(client).Roundtrip(ctx)
+			perr, ok := err.(*ProtocolError)
+			if !ok {
+				t.Fatalf("Roundtrip() = %v, want *ProtocolError", err)
+			}
+			want := ProtocolError{ObjectId: 3, Interface: "test", Code: 7, Message: "broken"}
+			if *perr != want {
+				t.Errorf("Roundtrip() = %+v, want %+v", *perr, want)
+			}
+			for _, q := range []*EventQueue{client.DefaultQueue(), client.NewEventQueue()} {
+				if _, err := q.Dispatch(ctx); err != perr {
+					t.Errorf("Dispatch() after the error = %v, want %v", err, perr)
+				}
+			}
+		})
+	}
+}
+
+func TestDeleteIdFollowsQueue(t *testing.T) {
+	client, server := testConns(t)
+	serveSync(server)
+	ctx := testContext(t)
+
+	q := client.NewEventQueue()
+	id := client.NextId()
+	o := new(testObject)
+	client.AddObject(id, o)
+	client.SetQueue(id, q)
+	sendEvents(t, server, id)
+	if err := client.DestroyObject(id); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.DestroyObject(id); err != nil {
+		t.Fatal(err)
+	}
+
+	// the default queue reads delete_id but leaves it to q, where the
+	// zombie still has an event to drop
+	if err := client.Roundtrip(ctx); err != nil {
+		t.Fatal(err)
+	}
+	client.mu.Lock()
+	_, ok := client.objects[id]
+	client.mu.Unlock()
+	if !ok {
+		t.Fatalf("object %d deleted before its queue was dispatched", id)
+	}
+
+	if _, err := q.DispatchPending(); err != nil {
+		t.Fatal(err)
+	}
+	client.mu.Lock()
+	_, ok = client.objects[id]
+	client.mu.Unlock()
+	if ok {
+		t.Errorf("object %d still registered after its queue was dispatched", id)
+	}
+	if len(o.handled) != 0 {
+		t.Errorf("destroyed object handled opcodes %v", o.handled)
+	}
+}
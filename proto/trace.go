@@ -0,0 +1,87 @@
+package proto
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Direction int
+
+const (
+	Received Direction = iota
+	Sent
+)
+
+func (d Direction) String() string {
+	if d == Sent {
+		return "sent"
+	}
+	return "received"
+}
+
+// Tracer is called by Conn for every message it sends or receives. For
+// received messages, m.Fds() holds the fds the message claimed from the
+// connection's fd queue. m must not be retained or read from.
+type Tracer interface {
+	TraceMessage(dir Direction, t time.Time, m *Message)
+}
+
+// SetTracer installs t on c, replacing any tracer set before, including
+// the one installed from WAYLAND_DEBUG. A nil t disables tracing.
+func (c *Conn) SetTracer(t Tracer) {
+	if t == nil {
+		c.tracer.Store(nil)
+		return
+	}
+	c.tracer.Store(&t)
+}
+
+func (c *Conn) trace(dir Direction, m *Message) {
+	if t := c.tracer.Load(); t != nil {
+		(*t).TraceMessage(dir, time.Now(), m)
+	}
+}
+
+// DebugTracer writes messages to w in the format used by libwayland
+// when WAYLAND_DEBUG is set.
+type DebugTracer struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewDebugTracer(w io.Writer) *DebugTracer {
+	return &DebugTracer{w: w}
+}
+
+// debugTracerFromEnv returns a DebugTracer writing to stderr if
+// WAYLAND_DEBUG asks for tracing of the given side ("client" or
+// "server"), as libwayland does.
+func debugTracerFromEnv(side string) Tracer {
+	debug := os.Getenv("WAYLAND_DEBUG")
+	if strings.Contains(debug, side) || strings.Contains(debug, "1") {
+		return NewDebugTracer(os.Stderr)
+	}
+	return nil
+}
+
+func (t *DebugTracer) TraceMessage(dir Direction, tm time.Time, m *Message) {
+	b := new(bytes.Buffer)
+
+	// libwayland prints a 32-bit microsecond clock in milliseconds
+	usec := uint32(tm.UnixNano() / 1000)
+	fmt.Fprintf(b, "[%10.3f] ", float64(usec)/1000)
+	if dir == Sent {
+		b.WriteString(" -> ")
+	}
+	m.format(b)
+	b.WriteString("\n")
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.w.Write(b.Bytes())
+}
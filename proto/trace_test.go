@@ -0,0 +1,161 @@
+package proto
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// argsInterface has a request carrying one argument of each type but fd,
+// and one carrying a uint.
+var argsInterface = &Interface{
+	Name:    "test_args",
+	Version: 1,
+	Requests: []Signature{
+		{Name: "all", Since: 1, Args: []ArgDesc{
+			{Name: "i", Type: ArgInt},
+			{Name: "u", Type: ArgUint},
+			{Name: "f", Type: ArgFixed},
+			{Name: "s", Type: ArgString},
+			{Name: "ns", Type: ArgString, Nullable: true},
+			{Name: "o", Type: ArgObject, Interface: "test"},
+			{Name: "no", Type: ArgObject, Nullable: true},
+			{Name: "n", Type: ArgNewId, Interface: "test"},
+			{Name: "a", Type: ArgArray},
+		}},
+		{Name: "uint", Since: 1, Args: []ArgDesc{{Name: "u", Type: ArgUint}}},
+	},
+}
+
+func TestDebugTracer(t *testing.T) {
+	all := NewMessage(3, 0)
+	all.WriteInt(-2)
+	all.WriteUint(7)
+	all.WriteFixedFloat(1.5)
+	all.WriteString("hi")
+	all.WriteUint(0)
+	all.WriteObjectId(3)
+	all.WriteObjectId(0)
+	all.WriteNewId(4)
+	all.WriteArray([]byte{1, 2, 3})
+	all.iface, all.sig = argsInterface, &argsInterface.Requests[0]
+
+	short := NewMessage(3, 1)
+	short.iface, short.sig = argsInterface, &argsInterface.Requests[1]
+
+	unknown := NewMessage(5, 2)
+	unknown.WriteUint(1)
+	unknown.WriteUint(2)
+	unknown.WriteFd(9)
+
+	fds := NewMessage(3, 0)
+	fds.WriteFd(8)
+	fds.WriteFd(9)
+	fds.iface, fds.sig = testInterface, &testInterface.Events[0]
+
+	// 1234567 microseconds
+	tm := time.Unix(0, 1234567000)
+	tests := []struct {
+		dir  Direction
+		m    *Message
+		want string
+	}{
+		{Received, all, `[  1234.567] test_args@3.all(-2, 7, 1.5, "hi", nil, test@3, nil, new id test@4, array[3])`},
+		{Sent, all, `[  1234.567]  -> test_args@3.all(-2, 7, 1.5, "hi", nil, test@3, nil, new id test@4, array[3])`},
+		{Received, fds, `[  1234.567] test@3.fds(fd 8, fd 9)`},
+		{Received, unknown, `[  1234.567] [unknown]@5.opcode 2(8 bytes, fd 9)`},
+		{Sent, short, `[  1234.567]  -> test_args@3.uint(undecodable: uint: argument u: message 3:1: payload too short)`},
+	}
+	for _, tt := range tests {
+		b := new(bytes.Buffer)
+		NewDebugTracer(b).TraceMessage(tt.dir, tm, tt.m)
+		if got := strings.TrimSuffix(b.String(), "\n"); got != tt.want {
+			t.Errorf("traced\n%s\nwant\n%s", got, tt.want)
+		}
+		// tracing leaves the message unread
+		if tt.m.off != 0 || tt.m.fdi != 0 {
+			t.Errorf("%s: tracing read the message", tt.want)
+		}
+	}
+}
+
+func TestTraceConn(t *testing.T) {
+	client, server := testConns(t)
+	client.AddObject(3, new(testObject))
+	server.AddObject(3, new(testObject))
+	sent, received := new(bytes.Buffer), new(bytes.Buffer)
+	client.SetTracer(NewDebugTracer(sent))
+	server.SetTracer(NewDebugTracer(received))
+	r, w := testPipe(t)
+
+	m := NewMessage(3, 0)
+	m.WriteFd(uintptr(r))
+	m.WriteFd(uintptr(w))
+	for _, m := range []*Message{m, NewMessage(3, 1), NewMessage(4, 1)} {
+		if err := client.WriteMessage(m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := client.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := server.ReadMessage(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		trace *bytes.Buffer
+		want  []string
+	}{
+		{"sent", sent, []string{
+			`^\[ *\d+\.\d{3}\]  -> test@3\.fds\(fd \d+, fd \d+\)$`,
+			`^\[ *\d+\.\d{3}\]  -> test@3\.none\(\)$`,
+			`^\[ *\d+\.\d{3}\]  -> \[unknown\]@4\.opcode 1\(0 bytes\)$`,
+		}},
+		// received fds are the ones claimed from the fd queue
+		{"received", received, []string{
+			`^\[ *\d+\.\d{3}\] test@3\.fds\(fd \d+, fd \d+\)$`,
+			`^\[ *\d+\.\d{3}\] test@3\.none\(\)$`,
+			`^\[ *\d+\.\d{3}\] \[unknown\]@4\.opcode 1\(0 bytes\)$`,
+		}},
+	}
+	for _, tt := range tests {
+		lines := strings.Split(strings.TrimSuffix(tt.trace.String(), "\n"), "\n")
+		if len(lines) != len(tt.want) {
+			t.Fatalf("%s: traced %d messages, want %d:\n%s", tt.name, len(lines), len(tt.want), tt.trace)
+		}
+		for i, re := range tt.want {
+			if !regexp.MustCompile(re).MatchString(lines[i]) {
+				t.Errorf("%s: traced %q, want match of %q", tt.name, lines[i], re)
+			}
+		}
+	}
+}
+
+func TestDebugTracerFromEnv(t *testing.T) {
+	tests := []struct {
+		debug        string
+		client, serv bool
+	}{
+		{"", false, false},
+		{"0", false, false},
+		{"1", true, true},
+		{"client", true, false},
+		{"server", false, true},
+		{"client,server", true, true},
+	}
+	for _, tt := range tests {
+		t.Setenv("WAYLAND_DEBUG", tt.debug)
+		if got := debugTracerFromEnv("client") != nil; got != tt.client {
+			t.Errorf("WAYLAND_DEBUG=%q: client tracing %v, want %v", tt.debug, got, tt.client)
+		}
+		if got := debugTracerFromEnv("server") != nil; got != tt.serv {
+			t.Errorf("WAYLAND_DEBUG=%q: server tracing %v, want %v", tt.debug, got, tt.serv)
+		}
+	}
+}
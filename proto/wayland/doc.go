@@ -0,0 +1,5 @@
+// Package wayland implements the core wayland protocol on top of
+// proto.Conn. It is generated from proto-generator/wayland.xml.
+package wayland
+
+//go:generate go run ../../proto-generator -pkg wayland -trim-prefix wl_ -o wayland.go ../../proto-generator/wayland.xml
@@ -0,0 +1,10618 @@
+// Code generated by proto-generator from wayland.xml. DO NOT EDIT.
+
+package wayland
+
+import (
+	"fmt"
+	"syscall"
+
+	"github.com/vasiliyl/playwand/proto"
+)
+
+type Client struct {
+	c *proto.Conn
+}
+
+func NewClient(c *proto.Conn) Client {
+	return Client{c}
+}
+
+type Server struct {
+	c *proto.Conn
+}
+
+func NewServer(c *proto.Conn) Server {
+	return Server{c}
+}
+
+// Display
+//
+// The core global object.  This is a special singleton object.  It
+// is used for internal Wayland protocol features.
+//
+
+// These errors are global and can be emitted in response to any
+// server request.
+type DisplayError uint32
+
+const (
+
+	// server couldn't find object
+	DISPLAY_ERROR_INVALID_OBJECT DisplayError = 0
+
+	// method doesn't exist on the specified interface
+	DISPLAY_ERROR_INVALID_METHOD DisplayError = 1
+
+	// server is out of memory
+	DISPLAY_ERROR_NO_MEMORY DisplayError = 2
+)
+
+func (e DisplayError) String() string {
+	switch e {
+	case DISPLAY_ERROR_INVALID_OBJECT:
+		return "invalid_object"
+	case DISPLAY_ERROR_INVALID_METHOD:
+		return "invalid_method"
+	case DISPLAY_ERROR_NO_MEMORY:
+		return "no_memory"
+	}
+	return fmt.Sprintf("DisplayError(%d)", uint32(e))
+}
+
+// Versions that introduced each request and event.
+const (
+	DISPLAY_SYNC_SINCE_VERSION         = 1
+	DISPLAY_GET_REGISTRY_SINCE_VERSION = 1
+	DISPLAY_ERROR_SINCE_VERSION        = 1
+	DISPLAY_DELETE_ID_SINCE_VERSION    = 1
+)
+
+var DisplayInterface = &proto.Interface{
+	Name:    "wl_display",
+	Version: 1,
+	Requests: []proto.Signature{
+		{Name: "sync", Since: 1, Args: []proto.ArgDesc{
+			{Name: "callback", Type: proto.ArgNewId, Interface: "wl_callback", Nullable: false},
+		}},
+		{Name: "get_registry", Since: 1, Args: []proto.ArgDesc{
+			{Name: "registry", Type: proto.ArgNewId, Interface: "wl_registry", Nullable: false},
+		}},
+	},
+	Events: []proto.Signature{
+		{Name: "error", Since: 1, Args: []proto.ArgDesc{
+			{Name: "object_id", Type: proto.ArgObject, Interface: "", Nullable: false},
+			{Name: "code", Type: proto.ArgUint, Interface: "", Nullable: false},
+			{Name: "message", Type: proto.ArgString, Interface: "", Nullable: false},
+		}},
+		{Name: "delete_id", Since: 1, Args: []proto.ArgDesc{
+			{Name: "id", Type: proto.ArgUint, Interface: "", Nullable: false},
+		}},
+	},
+}
+
+func init() {
+	proto.RegisterInterface(DisplayInterface)
+}
+
+type ClientDisplayImplementation interface {
+
+	//
+	// The error event is sent out when a fatal (non-recoverable)
+	// error has occurred.  The object_id argument is the object
+	// where the error occurred, most often in response to a request
+	// to that object.  The code identifies the error and is defined
+	// by the object interface.  As such, each interface defines its
+	// own set of error codes.  The message is an brief description
+	// of the error, for (debugging) convenience.
+	//
+	Error(objectId proto.Object, code uint32, message string) error
+
+	//
+	// This event is used internally by the object ID management
+	// logic.  When a client deletes an object, the server will send
+	// this event to acknowledge that it has seen the delete request.
+	// When the client receive this event, it will know that it can
+	// safely reuse the object ID.
+	//
+	DeleteId(id uint32) error
+}
+
+// ClientDisplayListener implements ClientDisplayImplementation with a
+// function per event. Events whose function is nil are ignored, and fds
+// they carry are closed.
+type ClientDisplayListener struct {
+	OnError    func(objectId proto.Object, code uint32, message string) error
+	OnDeleteId func(id uint32) error
+}
+
+func (l ClientDisplayListener) Error(objectId proto.Object, code uint32, message string) error {
+	if l.OnError == nil {
+		return nil
+	}
+	return l.OnError(objectId, code, message)
+}
+
+func (l ClientDisplayListener) DeleteId(id uint32) error {
+	if l.OnDeleteId == nil {
+		return nil
+	}
+	return l.OnDeleteId(id)
+}
+
+type ClientDisplay struct {
+	c       *proto.Conn
+	id      proto.ObjectId
+	version uint32
+	i       ClientDisplayImplementation
+}
+
+// NewDisplay creates an object at the given version, which is the
+// version it was bound at or the version of the object that created it.
+func (c Client) NewDisplay(version uint32, i ClientDisplayImplementation) *ClientDisplay {
+	return c.NewDisplayWithId(c.c.NextId(), version, i)
+}
+
+// NewDisplayWithId registers an object created by the other end
+// of the connection under the id it has chosen.
+func (c Client) NewDisplayWithId(id proto.ObjectId, version uint32, i ClientDisplayImplementation) *ClientDisplay {
+	o := &ClientDisplay{
+		c:       c.c,
+		id:      id,
+		version: version,
+		i:       i,
+	}
+	c.c.AddObject(o.id, o)
+	return o
+}
+
+func (o *ClientDisplay) Id() proto.ObjectId {
+	return o.id
+}
+
+func (o *ClientDisplay) Version() uint32 {
+	return o.version
+}
+
+func (o *ClientDisplay) Conn() *proto.Conn {
+	return o.c
+}
+
+func (o *ClientDisplay) Interface() *proto.Interface {
+	return DisplayInterface
+}
+
+func (o *ClientDisplay) Handle(m *proto.Message) (err error) {
+	switch m.Opcode() {
+
+	case 0:
+
+		var (
+			objectId proto.Object
+
+			code uint32
+
+			message string
+		)
+
+		if objectId, err = m.ReadObject(false); err != nil {
+			return fmt.Errorf("wl_display.error: argument object_id: %w", err)
+		}
+
+		if code, err = m.ReadUint(); err != nil {
+			return
+		}
+
+		if message, err = m.ReadString(); err != nil {
+			return
+		}
+
+		return o.i.Error(objectId, code, message)
+
+	case 1:
+
+		var (
+			id uint32
+		)
+
+		if id, err = m.ReadUint(); err != nil {
+			return
+		}
+
+		return o.i.DeleteId(id)
+
+	default:
+		return fmt.Errorf("Display: invalid event opcode: %d", m.Opcode())
+	}
+}
+
+// The sync request asks the server to emit the 'done' event
+// on the returned wl_callback object.  Since requests are
+// handled in-order and events are delivered in-order, this can
+// be used as a barrier to ensure all previous requests and the
+// resulting events have been handled.
+//
+// The object returned by this request will be destroyed by the
+// compositor after the callback is fired and as such the client must not
+// attempt to use it after that point.
+//
+// The callback_data passed in the callback is the event serial.
+func (o *ClientDisplay) Sync(i ClientCallbackImplementation) (*ClientCallback, error) {
+	obj := NewClient(o.c).NewCallback(o.version, i)
+	m := proto.NewMessage(o.id, 0)
+
+	if err := m.WriteNewId(obj.Id()); err != nil {
+		o.c.DeleteObject(obj.Id())
+		return nil, err
+	}
+
+	if err := o.c.WriteMessage(m); err != nil {
+		o.c.DeleteObject(obj.Id())
+		return nil, err
+	}
+	return obj, nil
+}
+
+// This request creates a registry object that allows the client
+// to list and bind the global objects available from the
+// compositor.
+func (o *ClientDisplay) GetRegistry(i ClientRegistryImplementation) (*ClientRegistry, error) {
+	obj := NewClient(o.c).NewRegistry(o.version, i)
+	m := proto.NewMessage(o.id, 1)
+
+	if err := m.WriteNewId(obj.Id()); err != nil {
+		o.c.DeleteObject(obj.Id())
+		return nil, err
+	}
+
+	if err := o.c.WriteMessage(m); err != nil {
+		o.c.DeleteObject(obj.Id())
+		return nil, err
+	}
+	return obj, nil
+}
+
+type ServerDisplayImplementation interface {
+
+	//
+	// The sync request asks the server to emit the 'done' event
+	// on the returned wl_callback object.  Since requests are
+	// handled in-order and events are delivered in-order, this can
+	// be used as a barrier to ensure all previous requests and the
+	// resulting events have been handled.
+	//
+	// The object returned by this request will be destroyed by the
+	// compositor after the callback is fired and as such the client must not
+	// attempt to use it after that point.
+	//
+	// The callback_data passed in the callback is the event serial.
+	//
+	Sync(callback proto.ObjectId) error
+
+	//
+	// This request creates a registry object that allows the client
+	// to list and bind the global objects available from the
+	// compositor.
+	//
+	GetRegistry(registry proto.ObjectId) error
+}
+
+type ServerDisplay struct {
+	c       *proto.Conn
+	id      proto.ObjectId
+	version uint32
+	i       ServerDisplayImplementation
+}
+
+// NewDisplay creates an object at the given version, which is the
+// version it was bound at or the version of the object that created it.
+func (c Server) NewDisplay(version uint32, i ServerDisplayImplementation) *ServerDisplay {
+	return c.NewDisplayWithId(c.c.NextId(), version, i)
+}
+
+// NewDisplayWithId registers an object created by the other end
+// of the connection under the id it has chosen.
+func (c Server) NewDisplayWithId(id proto.ObjectId, version uint32, i ServerDisplayImplementation) *ServerDisplay {
+	o := &ServerDisplay{
+		c:       c.c,
+		id:      id,
+		version: version,
+		i:       i,
+	}
+	c.c.AddObject(o.id, o)
+	return o
+}
+
+func (o *ServerDisplay) Id() proto.ObjectId {
+	return o.id
+}
+
+func (o *ServerDisplay) Version() uint32 {
+	return o.version
+}
+
+func (o *ServerDisplay) Conn() *proto.Conn {
+	return o.c
+}
+
+func (o *ServerDisplay) Interface() *proto.Interface {
+	return DisplayInterface
+}
+
+func (o *ServerDisplay) Handle(m *proto.Message) (err error) {
+	switch m.Opcode() {
+
+	case 0:
+
+		var (
+			callback proto.ObjectId
+		)
+
+		if callback, err = m.ReadNewId(); err != nil {
+			return
+		}
+
+		return o.i.Sync(callback)
+
+	case 1:
+
+		var (
+			registry proto.ObjectId
+		)
+
+		if registry, err = m.ReadNewId(); err != nil {
+			return
+		}
+
+		return o.i.GetRegistry(registry)
+
+	default:
+		return fmt.Errorf("Display: invalid request opcode: %d", m.Opcode())
+	}
+}
+
+// The error event is sent out when a fatal (non-recoverable)
+// error has occurred.  The object_id argument is the object
+// where the error occurred, most often in response to a request
+// to that object.  The code identifies the error and is defined
+// by the object interface.  As such, each interface defines its
+// own set of error codes.  The message is an brief description
+// of the error, for (debugging) convenience.
+func (o *ServerDisplay) Error(objectId proto.Proxy, code uint32, message string) error {
+	var objectIdId proto.ObjectId
+	if objectId != nil {
+		objectIdId = objectId.Id()
+	} else {
+		return fmt.Errorf("wl_display.error: argument object_id is null")
+	}
+	m := proto.NewMessage(o.id, 0)
+
+	if err := m.WriteObjectId(objectIdId); err != nil {
+		return err
+	}
+
+	if err := m.WriteUint(code); err != nil {
+		return err
+	}
+
+	if err := m.WriteString(message); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// This event is used internally by the object ID management
+// logic.  When a client deletes an object, the server will send
+// this event to acknowledge that it has seen the delete request.
+// When the client receive this event, it will know that it can
+// safely reuse the object ID.
+func (o *ServerDisplay) DeleteId(id uint32) error {
+	m := proto.NewMessage(o.id, 1)
+
+	if err := m.WriteUint(id); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// Registry
+//
+// The global registry object.  The server has a number of global
+// objects that are available to all clients.  These objects
+// typically represent an actual object in the server (for example,
+// an input device) or they are singleton objects that provide
+// extension functionality.
+//
+// When a client creates a registry object, the registry object
+// will emit a global event for each global currently in the
+// registry.  Globals come and go as a result of device or
+// monitor hotplugs, reconfiguration or other events, and the
+// registry will send out global and global_remove events to
+// keep the client up to date with the changes.  To mark the end
+// of the initial burst of events, the client can use the
+// wl_display.sync request immediately after calling
+// wl_display.get_registry.
+//
+// A client can bind to a global object by using the bind
+// request.  This creates a client-side handle that lets the object
+// emit events to the client and lets the client invoke requests on
+// the object.
+//
+
+// Versions that introduced each request and event.
+const (
+	REGISTRY_BIND_SINCE_VERSION          = 1
+	REGISTRY_GLOBAL_SINCE_VERSION        = 1
+	REGISTRY_GLOBAL_REMOVE_SINCE_VERSION = 1
+)
+
+var RegistryInterface = &proto.Interface{
+	Name:    "wl_registry",
+	Version: 1,
+	Requests: []proto.Signature{
+		{Name: "bind", Since: 1, Args: []proto.ArgDesc{
+			{Name: "name", Type: proto.ArgUint, Interface: "", Nullable: false},
+			{Name: "interface", Type: proto.ArgString, Interface: "", Nullable: false},
+			{Name: "version", Type: proto.ArgUint, Interface: "", Nullable: false},
+			{Name: "id", Type: proto.ArgNewId, Interface: "", Nullable: false},
+		}},
+	},
+	Events: []proto.Signature{
+		{Name: "global", Since: 1, Args: []proto.ArgDesc{
+			{Name: "name", Type: proto.ArgUint, Interface: "", Nullable: false},
+			{Name: "interface", Type: proto.ArgString, Interface: "", Nullable: false},
+			{Name: "version", Type: proto.ArgUint, Interface: "", Nullable: false},
+		}},
+		{Name: "global_remove", Since: 1, Args: []proto.ArgDesc{
+			{Name: "name", Type: proto.ArgUint, Interface: "", Nullable: false},
+		}},
+	},
+}
+
+func init() {
+	proto.RegisterInterface(RegistryInterface)
+}
+
+type ClientRegistryImplementation interface {
+
+	//
+	// Notify the client of global objects.
+	//
+	// The event notifies the client that a global object with
+	// the given name is now available, and it implements the
+	// given version of the given interface.
+	//
+	Global(name uint32, interface_ string, version uint32) error
+
+	//
+	// Notify the client of removed global objects.
+	//
+	// This event notifies the client that the global identified
+	// by name is no longer available.  If the client bound to
+	// the global using the bind request, the client should now
+	// destroy that object.
+	//
+	// The object remains valid and requests to the object will be
+	// ignored until the client destroys it, to avoid races between
+	// the global going away and a client sending a request to it.
+	//
+	GlobalRemove(name uint32) error
+}
+
+// ClientRegistryListener implements ClientRegistryImplementation with a
+// function per event. Events whose function is nil are ignored, and fds
+// they carry are closed.
+type ClientRegistryListener struct {
+	OnGlobal       func(name uint32, interface_ string, version uint32) error
+	OnGlobalRemove func(name uint32) error
+}
+
+func (l ClientRegistryListener) Global(name uint32, interface_ string, version uint32) error {
+	if l.OnGlobal == nil {
+		return nil
+	}
+	return l.OnGlobal(name, interface_, version)
+}
+
+func (l ClientRegistryListener) GlobalRemove(name uint32) error {
+	if l.OnGlobalRemove == nil {
+		return nil
+	}
+	return l.OnGlobalRemove(name)
+}
+
+type ClientRegistry struct {
+	c       *proto.Conn
+	id      proto.ObjectId
+	version uint32
+	i       ClientRegistryImplementation
+}
+
+// NewRegistry creates an object at the given version, which is the
+// version it was bound at or the version of the object that created it.
+func (c Client) NewRegistry(version uint32, i ClientRegistryImplementation) *ClientRegistry {
+	return c.NewRegistryWithId(c.c.NextId(), version, i)
+}
+
+// NewRegistryWithId registers an object created by the other end
+// of the connection under the id it has chosen.
+func (c Client) NewRegistryWithId(id proto.ObjectId, version uint32, i ClientRegistryImplementation) *ClientRegistry {
+	o := &ClientRegistry{
+		c:       c.c,
+		id:      id,
+		version: version,
+		i:       i,
+	}
+	c.c.AddObject(o.id, o)
+	return o
+}
+
+func (o *ClientRegistry) Id() proto.ObjectId {
+	return o.id
+}
+
+func (o *ClientRegistry) Version() uint32 {
+	return o.version
+}
+
+func (o *ClientRegistry) Conn() *proto.Conn {
+	return o.c
+}
+
+func (o *ClientRegistry) Interface() *proto.Interface {
+	return RegistryInterface
+}
+
+func (o *ClientRegistry) Handle(m *proto.Message) (err error) {
+	switch m.Opcode() {
+
+	case 0:
+
+		var (
+			name uint32
+
+			interface_ string
+
+			version uint32
+		)
+
+		if name, err = m.ReadUint(); err != nil {
+			return
+		}
+
+		if interface_, err = m.ReadString(); err != nil {
+			return
+		}
+
+		if version, err = m.ReadUint(); err != nil {
+			return
+		}
+
+		return o.i.Global(name, interface_, version)
+
+	case 1:
+
+		var (
+			name uint32
+		)
+
+		if name, err = m.ReadUint(); err != nil {
+			return
+		}
+
+		return o.i.GlobalRemove(name)
+
+	default:
+		return fmt.Errorf("Registry: invalid event opcode: %d", m.Opcode())
+	}
+}
+
+// Binds a new, client-created object to the server using the
+// specified name as the identifier.
+func (o *ClientRegistry) Bind(name uint32, interface_ string, version uint32, id proto.Proxy) error {
+	m := proto.NewMessage(o.id, 0)
+
+	if err := m.WriteUint(name); err != nil {
+		return err
+	}
+
+	if err := m.WriteString(interface_); err != nil {
+		return err
+	}
+
+	if err := m.WriteUint(version); err != nil {
+		return err
+	}
+
+	if err := m.WriteNewId(id.Id()); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+type ServerRegistryImplementation interface {
+
+	//
+	// Binds a new, client-created object to the server using the
+	// specified name as the identifier.
+	//
+	Bind(name uint32, interface_ string, version uint32, id proto.ObjectId) error
+}
+
+type ServerRegistry struct {
+	c       *proto.Conn
+	id      proto.ObjectId
+	version uint32
+	i       ServerRegistryImplementation
+}
+
+// NewRegistry creates an object at the given version, which is the
+// version it was bound at or the version of the object that created it.
+func (c Server) NewRegistry(version uint32, i ServerRegistryImplementation) *ServerRegistry {
+	return c.NewRegistryWithId(c.c.NextId(), version, i)
+}
+
+// NewRegistryWithId registers an object created by the other end
+// of the connection under the id it has chosen.
+func (c Server) NewRegistryWithId(id proto.ObjectId, version uint32, i ServerRegistryImplementation) *ServerRegistry {
+	o := &ServerRegistry{
+		c:       c.c,
+		id:      id,
+		version: version,
+		i:       i,
+	}
+	c.c.AddObject(o.id, o)
+	return o
+}
+
+func (o *ServerRegistry) Id() proto.ObjectId {
+	return o.id
+}
+
+func (o *ServerRegistry) Version() uint32 {
+	return o.version
+}
+
+func (o *ServerRegistry) Conn() *proto.Conn {
+	return o.c
+}
+
+func (o *ServerRegistry) Interface() *proto.Interface {
+	return RegistryInterface
+}
+
+func (o *ServerRegistry) Handle(m *proto.Message) (err error) {
+	switch m.Opcode() {
+
+	case 0:
+
+		var (
+			name uint32
+
+			interface_ string
+
+			version uint32
+
+			id proto.ObjectId
+		)
+
+		if name, err = m.ReadUint(); err != nil {
+			return
+		}
+
+		if interface_, err = m.ReadString(); err != nil {
+			return
+		}
+
+		if version, err = m.ReadUint(); err != nil {
+			return
+		}
+
+		if id, err = m.ReadNewId(); err != nil {
+			return
+		}
+
+		return o.i.Bind(name, interface_, version, id)
+
+	default:
+		return fmt.Errorf("Registry: invalid request opcode: %d", m.Opcode())
+	}
+}
+
+// Notify the client of global objects.
+//
+// The event notifies the client that a global object with
+// the given name is now available, and it implements the
+// given version of the given interface.
+func (o *ServerRegistry) Global(name uint32, interface_ string, version uint32) error {
+	m := proto.NewMessage(o.id, 0)
+
+	if err := m.WriteUint(name); err != nil {
+		return err
+	}
+
+	if err := m.WriteString(interface_); err != nil {
+		return err
+	}
+
+	if err := m.WriteUint(version); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// Notify the client of removed global objects.
+//
+// This event notifies the client that the global identified
+// by name is no longer available.  If the client bound to
+// the global using the bind request, the client should now
+// destroy that object.
+//
+// The object remains valid and requests to the object will be
+// ignored until the client destroys it, to avoid races between
+// the global going away and a client sending a request to it.
+func (o *ServerRegistry) GlobalRemove(name uint32) error {
+	m := proto.NewMessage(o.id, 1)
+
+	if err := m.WriteUint(name); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// Callback
+//
+// Clients can handle the 'done' event to get notified when
+// the related request is done.
+//
+
+// Versions that introduced each request and event.
+const (
+	CALLBACK_DONE_SINCE_VERSION = 1
+)
+
+var CallbackInterface = &proto.Interface{
+	Name:     "wl_callback",
+	Version:  1,
+	Requests: []proto.Signature{},
+	Events: []proto.Signature{
+		{Name: "done", Since: 1, Args: []proto.ArgDesc{
+			{Name: "callback_data", Type: proto.ArgUint, Interface: "", Nullable: false},
+		}},
+	},
+}
+
+func init() {
+	proto.RegisterInterface(CallbackInterface)
+}
+
+type ClientCallbackImplementation interface {
+
+	//
+	// Notify the client when the related request is done.
+	//
+	Done(callbackData uint32) error
+}
+
+// ClientCallbackListener implements ClientCallbackImplementation with a
+// function per event. Events whose function is nil are ignored, and fds
+// they carry are closed.
+type ClientCallbackListener struct {
+	OnDone func(callbackData uint32) error
+}
+
+func (l ClientCallbackListener) Done(callbackData uint32) error {
+	if l.OnDone == nil {
+		return nil
+	}
+	return l.OnDone(callbackData)
+}
+
+type ClientCallback struct {
+	c       *proto.Conn
+	id      proto.ObjectId
+	version uint32
+	i       ClientCallbackImplementation
+}
+
+// NewCallback creates an object at the given version, which is the
+// version it was bound at or the version of the object that created it.
+func (c Client) NewCallback(version uint32, i ClientCallbackImplementation) *ClientCallback {
+	return c.NewCallbackWithId(c.c.NextId(), version, i)
+}
+
+// NewCallbackWithId registers an object created by the other end
+// of the connection under the id it has chosen.
+func (c Client) NewCallbackWithId(id proto.ObjectId, version uint32, i ClientCallbackImplementation) *ClientCallback {
+	o := &ClientCallback{
+		c:       c.c,
+		id:      id,
+		version: version,
+		i:       i,
+	}
+	c.c.AddObject(o.id, o)
+	return o
+}
+
+func (o *ClientCallback) Id() proto.ObjectId {
+	return o.id
+}
+
+func (o *ClientCallback) Version() uint32 {
+	return o.version
+}
+
+func (o *ClientCallback) Conn() *proto.Conn {
+	return o.c
+}
+
+func (o *ClientCallback) Interface() *proto.Interface {
+	return CallbackInterface
+}
+
+func (o *ClientCallback) Handle(m *proto.Message) (err error) {
+	switch m.Opcode() {
+
+	case 0:
+
+		var (
+			callbackData uint32
+		)
+
+		if callbackData, err = m.ReadUint(); err != nil {
+			return
+		}
+
+		return o.i.Done(callbackData)
+
+	default:
+		return fmt.Errorf("Callback: invalid event opcode: %d", m.Opcode())
+	}
+}
+
+type ServerCallbackImplementation interface {
+}
+
+type ServerCallback struct {
+	c       *proto.Conn
+	id      proto.ObjectId
+	version uint32
+	i       ServerCallbackImplementation
+}
+
+// NewCallback creates an object at the given version, which is the
+// version it was bound at or the version of the object that created it.
+func (c Server) NewCallback(version uint32, i ServerCallbackImplementation) *ServerCallback {
+	return c.NewCallbackWithId(c.c.NextId(), version, i)
+}
+
+// NewCallbackWithId registers an object created by the other end
+// of the connection under the id it has chosen.
+func (c Server) NewCallbackWithId(id proto.ObjectId, version uint32, i ServerCallbackImplementation) *ServerCallback {
+	o := &ServerCallback{
+		c:       c.c,
+		id:      id,
+		version: version,
+		i:       i,
+	}
+	c.c.AddObject(o.id, o)
+	return o
+}
+
+func (o *ServerCallback) Id() proto.ObjectId {
+	return o.id
+}
+
+func (o *ServerCallback) Version() uint32 {
+	return o.version
+}
+
+func (o *ServerCallback) Conn() *proto.Conn {
+	return o.c
+}
+
+func (o *ServerCallback) Interface() *proto.Interface {
+	return CallbackInterface
+}
+
+func (o *ServerCallback) Handle(m *proto.Message) (err error) {
+	switch m.Opcode() {
+
+	default:
+		return fmt.Errorf("Callback: invalid request opcode: %d", m.Opcode())
+	}
+}
+
+// Notify the client when the related request is done.
+func (o *ServerCallback) Done(callbackData uint32) error {
+	m := proto.NewMessage(o.id, 0)
+
+	if err := m.WriteUint(callbackData); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// Compositor
+//
+// A compositor.  This object is a singleton global.  The
+// compositor is in charge of combining the contents of multiple
+// surfaces into one displayable output.
+//
+
+// Versions that introduced each request and event.
+const (
+	COMPOSITOR_CREATE_SURFACE_SINCE_VERSION = 1
+	COMPOSITOR_CREATE_REGION_SINCE_VERSION  = 1
+)
+
+var CompositorInterface = &proto.Interface{
+	Name:    "wl_compositor",
+	Version: 3,
+	Requests: []proto.Signature{
+		{Name: "create_surface", Since: 1, Args: []proto.ArgDesc{
+			{Name: "id", Type: proto.ArgNewId, Interface: "wl_surface", Nullable: false},
+		}},
+		{Name: "create_region", Since: 1, Args: []proto.ArgDesc{
+			{Name: "id", Type: proto.ArgNewId, Interface: "wl_region", Nullable: false},
+		}},
+	},
+	Events: []proto.Signature{},
+}
+
+func init() {
+	proto.RegisterInterface(CompositorInterface)
+}
+
+type ClientCompositorImplementation interface {
+}
+
+// ClientCompositorListener implements ClientCompositorImplementation with a
+// function per event. Events whose function is nil are ignored, and fds
+// they carry are closed.
+type ClientCompositorListener struct {
+}
+
+type ClientCompositor struct {
+	c       *proto.Conn
+	id      proto.ObjectId
+	version uint32
+	i       ClientCompositorImplementation
+}
+
+// NewCompositor creates an object at the given version, which is the
+// version it was bound at or the version of the object that created it.
+func (c Client) NewCompositor(version uint32, i ClientCompositorImplementation) *ClientCompositor {
+	return c.NewCompositorWithId(c.c.NextId(), version, i)
+}
+
+// NewCompositorWithId registers an object created by the other end
+// of the connection under the id it has chosen.
+func (c Client) NewCompositorWithId(id proto.ObjectId, version uint32, i ClientCompositorImplementation) *ClientCompositor {
+	o := &ClientCompositor{
+		c:       c.c,
+		id:      id,
+		version: version,
+		i:       i,
+	}
+	c.c.AddObject(o.id, o)
+	return o
+}
+
+func (o *ClientCompositor) Id() proto.ObjectId {
+	return o.id
+}
+
+func (o *ClientCompositor) Version() uint32 {
+	return o.version
+}
+
+func (o *ClientCompositor) Conn() *proto.Conn {
+	return o.c
+}
+
+func (o *ClientCompositor) Interface() *proto.Interface {
+	return CompositorInterface
+}
+
+func (o *ClientCompositor) Handle(m *proto.Message) (err error) {
+	switch m.Opcode() {
+
+	default:
+		return fmt.Errorf("Compositor: invalid event opcode: %d", m.Opcode())
+	}
+}
+
+// BindCompositor binds global, which must be a wl_compositor, at the
+// lower of its advertised version and maxVersion, and returns the proxy.
+// maxVersion is capped at 3, the version known to this package.
+func BindCompositor(registry proto.Registry, global proto.Global, maxVersion uint32, i ClientCompositorImplementation) (*ClientCompositor, error) {
+	if maxVersion > 3 {
+		maxVersion = 3
+	}
+	return proto.BindGlobal(registry, global, maxVersion, func(version uint32) *ClientCompositor {
+		return NewClient(registry.Conn()).NewCompositor(version, i)
+	})
+}
+
+// Ask the compositor to create a new surface.
+func (o *ClientCompositor) CreateSurface(i ClientSurfaceImplementation) (*ClientSurface, error) {
+	obj := NewClient(o.c).NewSurface(o.version, i)
+	m := proto.NewMessage(o.id, 0)
+
+	if err := m.WriteNewId(obj.Id()); err != nil {
+		o.c.DeleteObject(obj.Id())
+		return nil, err
+	}
+
+	if err := o.c.WriteMessage(m); err != nil {
+		o.c.DeleteObject(obj.Id())
+		return nil, err
+	}
+	return obj, nil
+}
+
+// Ask the compositor to create a new region.
+func (o *ClientCompositor) CreateRegion(i ClientRegionImplementation) (*ClientRegion, error) {
+	obj := NewClient(o.c).NewRegion(o.version, i)
+	m := proto.NewMessage(o.id, 1)
+
+	if err := m.WriteNewId(obj.Id()); err != nil {
+		o.c.DeleteObject(obj.Id())
+		return nil, err
+	}
+
+	if err := o.c.WriteMessage(m); err != nil {
+		o.c.DeleteObject(obj.Id())
+		return nil, err
+	}
+	return obj, nil
+}
+
+type ServerCompositorImplementation interface {
+
+	//
+	// Ask the compositor to create a new surface.
+	//
+	CreateSurface(id proto.ObjectId) error
+
+	//
+	// Ask the compositor to create a new region.
+	//
+	CreateRegion(id proto.ObjectId) error
+}
+
+type ServerCompositor struct {
+	c       *proto.Conn
+	id      proto.ObjectId
+	version uint32
+	i       ServerCompositorImplementation
+}
+
+// NewCompositor creates an object at the given version, which is the
+// version it was bound at or the version of the object that created it.
+func (c Server) NewCompositor(version uint32, i ServerCompositorImplementation) *ServerCompositor {
+	return c.NewCompositorWithId(c.c.NextId(), version, i)
+}
+
+// NewCompositorWithId registers an object created by the other end
+// of the connection under the id it has chosen.
+func (c Server) NewCompositorWithId(id proto.ObjectId, version uint32, i ServerCompositorImplementation) *ServerCompositor {
+	o := &ServerCompositor{
+		c:       c.c,
+		id:      id,
+		version: version,
+		i:       i,
+	}
+	c.c.AddObject(o.id, o)
+	return o
+}
+
+func (o *ServerCompositor) Id() proto.ObjectId {
+	return o.id
+}
+
+func (o *ServerCompositor) Version() uint32 {
+	return o.version
+}
+
+func (o *ServerCompositor) Conn() *proto.Conn {
+	return o.c
+}
+
+func (o *ServerCompositor) Interface() *proto.Interface {
+	return CompositorInterface
+}
+
+func (o *ServerCompositor) Handle(m *proto.Message) (err error) {
+	switch m.Opcode() {
+
+	case 0:
+
+		var (
+			id proto.ObjectId
+		)
+
+		if id, err = m.ReadNewId(); err != nil {
+			return
+		}
+
+		return o.i.CreateSurface(id)
+
+	case 1:
+
+		var (
+			id proto.ObjectId
+		)
+
+		if id, err = m.ReadNewId(); err != nil {
+			return
+		}
+
+		return o.i.CreateRegion(id)
+
+	default:
+		return fmt.Errorf("Compositor: invalid request opcode: %d", m.Opcode())
+	}
+}
+
+// ShmPool
+//
+// The wl_shm_pool object encapsulates a piece of memory shared
+// between the compositor and client.  Through the wl_shm_pool
+// object, the client can allocate shared memory wl_buffer objects.
+// All objects created through the same pool share the same
+// underlying mapped memory. Reusing the mapped memory avoids the
+// setup/teardown overhead and is useful when interactively resizing
+// a surface or for many small buffers.
+//
+
+// Versions that introduced each request and event.
+const (
+	SHM_POOL_CREATE_BUFFER_SINCE_VERSION = 1
+	SHM_POOL_DESTROY_SINCE_VERSION       = 1
+	SHM_POOL_RESIZE_SINCE_VERSION        = 1
+)
+
+var ShmPoolInterface = &proto.Interface{
+	Name:    "wl_shm_pool",
+	Version: 1,
+	Requests: []proto.Signature{
+		{Name: "create_buffer", Since: 1, Args: []proto.ArgDesc{
+			{Name: "id", Type: proto.ArgNewId, Interface: "wl_buffer", Nullable: false},
+			{Name: "offset", Type: proto.ArgInt, Interface: "", Nullable: false},
+			{Name: "width", Type: proto.ArgInt, Interface: "", Nullable: false},
+			{Name: "height", Type: proto.ArgInt, Interface: "", Nullable: false},
+			{Name: "stride", Type: proto.ArgInt, Interface: "", Nullable: false},
+			{Name: "format", Type: proto.ArgUint, Interface: "", Nullable: false},
+		}},
+		{Name: "destroy", Since: 1, Args: []proto.ArgDesc{}},
+		{Name: "resize", Since: 1, Args: []proto.ArgDesc{
+			{Name: "size", Type: proto.ArgInt, Interface: "", Nullable: false},
+		}},
+	},
+	Events: []proto.Signature{},
+}
+
+func init() {
+	proto.RegisterInterface(ShmPoolInterface)
+}
+
+type ClientShmPoolImplementation interface {
+}
+
+// ClientShmPoolListener implements ClientShmPoolImplementation with a
+// function per event. Events whose function is nil are ignored, and fds
+// they carry are closed.
+type ClientShmPoolListener struct {
+}
+
+type ClientShmPool struct {
+	c         *proto.Conn
+	id        proto.ObjectId
+	version   uint32
+	i         ClientShmPoolImplementation
+	destroyed bool
+}
+
+// NewShmPool creates an object at the given version, which is the
+// version it was bound at or the version of the object that created it.
+func (c Client) NewShmPool(version uint32, i ClientShmPoolImplementation) *ClientShmPool {
+	return c.NewShmPoolWithId(c.c.NextId(), version, i)
+}
+
+// NewShmPoolWithId registers an object created by the other end
+// of the connection under the id it has chosen.
+func (c Client) NewShmPoolWithId(id proto.ObjectId, version uint32, i ClientShmPoolImplementation) *ClientShmPool {
+	o := &ClientShmPool{
+		c:       c.c,
+		id:      id,
+		version: version,
+		i:       i,
+	}
+	c.c.AddObject(o.id, o)
+	return o
+}
+
+func (o *ClientShmPool) Id() proto.ObjectId {
+	return o.id
+}
+
+func (o *ClientShmPool) Version() uint32 {
+	return o.version
+}
+
+func (o *ClientShmPool) Conn() *proto.Conn {
+	return o.c
+}
+
+func (o *ClientShmPool) Interface() *proto.Interface {
+	return ShmPoolInterface
+}
+
+func (o *ClientShmPool) Handle(m *proto.Message) (err error) {
+	switch m.Opcode() {
+
+	default:
+		return fmt.Errorf("ShmPool: invalid event opcode: %d", m.Opcode())
+	}
+}
+
+// Create a wl_buffer object from the pool.
+//
+// The buffer is created offset bytes into the pool and has
+// width and height as specified.  The stride arguments specifies
+// the number of bytes from beginning of one row to the beginning
+// of the next.  The format is the pixel format of the buffer and
+// must be one of those advertised through the wl_shm.format event.
+//
+// A buffer will keep a reference to the pool it was created from
+// so it is valid to destroy the pool immediately after creating
+// a buffer from it.
+func (o *ClientShmPool) CreateBuffer(i ClientBufferImplementation, offset int32, width int32, height int32, stride int32, format ShmFormat) (*ClientBuffer, error) {
+	if o.destroyed {
+		return nil, fmt.Errorf("wl_shm_pool@%d.create_buffer: %w", o.id, proto.ErrDestroyed)
+	}
+	obj := NewClient(o.c).NewBuffer(o.version, i)
+	m := proto.NewMessage(o.id, 0)
+
+	if err := m.WriteNewId(obj.Id()); err != nil {
+		o.c.DeleteObject(obj.Id())
+		return nil, err
+	}
+
+	if err := m.WriteInt(offset); err != nil {
+		o.c.DeleteObject(obj.Id())
+		return nil, err
+	}
+
+	if err := m.WriteInt(width); err != nil {
+		o.c.DeleteObject(obj.Id())
+		return nil, err
+	}
+
+	if err := m.WriteInt(height); err != nil {
+		o.c.DeleteObject(obj.Id())
+		return nil, err
+	}
+
+	if err := m.WriteInt(stride); err != nil {
+		o.c.DeleteObject(obj.Id())
+		return nil, err
+	}
+
+	if err := m.WriteUint(uint32(format)); err != nil {
+		o.c.DeleteObject(obj.Id())
+		return nil, err
+	}
+
+	if err := o.c.WriteMessage(m); err != nil {
+		o.c.DeleteObject(obj.Id())
+		return nil, err
+	}
+	return obj, nil
+}
+
+// Destroy the shared memory pool.
+//
+// The mmapped memory will be released when all
+// buffers that have been created from this pool
+// are gone.
+func (o *ClientShmPool) Destroy() error {
+	if o.destroyed {
+		return fmt.Errorf("wl_shm_pool@%d.destroy: %w", o.id, proto.ErrDestroyed)
+	}
+	m := proto.NewMessage(o.id, 1)
+
+	if err := o.c.WriteMessage(m); err != nil {
+		return err
+	}
+	o.destroyed = true
+	return o.c.DestroyObject(o.id)
+}
+
+// This request will cause the server to remap the backing memory
+// for the pool from the file descriptor passed when the pool was
+// created, but using the new size.
+func (o *ClientShmPool) Resize(size int32) error {
+	if o.destroyed {
+		return fmt.Errorf("wl_shm_pool@%d.resize: %w", o.id, proto.ErrDestroyed)
+	}
+	m := proto.NewMessage(o.id, 2)
+
+	if err := m.WriteInt(size); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+type ServerShmPoolImplementation interface {
+
+	//
+	// Create a wl_buffer object from the pool.
+	//
+	// The buffer is created offset bytes into the pool and has
+	// width and height as specified.  The stride arguments specifies
+	// the number of bytes from beginning of one row to the beginning
+	// of the next.  The format is the pixel format of the buffer and
+	// must be one of those advertised through the wl_shm.format event.
+	//
+	// A buffer will keep a reference to the pool it was created from
+	// so it is valid to destroy the pool immediately after creating
+	// a buffer from it.
+	//
+	CreateBuffer(id proto.ObjectId, offset int32, width int32, height int32, stride int32, format ShmFormat) error
+
+	//
+	// Destroy the shared memory pool.
+	//
+	// The mmapped memory will be released when all
+	// buffers that have been created from this pool
+	// are gone.
+	//
+	Destroy() error
+
+	//
+	// This request will cause the server to remap the backing memory
+	// for the pool from the file descriptor passed when the pool was
+	// created, but using the new size.
+	//
+	Resize(size int32) error
+}
+
+type ServerShmPool struct {
+	c         *proto.Conn
+	id        proto.ObjectId
+	version   uint32
+	i         ServerShmPoolImplementation
+	destroyed bool
+}
+
+// NewShmPool creates an object at the given version, which is the
+// version it was bound at or the version of the object that created it.
+func (c Server) NewShmPool(version uint32, i ServerShmPoolImplementation) *ServerShmPool {
+	return c.NewShmPoolWithId(c.c.NextId(), version, i)
+}
+
+// NewShmPoolWithId registers an object created by the other end
+// of the connection under the id it has chosen.
+func (c Server) NewShmPoolWithId(id proto.ObjectId, version uint32, i ServerShmPoolImplementation) *ServerShmPool {
+	o := &ServerShmPool{
+		c:       c.c,
+		id:      id,
+		version: version,
+		i:       i,
+	}
+	c.c.AddObject(o.id, o)
+	return o
+}
+
+func (o *ServerShmPool) Id() proto.ObjectId {
+	return o.id
+}
+
+func (o *ServerShmPool) Version() uint32 {
+	return o.version
+}
+
+func (o *ServerShmPool) Conn() *proto.Conn {
+	return o.c
+}
+
+func (o *ServerShmPool) Interface() *proto.Interface {
+	return ShmPoolInterface
+}
+
+func (o *ServerShmPool) Handle(m *proto.Message) (err error) {
+	switch m.Opcode() {
+
+	case 0:
+
+		var (
+			id proto.ObjectId
+
+			offset int32
+
+			width int32
+
+			height int32
+
+			stride int32
+
+			format ShmFormat
+		)
+
+		if id, err = m.ReadNewId(); err != nil {
+			return
+		}
+
+		if offset, err = m.ReadInt(); err != nil {
+			return
+		}
+
+		if width, err = m.ReadInt(); err != nil {
+			return
+		}
+
+		if height, err = m.ReadInt(); err != nil {
+			return
+		}
+
+		if stride, err = m.ReadInt(); err != nil {
+			return
+		}
+
+		var formatRaw uint32
+		if formatRaw, err = m.ReadUint(); err != nil {
+			return
+		}
+		format = ShmFormat(formatRaw)
+
+		return o.i.CreateBuffer(id, offset, width, height, stride, format)
+
+	case 1:
+
+		var ()
+
+		err = o.i.Destroy()
+		o.destroyed = true
+		if derr := o.c.DestroyObject(o.id); err == nil {
+			err = derr
+		}
+		return
+
+	case 2:
+
+		var (
+			size int32
+		)
+
+		if size, err = m.ReadInt(); err != nil {
+			return
+		}
+
+		return o.i.Resize(size)
+
+	default:
+		return fmt.Errorf("ShmPool: invalid request opcode: %d", m.Opcode())
+	}
+}
+
+// Shm
+//
+// A global singleton object that provides support for shared
+// memory.
+//
+// Clients can create wl_shm_pool objects using the create_pool
+// request.
+//
+// At connection setup time, the wl_shm object emits one or more
+// format events to inform clients about the valid pixel formats
+// that can be used for buffers.
+//
+
+// These errors can be emitted in response to wl_shm requests.
+type ShmError uint32
+
+const (
+
+	// buffer format is not known
+	SHM_ERROR_INVALID_FORMAT ShmError = 0
+
+	// invalid size or stride during pool or buffer creation
+	SHM_ERROR_INVALID_STRIDE ShmError = 1
+
+	// mmapping the file descriptor failed
+	SHM_ERROR_INVALID_FD ShmError = 2
+)
+
+func (e ShmError) String() string {
+	switch e {
+	case SHM_ERROR_INVALID_FORMAT:
+		return "invalid_format"
+	case SHM_ERROR_INVALID_STRIDE:
+		return "invalid_stride"
+	case SHM_ERROR_INVALID_FD:
+		return "invalid_fd"
+	}
+	return fmt.Sprintf("ShmError(%d)", uint32(e))
+}
+
+// This describes the memory layout of an individual pixel.
+//
+// All renderers should support argb8888 and xrgb8888 but any other
+// formats are optional and may not be supported by the particular
+// renderer in use.
+type ShmFormat uint32
+
+const (
+
+	// 32-bit ARGB format
+	SHM_FORMAT_ARGB8888 ShmFormat = 0
+
+	// 32-bit RGB format
+	SHM_FORMAT_XRGB8888 ShmFormat = 1
+
+	//
+	SHM_FORMAT_C8 ShmFormat = 0x20203843
+
+	//
+	SHM_FORMAT_RGB332 ShmFormat = 0x38424752
+
+	//
+	SHM_FORMAT_BGR233 ShmFormat = 0x38524742
+
+	//
+	SHM_FORMAT_XRGB4444 ShmFormat = 0x32315258
+
+	//
+	SHM_FORMAT_XBGR4444 ShmFormat = 0x32314258
+
+	//
+	SHM_FORMAT_RGBX4444 ShmFormat = 0x32315852
+
+	//
+	SHM_FORMAT_BGRX4444 ShmFormat = 0x32315842
+
+	//
+	SHM_FORMAT_ARGB4444 ShmFormat = 0x32315241
+
+	//
+	SHM_FORMAT_ABGR4444 ShmFormat = 0x32314241
+
+	//
+	SHM_FORMAT_RGBA4444 ShmFormat = 0x32314152
+
+	//
+	SHM_FORMAT_BGRA4444 ShmFormat = 0x32314142
+
+	//
+	SHM_FORMAT_XRGB1555 ShmFormat = 0x35315258
+
+	//
+	SHM_FORMAT_XBGR1555 ShmFormat = 0x35314258
+
+	//
+	SHM_FORMAT_RGBX5551 ShmFormat = 0x35315852
+
+	//
+	SHM_FORMAT_BGRX5551 ShmFormat = 0x35315842
+
+	//
+	SHM_FORMAT_ARGB1555 ShmFormat = 0x35315241
+
+	//
+	SHM_FORMAT_ABGR1555 ShmFormat = 0x35314241
+
+	//
+	SHM_FORMAT_RGBA5551 ShmFormat = 0x35314152
+
+	//
+	SHM_FORMAT_BGRA5551 ShmFormat = 0x35314142
+
+	//
+	SHM_FORMAT_RGB565 ShmFormat = 0x36314752
+
+	//
+	SHM_FORMAT_BGR565 ShmFormat = 0x36314742
+
+	//
+	SHM_FORMAT_RGB888 ShmFormat = 0x34324752
+
+	//
+	SHM_FORMAT_BGR888 ShmFormat = 0x34324742
+
+	//
+	SHM_FORMAT_XBGR8888 ShmFormat = 0x34324258
+
+	//
+	SHM_FORMAT_RGBX8888 ShmFormat = 0x34325852
+
+	//
+	SHM_FORMAT_BGRX8888 ShmFormat = 0x34325842
+
+	//
+	SHM_FORMAT_ABGR8888 ShmFormat = 0x34324241
+
+	//
+	SHM_FORMAT_RGBA8888 ShmFormat = 0x34324152
+
+	//
+	SHM_FORMAT_BGRA8888 ShmFormat = 0x34324142
+
+	//
+	SHM_FORMAT_XRGB2101010 ShmFormat = 0x30335258
+
+	//
+	SHM_FORMAT_XBGR2101010 ShmFormat = 0x30334258
+
+	//
+	SHM_FORMAT_RGBX1010102 ShmFormat = 0x30335852
+
+	//
+	SHM_FORMAT_BGRX1010102 ShmFormat = 0x30335842
+
+	//
+	SHM_FORMAT_ARGB2101010 ShmFormat = 0x30335241
+
+	//
+	SHM_FORMAT_ABGR2101010 ShmFormat = 0x30334241
+
+	//
+	SHM_FORMAT_RGBA1010102 ShmFormat = 0x30334152
+
+	//
+	SHM_FORMAT_BGRA1010102 ShmFormat = 0x30334142
+
+	//
+	SHM_FORMAT_YUYV ShmFormat = 0x56595559
+
+	//
+	SHM_FORMAT_YVYU ShmFormat = 0x55595659
+
+	//
+	SHM_FORMAT_UYVY ShmFormat = 0x59565955
+
+	//
+	SHM_FORMAT_VYUY ShmFormat = 0x59555956
+
+	//
+	SHM_FORMAT_AYUV ShmFormat = 0x56555941
+
+	//
+	SHM_FORMAT_NV12 ShmFormat = 0x3231564e
+
+	//
+	SHM_FORMAT_NV21 ShmFormat = 0x3132564e
+
+	//
+	SHM_FORMAT_NV16 ShmFormat = 0x3631564e
+
+	//
+	SHM_FORMAT_NV61 ShmFormat = 0x3136564e
+
+	//
+	SHM_FORMAT_YUV410 ShmFormat = 0x39565559
+
+	//
+	SHM_FORMAT_YVU410 ShmFormat = 0x39555659
+
+	//
+	SHM_FORMAT_YUV411 ShmFormat = 0x31315559
+
+	//
+	SHM_FORMAT_YVU411 ShmFormat = 0x31315659
+
+	//
+	SHM_FORMAT_YUV420 ShmFormat = 0x32315559
+
+	//
+	SHM_FORMAT_YVU420 ShmFormat = 0x32315659
+
+	//
+	SHM_FORMAT_YUV422 ShmFormat = 0x36315559
+
+	//
+	SHM_FORMAT_YVU422 ShmFormat = 0x36315659
+
+	//
+	SHM_FORMAT_YUV444 ShmFormat = 0x34325559
+
+	//
+	SHM_FORMAT_YVU444 ShmFormat = 0x34325659
+)
+
+func (e ShmFormat) String() string {
+	switch e {
+	case SHM_FORMAT_ARGB8888:
+		return "argb8888"
+	case SHM_FORMAT_XRGB8888:
+		return "xrgb8888"
+	case SHM_FORMAT_C8:
+		return "c8"
+	case SHM_FORMAT_RGB332:
+		return "rgb332"
+	case SHM_FORMAT_BGR233:
+		return "bgr233"
+	case SHM_FORMAT_XRGB4444:
+		return "xrgb4444"
+	case SHM_FORMAT_XBGR4444:
+		return "xbgr4444"
+	case SHM_FORMAT_RGBX4444:
+		return "rgbx4444"
+	case SHM_FORMAT_BGRX4444:
+		return "bgrx4444"
+	case SHM_FORMAT_ARGB4444:
+		return "argb4444"
+	case SHM_FORMAT_ABGR4444:
+		return "abgr4444"
+	case SHM_FORMAT_RGBA4444:
+		return "rgba4444"
+	case SHM_FORMAT_BGRA4444:
+		return "bgra4444"
+	case SHM_FORMAT_XRGB1555:
+		return "xrgb1555"
+	case SHM_FORMAT_XBGR1555:
+		return "xbgr1555"
+	case SHM_FORMAT_RGBX5551:
+		return "rgbx5551"
+	case SHM_FORMAT_BGRX5551:
+		return "bgrx5551"
+	case SHM_FORMAT_ARGB1555:
+		return "argb1555"
+	case SHM_FORMAT_ABGR1555:
+		return "abgr1555"
+	case SHM_FORMAT_RGBA5551:
+		return "rgba5551"
+	case SHM_FORMAT_BGRA5551:
+		return "bgra5551"
+	case SHM_FORMAT_RGB565:
+		return "rgb565"
+	case SHM_FORMAT_BGR565:
+		return "bgr565"
+	case SHM_FORMAT_RGB888:
+		return "rgb888"
+	case SHM_FORMAT_BGR888:
+		return "bgr888"
+	case SHM_FORMAT_XBGR8888:
+		return "xbgr8888"
+	case SHM_FORMAT_RGBX8888:
+		return "rgbx8888"
+	case SHM_FORMAT_BGRX8888:
+		return "bgrx8888"
+	case SHM_FORMAT_ABGR8888:
+		return "abgr8888"
+	case SHM_FORMAT_RGBA8888:
+		return "rgba8888"
+	case SHM_FORMAT_BGRA8888:
+		return "bgra8888"
+	case SHM_FORMAT_XRGB2101010:
+		return "xrgb2101010"
+	case SHM_FORMAT_XBGR2101010:
+		return "xbgr2101010"
+	case SHM_FORMAT_RGBX1010102:
+		return "rgbx1010102"
+	case SHM_FORMAT_BGRX1010102:
+		return "bgrx1010102"
+	case SHM_FORMAT_ARGB2101010:
+		return "argb2101010"
+	case SHM_FORMAT_ABGR2101010:
+		return "abgr2101010"
+	case SHM_FORMAT_RGBA1010102:
+		return "rgba1010102"
+	case SHM_FORMAT_BGRA1010102:
+		return "bgra1010102"
+	case SHM_FORMAT_YUYV:
+		return "yuyv"
+	case SHM_FORMAT_YVYU:
+		return "yvyu"
+	case SHM_FORMAT_UYVY:
+		return "uyvy"
+	case SHM_FORMAT_VYUY:
+		return "vyuy"
+	case SHM_FORMAT_AYUV:
+		return "ayuv"
+	case SHM_FORMAT_NV12:
+		return "nv12"
+	case SHM_FORMAT_NV21:
+		return "nv21"
+	case SHM_FORMAT_NV16:
+		return "nv16"
+	case SHM_FORMAT_NV61:
+		return "nv61"
+	case SHM_FORMAT_YUV410:
+		return "yuv410"
+	case SHM_FORMAT_YVU410:
+		return "yvu410"
+	case SHM_FORMAT_YUV411:
+		return "yuv411"
+	case SHM_FORMAT_YVU411:
+		return "yvu411"
+	case SHM_FORMAT_YUV420:
+		return "yuv420"
+	case SHM_FORMAT_YVU420:
+		return "yvu420"
+	case SHM_FORMAT_YUV422:
+		return "yuv422"
+	case SHM_FORMAT_YVU422:
+		return "yvu422"
+	case SHM_FORMAT_YUV444:
+		return "yuv444"
+	case SHM_FORMAT_YVU444:
+		return "yvu444"
+	}
+	return fmt.Sprintf("ShmFormat(%d)", uint32(e))
+}
+
+// Versions that introduced each request and event.
+const (
+	SHM_CREATE_POOL_SINCE_VERSION = 1
+	SHM_FORMAT_SINCE_VERSION      = 1
+)
+
+var ShmInterface = &proto.Interface{
+	Name:    "wl_shm",
+	Version: 1,
+	Requests: []proto.Signature{
+		{Name: "create_pool", Since: 1, Args: []proto.ArgDesc{
+			{Name: "id", Type: proto.ArgNewId, Interface: "wl_shm_pool", Nullable: false},
+			{Name: "fd", Type: proto.ArgFd, Interface: "", Nullable: false},
+			{Name: "size", Type: proto.ArgInt, Interface: "", Nullable: false},
+		}},
+	},
+	Events: []proto.Signature{
+		{Name: "format", Since: 1, Args: []proto.ArgDesc{
+			{Name: "format", Type: proto.ArgUint, Interface: "", Nullable: false},
+		}},
+	},
+}
+
+func init() {
+	proto.RegisterInterface(ShmInterface)
+}
+
+type ClientShmImplementation interface {
+
+	//
+	// Informs the client about a valid pixel format that
+	// can be used for buffers. Known formats include
+	// argb8888 and xrgb8888.
+	//
+	Format(format ShmFormat) error
+}
+
+// ClientShmListener implements ClientShmImplementation with a
+// function per event. Events whose function is nil are ignored, and fds
+// they carry are closed.
+type ClientShmListener struct {
+	OnFormat func(format ShmFormat) error
+}
+
+func (l ClientShmListener) Format(format ShmFormat) error {
+	if l.OnFormat == nil {
+		return nil
+	}
+	return l.OnFormat(format)
+}
+
+type ClientShm struct {
+	c       *proto.Conn
+	id      proto.ObjectId
+	version uint32
+	i       ClientShmImplementation
+}
+
+// NewShm creates an object at the given version, which is the
+// version it was bound at or the version of the object that created it.
+func (c Client) NewShm(version uint32, i ClientShmImplementation) *ClientShm {
+	return c.NewShmWithId(c.c.NextId(), version, i)
+}
+
+// NewShmWithId registers an object created by the other end
+// of the connection under the id it has chosen.
+func (c Client) NewShmWithId(id proto.ObjectId, version uint32, i ClientShmImplementation) *ClientShm {
+	o := &ClientShm{
+		c:       c.c,
+		id:      id,
+		version: version,
+		i:       i,
+	}
+	c.c.AddObject(o.id, o)
+	return o
+}
+
+func (o *ClientShm) Id() proto.ObjectId {
+	return o.id
+}
+
+func (o *ClientShm) Version() uint32 {
+	return o.version
+}
+
+func (o *ClientShm) Conn() *proto.Conn {
+	return o.c
+}
+
+func (o *ClientShm) Interface() *proto.Interface {
+	return ShmInterface
+}
+
+func (o *ClientShm) Handle(m *proto.Message) (err error) {
+	switch m.Opcode() {
+
+	case 0:
+
+		var (
+			format ShmFormat
+		)
+
+		var formatRaw uint32
+		if formatRaw, err = m.ReadUint(); err != nil {
+			return
+		}
+		format = ShmFormat(formatRaw)
+
+		return o.i.Format(format)
+
+	default:
+		return fmt.Errorf("Shm: invalid event opcode: %d", m.Opcode())
+	}
+}
+
+// BindShm binds global, which must be a wl_shm, at the
+// lower of its advertised version and maxVersion, and returns the proxy.
+// maxVersion is capped at 1, the version known to this package.
+func BindShm(registry proto.Registry, global proto.Global, maxVersion uint32, i ClientShmImplementation) (*ClientShm, error) {
+	if maxVersion > 1 {
+		maxVersion = 1
+	}
+	return proto.BindGlobal(registry, global, maxVersion, func(version uint32) *ClientShm {
+		return NewClient(registry.Conn()).NewShm(version, i)
+	})
+}
+
+// Create a new wl_shm_pool object.
+//
+// The pool can be used to create shared memory based buffer
+// objects.  The server will mmap size bytes of the passed file
+// descriptor, to use as backing memory for the pool.
+func (o *ClientShm) CreatePool(i ClientShmPoolImplementation, fd uintptr, size int32) (*ClientShmPool, error) {
+	obj := NewClient(o.c).NewShmPool(o.version, i)
+	m := proto.NewMessage(o.id, 0)
+
+	if err := m.WriteNewId(obj.Id()); err != nil {
+		o.c.DeleteObject(obj.Id())
+		return nil, err
+	}
+
+	if err := m.WriteFd(fd); err != nil {
+		o.c.DeleteObject(obj.Id())
+		return nil, err
+	}
+
+	if err := m.WriteInt(size); err != nil {
+		o.c.DeleteObject(obj.Id())
+		return nil, err
+	}
+
+	if err := o.c.WriteMessage(m); err != nil {
+		o.c.DeleteObject(obj.Id())
+		return nil, err
+	}
+	return obj, nil
+}
+
+type ServerShmImplementation interface {
+
+	//
+	// Create a new wl_shm_pool object.
+	//
+	// The pool can be used to create shared memory based buffer
+	// objects.  The server will mmap size bytes of the passed file
+	// descriptor, to use as backing memory for the pool.
+	//
+	CreatePool(id proto.ObjectId, fd uintptr, size int32) error
+}
+
+type ServerShm struct {
+	c       *proto.Conn
+	id      proto.ObjectId
+	version uint32
+	i       ServerShmImplementation
+}
+
+// NewShm creates an object at the given version, which is the
+// version it was bound at or the version of the object that created it.
+func (c Server) NewShm(version uint32, i ServerShmImplementation) *ServerShm {
+	return c.NewShmWithId(c.c.NextId(), version, i)
+}
+
+// NewShmWithId registers an object created by the other end
+// of the connection under the id it has chosen.
+func (c Server) NewShmWithId(id proto.ObjectId, version uint32, i ServerShmImplementation) *ServerShm {
+	o := &ServerShm{
+		c:       c.c,
+		id:      id,
+		version: version,
+		i:       i,
+	}
+	c.c.AddObject(o.id, o)
+	return o
+}
+
+func (o *ServerShm) Id() proto.ObjectId {
+	return o.id
+}
+
+func (o *ServerShm) Version() uint32 {
+	return o.version
+}
+
+func (o *ServerShm) Conn() *proto.Conn {
+	return o.c
+}
+
+func (o *ServerShm) Interface() *proto.Interface {
+	return ShmInterface
+}
+
+func (o *ServerShm) Handle(m *proto.Message) (err error) {
+	switch m.Opcode() {
+
+	case 0:
+
+		var (
+			id proto.ObjectId
+
+			fd uintptr
+
+			size int32
+		)
+
+		if id, err = m.ReadNewId(); err != nil {
+			return
+		}
+
+		if fd, err = m.ReadFd(); err != nil {
+			return
+		}
+
+		if size, err = m.ReadInt(); err != nil {
+			return
+		}
+
+		return o.i.CreatePool(id, fd, size)
+
+	default:
+		return fmt.Errorf("Shm: invalid request opcode: %d", m.Opcode())
+	}
+}
+
+// Informs the client about a valid pixel format that
+// can be used for buffers. Known formats include
+// argb8888 and xrgb8888.
+func (o *ServerShm) Format(format ShmFormat) error {
+	m := proto.NewMessage(o.id, 0)
+
+	if err := m.WriteUint(uint32(format)); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// Buffer
+//
+// A buffer provides the content for a wl_surface. Buffers are
+// created through factory interfaces such as wl_drm, wl_shm or
+// similar. It has a width and a height and can be attached to a
+// wl_surface, but the mechanism by which a client provides and
+// updates the contents is defined by the buffer factory interface.
+//
+
+// Versions that introduced each request and event.
+const (
+	BUFFER_DESTROY_SINCE_VERSION = 1
+	BUFFER_RELEASE_SINCE_VERSION = 1
+)
+
+var BufferInterface = &proto.Interface{
+	Name:    "wl_buffer",
+	Version: 1,
+	Requests: []proto.Signature{
+		{Name: "destroy", Since: 1, Args: []proto.ArgDesc{}},
+	},
+	Events: []proto.Signature{
+		{Name: "release", Since: 1, Args: []proto.ArgDesc{}},
+	},
+}
+
+func init() {
+	proto.RegisterInterface(BufferInterface)
+}
+
+type ClientBufferImplementation interface {
+
+	//
+	// Sent when this wl_buffer is no longer used by the compositor.
+	// The client is now free to re-use or destroy this buffer and its
+	// backing storage.
+	//
+	// If a client receives a release event before the frame callback
+	// requested in the same wl_surface.commit that attaches this
+	// wl_buffer to a surface, then the client is immediately free to
+	// re-use the buffer and its backing storage, and does not need a
+	// second buffer for the next surface content update. Typically
+	// this is possible, when the compositor maintains a copy of the
+	// wl_surface contents, e.g. as a GL texture. This is an important
+	// optimization for GL(ES) compositors with wl_shm clients.
+	//
+	Release() error
+}
+
+// ClientBufferListener implements ClientBufferImplementation with a
+// function per event. Events whose function is nil are ignored, and fds
+// they carry are closed.
+type ClientBufferListener struct {
+	OnRelease func() error
+}
+
+func (l ClientBufferListener) Release() error {
+	if l.OnRelease == nil {
+		return nil
+	}
+	return l.OnRelease()
+}
+
+type ClientBuffer struct {
+	c         *proto.Conn
+	id        proto.ObjectId
+	version   uint32
+	i         ClientBufferImplementation
+	destroyed bool
+}
+
+// NewBuffer creates an object at the given version, which is the
+// version it was bound at or the version of the object that created it.
+func (c Client) NewBuffer(version uint32, i ClientBufferImplementation) *ClientBuffer {
+	return c.NewBufferWithId(c.c.NextId(), version, i)
+}
+
+// NewBufferWithId registers an object created by the other end
+// of the connection under the id it has chosen.
+func (c Client) NewBufferWithId(id proto.ObjectId, version uint32, i ClientBufferImplementation) *ClientBuffer {
+	o := &ClientBuffer{
+		c:       c.c,
+		id:      id,
+		version: version,
+		i:       i,
+	}
+	c.c.AddObject(o.id, o)
+	return o
+}
+
+func (o *ClientBuffer) Id() proto.ObjectId {
+	return o.id
+}
+
+func (o *ClientBuffer) Version() uint32 {
+	return o.version
+}
+
+func (o *ClientBuffer) Conn() *proto.Conn {
+	return o.c
+}
+
+func (o *ClientBuffer) Interface() *proto.Interface {
+	return BufferInterface
+}
+
+func (o *ClientBuffer) Handle(m *proto.Message) (err error) {
+	switch m.Opcode() {
+
+	case 0:
+
+		var ()
+
+		return o.i.Release()
+
+	default:
+		return fmt.Errorf("Buffer: invalid event opcode: %d", m.Opcode())
+	}
+}
+
+// Destroy a buffer. If and how you need to release the backing
+// storage is defined by the buffer factory interface.
+//
+// For possible side-effects to a surface, see wl_surface.attach.
+func (o *ClientBuffer) Destroy() error {
+	if o.destroyed {
+		return fmt.Errorf("wl_buffer@%d.destroy: %w", o.id, proto.ErrDestroyed)
+	}
+	m := proto.NewMessage(o.id, 0)
+
+	if err := o.c.WriteMessage(m); err != nil {
+		return err
+	}
+	o.destroyed = true
+	return o.c.DestroyObject(o.id)
+}
+
+type ServerBufferImplementation interface {
+
+	//
+	// Destroy a buffer. If and how you need to release the backing
+	// storage is defined by the buffer factory interface.
+	//
+	// For possible side-effects to a surface, see wl_surface.attach.
+	//
+	Destroy() error
+}
+
+type ServerBuffer struct {
+	c         *proto.Conn
+	id        proto.ObjectId
+	version   uint32
+	i         ServerBufferImplementation
+	destroyed bool
+}
+
+// NewBuffer creates an object at the given version, which is the
+// version it was bound at or the version of the object that created it.
+func (c Server) NewBuffer(version uint32, i ServerBufferImplementation) *ServerBuffer {
+	return c.NewBufferWithId(c.c.NextId(), version, i)
+}
+
+// NewBufferWithId registers an object created by the other end
+// of the connection under the id it has chosen.
+func (c Server) NewBufferWithId(id proto.ObjectId, version uint32, i ServerBufferImplementation) *ServerBuffer {
+	o := &ServerBuffer{
+		c:       c.c,
+		id:      id,
+		version: version,
+		i:       i,
+	}
+	c.c.AddObject(o.id, o)
+	return o
+}
+
+func (o *ServerBuffer) Id() proto.ObjectId {
+	return o.id
+}
+
+func (o *ServerBuffer) Version() uint32 {
+	return o.version
+}
+
+func (o *ServerBuffer) Conn() *proto.Conn {
+	return o.c
+}
+
+func (o *ServerBuffer) Interface() *proto.Interface {
+	return BufferInterface
+}
+
+func (o *ServerBuffer) Handle(m *proto.Message) (err error) {
+	switch m.Opcode() {
+
+	case 0:
+
+		var ()
+
+		err = o.i.Destroy()
+		o.destroyed = true
+		if derr := o.c.DestroyObject(o.id); err == nil {
+			err = derr
+		}
+		return
+
+	default:
+		return fmt.Errorf("Buffer: invalid request opcode: %d", m.Opcode())
+	}
+}
+
+// Sent when this wl_buffer is no longer used by the compositor.
+// The client is now free to re-use or destroy this buffer and its
+// backing storage.
+//
+// If a client receives a release event before the frame callback
+// requested in the same wl_surface.commit that attaches this
+// wl_buffer to a surface, then the client is immediately free to
+// re-use the buffer and its backing storage, and does not need a
+// second buffer for the next surface content update. Typically
+// this is possible, when the compositor maintains a copy of the
+// wl_surface contents, e.g. as a GL texture. This is an important
+// optimization for GL(ES) compositors with wl_shm clients.
+func (o *ServerBuffer) Release() error {
+	if o.destroyed {
+		return fmt.Errorf("wl_buffer@%d.release: %w", o.id, proto.ErrDestroyed)
+	}
+	m := proto.NewMessage(o.id, 0)
+
+	return o.c.WriteMessage(m)
+}
+
+// DataOffer
+//
+// A wl_data_offer represents a piece of data offered for transfer
+// by another client (the source client).  It is used by the
+// copy-and-paste and drag-and-drop mechanisms.  The offer
+// describes the different mime types that the data can be
+// converted to and provides the mechanism for transferring the
+// data directly from the source client.
+//
+
+// Versions that introduced each request and event.
+const (
+	DATA_OFFER_ACCEPT_SINCE_VERSION  = 1
+	DATA_OFFER_RECEIVE_SINCE_VERSION = 1
+	DATA_OFFER_DESTROY_SINCE_VERSION = 1
+	DATA_OFFER_OFFER_SINCE_VERSION   = 1
+)
+
+var DataOfferInterface = &proto.Interface{
+	Name:    "wl_data_offer",
+	Version: 1,
+	Requests: []proto.Signature{
+		{Name: "accept", Since: 1, Args: []proto.ArgDesc{
+			{Name: "serial", Type: proto.ArgUint, Interface: "", Nullable: false},
+			{Name: "mime_type", Type: proto.ArgString, Interface: "", Nullable: true},
+		}},
+		{Name: "receive", Since: 1, Args: []proto.ArgDesc{
+			{Name: "mime_type", Type: proto.ArgString, Interface: "", Nullable: false},
+			{Name: "fd", Type: proto.ArgFd, Interface: "", Nullable: false},
+		}},
+		{Name: "destroy", Since: 1, Args: []proto.ArgDesc{}},
+	},
+	Events: []proto.Signature{
+		{Name: "offer", Since: 1, Args: []proto.ArgDesc{
+			{Name: "mime_type", Type: proto.ArgString, Interface: "", Nullable: false},
+		}},
+	},
+}
+
+func init() {
+	proto.RegisterInterface(DataOfferInterface)
+}
+
+type ClientDataOfferImplementation interface {
+
+	//
+	// Sent immediately after creating the wl_data_offer object.  One
+	// event per offered mime type.
+	//
+	Offer(mimeType string) error
+}
+
+// ClientDataOfferListener implements ClientDataOfferImplementation with a
+// function per event. Events whose function is nil are ignored, and fds
+// they carry are closed.
+type ClientDataOfferListener struct {
+	OnOffer func(mimeType string) error
+}
+
+func (l ClientDataOfferListener) Offer(mimeType string) error {
+	if l.OnOffer == nil {
+		return nil
+	}
+	return l.OnOffer(mimeType)
+}
+
+type ClientDataOffer struct {
+	c         *proto.Conn
+	id        proto.ObjectId
+	version   uint32
+	i         ClientDataOfferImplementation
+	destroyed bool
+}
+
+// NewDataOffer creates an object at the given version, which is the
+// version it was bound at or the version of the object that created it.
+func (c Client) NewDataOffer(version uint32, i ClientDataOfferImplementation) *ClientDataOffer {
+	return c.NewDataOfferWithId(c.c.NextId(), version, i)
+}
+
+// NewDataOfferWithId registers an object created by the other end
+// of the connection under the id it has chosen.
+func (c Client) NewDataOfferWithId(id proto.ObjectId, version uint32, i ClientDataOfferImplementation) *ClientDataOffer {
+	o := &ClientDataOffer{
+		c:       c.c,
+		id:      id,
+		version: version,
+		i:       i,
+	}
+	c.c.AddObject(o.id, o)
+	return o
+}
+
+func (o *ClientDataOffer) Id() proto.ObjectId {
+	return o.id
+}
+
+func (o *ClientDataOffer) Version() uint32 {
+	return o.version
+}
+
+func (o *ClientDataOffer) Conn() *proto.Conn {
+	return o.c
+}
+
+func (o *ClientDataOffer) Interface() *proto.Interface {
+	return DataOfferInterface
+}
+
+func (o *ClientDataOffer) Handle(m *proto.Message) (err error) {
+	switch m.Opcode() {
+
+	case 0:
+
+		var (
+			mimeType string
+		)
+
+		if mimeType, err = m.ReadString(); err != nil {
+			return
+		}
+
+		return o.i.Offer(mimeType)
+
+	default:
+		return fmt.Errorf("DataOffer: invalid event opcode: %d", m.Opcode())
+	}
+}
+
+// Indicate that the client can accept the given mime type, or
+// NULL for not accepted.
+//
+// Used for feedback during drag-and-drop.
+func (o *ClientDataOffer) Accept(serial uint32, mimeType string) error {
+	if o.destroyed {
+		return fmt.Errorf("wl_data_offer@%d.accept: %w", o.id, proto.ErrDestroyed)
+	}
+	m := proto.NewMessage(o.id, 0)
+
+	if err := m.WriteUint(serial); err != nil {
+		return err
+	}
+
+	if err := m.WriteString(mimeType); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// To transfer the offered data, the client issues this request
+// and indicates the mime type it wants to receive.  The transfer
+// happens through the passed file descriptor (typically created
+// with the pipe system call).  The source client writes the data
+// in the mime type representation requested and then closes the
+// file descriptor.
+//
+// The receiving client reads from the read end of the pipe until
+// EOF and the closes its end, at which point the transfer is
+// complete.
+func (o *ClientDataOffer) Receive(mimeType string, fd uintptr) error {
+	if o.destroyed {
+		return fmt.Errorf("wl_data_offer@%d.receive: %w", o.id, proto.ErrDestroyed)
+	}
+	m := proto.NewMessage(o.id, 1)
+
+	if err := m.WriteString(mimeType); err != nil {
+		return err
+	}
+
+	if err := m.WriteFd(fd); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// Destroy the data offer.
+func (o *ClientDataOffer) Destroy() error {
+	if o.destroyed {
+		return fmt.Errorf("wl_data_offer@%d.destroy: %w", o.id, proto.ErrDestroyed)
+	}
+	m := proto.NewMessage(o.id, 2)
+
+	if err := o.c.WriteMessage(m); err != nil {
+		return err
+	}
+	o.destroyed = true
+	return o.c.DestroyObject(o.id)
+}
+
+type ServerDataOfferImplementation interface {
+
+	//
+	// Indicate that the client can accept the given mime type, or
+	// NULL for not accepted.
+	//
+	// Used for feedback during drag-and-drop.
+	//
+	Accept(serial uint32, mimeType string) error
+
+	//
+	// To transfer the offered data, the client issues this request
+	// and indicates the mime type it wants to receive.  The transfer
+	// happens through the passed file descriptor (typically created
+	// with the pipe system call).  The source client writes the data
+	// in the mime type representation requested and then closes the
+	// file descriptor.
+	//
+	// The receiving client reads from the read end of the pipe until
+	// EOF and the closes its end, at which point the transfer is
+	// complete.
+	//
+	Receive(mimeType string, fd uintptr) error
+
+	//
+	// Destroy the data offer.
+	//
+	Destroy() error
+}
+
+type ServerDataOffer struct {
+	c         *proto.Conn
+	id        proto.ObjectId
+	version   uint32
+	i         ServerDataOfferImplementation
+	destroyed bool
+}
+
+// NewDataOffer creates an object at the given version, which is the
+// version it was bound at or the version of the object that created it.
+func (c Server) NewDataOffer(version uint32, i ServerDataOfferImplementation) *ServerDataOffer {
+	return c.NewDataOfferWithId(c.c.NextId(), version, i)
+}
+
+// NewDataOfferWithId registers an object created by the other end
+// of the connection under the id it has chosen.
+func (c Server) NewDataOfferWithId(id proto.ObjectId, version uint32, i ServerDataOfferImplementation) *ServerDataOffer {
+	o := &ServerDataOffer{
+		c:       c.c,
+		id:      id,
+		version: version,
+		i:       i,
+	}
+	c.c.AddObject(o.id, o)
+	return o
+}
+
+func (o *ServerDataOffer) Id() proto.ObjectId {
+	return o.id
+}
+
+func (o *ServerDataOffer) Version() uint32 {
+	return o.version
+}
+
+func (o *ServerDataOffer) Conn() *proto.Conn {
+	return o.c
+}
+
+func (o *ServerDataOffer) Interface() *proto.Interface {
+	return DataOfferInterface
+}
+
+func (o *ServerDataOffer) Handle(m *proto.Message) (err error) {
+	switch m.Opcode() {
+
+	case 0:
+
+		var (
+			serial uint32
+
+			mimeType string
+		)
+
+		if serial, err = m.ReadUint(); err != nil {
+			return
+		}
+
+		if mimeType, err = m.ReadString(); err != nil {
+			return
+		}
+
+		return o.i.Accept(serial, mimeType)
+
+	case 1:
+
+		var (
+			mimeType string
+
+			fd uintptr
+		)
+
+		if mimeType, err = m.ReadString(); err != nil {
+			return
+		}
+
+		if fd, err = m.ReadFd(); err != nil {
+			return
+		}
+
+		return o.i.Receive(mimeType, fd)
+
+	case 2:
+
+		var ()
+
+		err = o.i.Destroy()
+		o.destroyed = true
+		if derr := o.c.DestroyObject(o.id); err == nil {
+			err = derr
+		}
+		return
+
+	default:
+		return fmt.Errorf("DataOffer: invalid request opcode: %d", m.Opcode())
+	}
+}
+
+// Sent immediately after creating the wl_data_offer object.  One
+// event per offered mime type.
+func (o *ServerDataOffer) Offer(mimeType string) error {
+	if o.destroyed {
+		return fmt.Errorf("wl_data_offer@%d.offer: %w", o.id, proto.ErrDestroyed)
+	}
+	m := proto.NewMessage(o.id, 0)
+
+	if err := m.WriteString(mimeType); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// DataSource
+//
+// The wl_data_source object is the source side of a wl_data_offer.
+// It is created by the source client in a data transfer and
+// provides a way to describe the offered data and a way to respond
+// to requests to transfer the data.
+//
+
+// Versions that introduced each request and event.
+const (
+	DATA_SOURCE_OFFER_SINCE_VERSION     = 1
+	DATA_SOURCE_DESTROY_SINCE_VERSION   = 1
+	DATA_SOURCE_TARGET_SINCE_VERSION    = 1
+	DATA_SOURCE_SEND_SINCE_VERSION      = 1
+	DATA_SOURCE_CANCELLED_SINCE_VERSION = 1
+)
+
+var DataSourceInterface = &proto.Interface{
+	Name:    "wl_data_source",
+	Version: 1,
+	Requests: []proto.Signature{
+		{Name: "offer", Since: 1, Args: []proto.ArgDesc{
+			{Name: "mime_type", Type: proto.ArgString, Interface: "", Nullable: false},
+		}},
+		{Name: "destroy", Since: 1, Args: []proto.ArgDesc{}},
+	},
+	Events: []proto.Signature{
+		{Name: "target", Since: 1, Args: []proto.ArgDesc{
+			{Name: "mime_type", Type: proto.ArgString, Interface: "", Nullable: true},
+		}},
+		{Name: "send", Since: 1, Args: []proto.ArgDesc{
+			{Name: "mime_type", Type: proto.ArgString, Interface: "", Nullable: false},
+			{Name: "fd", Type: proto.ArgFd, Interface: "", Nullable: false},
+		}},
+		{Name: "cancelled", Since: 1, Args: []proto.ArgDesc{}},
+	},
+}
+
+func init() {
+	proto.RegisterInterface(DataSourceInterface)
+}
+
+type ClientDataSourceImplementation interface {
+
+	//
+	// Sent when a target accepts pointer_focus or motion events.  If
+	// a target does not accept any of the offered types, type is NULL.
+	//
+	// Used for feedback during drag-and-drop.
+	//
+	Target(mimeType string) error
+
+	//
+	// Request for data from the client.  Send the data as the
+	// specified mime type over the passed file descriptor, then
+	// close it.
+	//
+	Send(mimeType string, fd uintptr) error
+
+	//
+	// This data source has been replaced by another data source.
+	// The client should clean up and destroy this data source.
+	//
+	Cancelled() error
+}
+
+// ClientDataSourceListener implements ClientDataSourceImplementation with a
+// function per event. Events whose function is nil are ignored, and fds
+// they carry are closed.
+type ClientDataSourceListener struct {
+	OnTarget    func(mimeType string) error
+	OnSend      func(mimeType string, fd uintptr) error
+	OnCancelled func() error
+}
+
+func (l ClientDataSourceListener) Target(mimeType string) error {
+	if l.OnTarget == nil {
+		return nil
+	}
+	return l.OnTarget(mimeType)
+}
+
+func (l ClientDataSourceListener) Send(mimeType string, fd uintptr) error {
+	if l.OnSend == nil {
+		syscall.Close(int(fd))
+		return nil
+	}
+	return l.OnSend(mimeType, fd)
+}
+
+func (l ClientDataSourceListener) Cancelled() error {
+	if l.OnCancelled == nil {
+		return nil
+	}
+	return l.OnCancelled()
+}
+
+type ClientDataSource struct {
+	c         *proto.Conn
+	id        proto.ObjectId
+	version   uint32
+	i         ClientDataSourceImplementation
+	destroyed bool
+}
+
+// NewDataSource creates an object at the given version, which is the
+// version it was bound at or the version of the object that created it.
+func (c Client) NewDataSource(version uint32, i ClientDataSourceImplementation) *ClientDataSource {
+	return c.NewDataSourceWithId(c.c.NextId(), version, i)
+}
+
+// NewDataSourceWithId registers an object created by the other end
+// of the connection under the id it has chosen.
+func (c Client) NewDataSourceWithId(id proto.ObjectId, version uint32, i ClientDataSourceImplementation) *ClientDataSource {
+	o := &ClientDataSource{
+		c:       c.c,
+		id:      id,
+		version: version,
+		i:       i,
+	}
+	c.c.AddObject(o.id, o)
+	return o
+}
+
+func (o *ClientDataSource) Id() proto.ObjectId {
+	return o.id
+}
+
+func (o *ClientDataSource) Version() uint32 {
+	return o.version
+}
+
+func (o *ClientDataSource) Conn() *proto.Conn {
+	return o.c
+}
+
+func (o *ClientDataSource) Interface() *proto.Interface {
+	return DataSourceInterface
+}
+
+func (o *ClientDataSource) Handle(m *proto.Message) (err error) {
+	switch m.Opcode() {
+
+	case 0:
+
+		var (
+			mimeType string
+		)
+
+		if mimeType, err = m.ReadString(); err != nil {
+			return
+		}
+
+		return o.i.Target(mimeType)
+
+	case 1:
+
+		var (
+			mimeType string
+
+			fd uintptr
+		)
+
+		if mimeType, err = m.ReadString(); err != nil {
+			return
+		}
+
+		if fd, err = m.ReadFd(); err != nil {
+			return
+		}
+
+		return o.i.Send(mimeType, fd)
+
+	case 2:
+
+		var ()
+
+		return o.i.Cancelled()
+
+	default:
+		return fmt.Errorf("DataSource: invalid event opcode: %d", m.Opcode())
+	}
+}
+
+// This request adds a mime type to the set of mime types
+// advertised to targets.  Can be called several times to offer
+// multiple types.
+func (o *ClientDataSource) Offer(mimeType string) error {
+	if o.destroyed {
+		return fmt.Errorf("wl_data_source@%d.offer: %w", o.id, proto.ErrDestroyed)
+	}
+	m := proto.NewMessage(o.id, 0)
+
+	if err := m.WriteString(mimeType); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// Destroy the data source.
+func (o *ClientDataSource) Destroy() error {
+	if o.destroyed {
+		return fmt.Errorf("wl_data_source@%d.destroy: %w", o.id, proto.ErrDestroyed)
+	}
+	m := proto.NewMessage(o.id, 1)
+
+	if err := o.c.WriteMessage(m); err != nil {
+		return err
+	}
+	o.destroyed = true
+	return o.c.DestroyObject(o.id)
+}
+
+type ServerDataSourceImplementation interface {
+
+	//
+	// This request adds a mime type to the set of mime types
+	// advertised to targets.  Can be called several times to offer
+	// multiple types.
+	//
+	Offer(mimeType string) error
+
+	//
+	// Destroy the data source.
+	//
+	Destroy() error
+}
+
+type ServerDataSource struct {
+	c         *proto.Conn
+	id        proto.ObjectId
+	version   uint32
+	i         ServerDataSourceImplementation
+	destroyed bool
+}
+
+// NewDataSource creates an object at the given version, which is the
+// version it was bound at or the version of the object that created it.
+func (c Server) NewDataSource(version uint32, i ServerDataSourceImplementation) *ServerDataSource {
+	return c.NewDataSourceWithId(c.c.NextId(), version, i)
+}
+
+// NewDataSourceWithId registers an object created by the other end
+// of the connection under the id it has chosen.
+func (c Server) NewDataSourceWithId(id proto.ObjectId, version uint32, i ServerDataSourceImplementation) *ServerDataSource {
+	o := &ServerDataSource{
+		c:       c.c,
+		id:      id,
+		version: version,
+		i:       i,
+	}
+	c.c.AddObject(o.id, o)
+	return o
+}
+
+func (o *ServerDataSource) Id() proto.ObjectId {
+	return o.id
+}
+
+func (o *ServerDataSource) Version() uint32 {
+	return o.version
+}
+
+func (o *ServerDataSource) Conn() *proto.Conn {
+	return o.c
+}
+
+func (o *ServerDataSource) Interface() *proto.Interface {
+	return DataSourceInterface
+}
+
+func (o *ServerDataSource) Handle(m *proto.Message) (err error) {
+	switch m.Opcode() {
+
+	case 0:
+
+		var (
+			mimeType string
+		)
+
+		if mimeType, err = m.ReadString(); err != nil {
+			return
+		}
+
+		return o.i.Offer(mimeType)
+
+	case 1:
+
+		var ()
+
+		err = o.i.Destroy()
+		o.destroyed = true
+		if derr := o.c.DestroyObject(o.id); err == nil {
+			err = derr
+		}
+		return
+
+	default:
+		return fmt.Errorf("DataSource: invalid request opcode: %d", m.Opcode())
+	}
+}
+
+// Sent when a target accepts pointer_focus or motion events.  If
+// a target does not accept any of the offered types, type is NULL.
+//
+// Used for feedback during drag-and-drop.
+func (o *ServerDataSource) Target(mimeType string) error {
+	if o.destroyed {
+		return fmt.Errorf("wl_data_source@%d.target: %w", o.id, proto.ErrDestroyed)
+	}
+	m := proto.NewMessage(o.id, 0)
+
+	if err := m.WriteString(mimeType); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// Request for data from the client.  Send the data as the
+// specified mime type over the passed file descriptor, then
+// close it.
+func (o *ServerDataSource) Send(mimeType string, fd uintptr) error {
+	if o.destroyed {
+		return fmt.Errorf("wl_data_source@%d.send: %w", o.id, proto.ErrDestroyed)
+	}
+	m := proto.NewMessage(o.id, 1)
+
+	if err := m.WriteString(mimeType); err != nil {
+		return err
+	}
+
+	if err := m.WriteFd(fd); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// This data source has been replaced by another data source.
+// The client should clean up and destroy this data source.
+func (o *ServerDataSource) Cancelled() error {
+	if o.destroyed {
+		return fmt.Errorf("wl_data_source@%d.cancelled: %w", o.id, proto.ErrDestroyed)
+	}
+	m := proto.NewMessage(o.id, 2)
+
+	return o.c.WriteMessage(m)
+}
+
+// DataDevice
+//
+// There is one wl_data_device per seat which can be obtained
+// from the global wl_data_device_manager singleton.
+//
+// A wl_data_device provides access to inter-client data transfer
+// mechanisms such as copy-and-paste and drag-and-drop.
+//
+
+// Versions that introduced each request and event.
+const (
+	DATA_DEVICE_START_DRAG_SINCE_VERSION    = 1
+	DATA_DEVICE_SET_SELECTION_SINCE_VERSION = 1
+	DATA_DEVICE_DATA_OFFER_SINCE_VERSION    = 1
+	DATA_DEVICE_ENTER_SINCE_VERSION         = 1
+	DATA_DEVICE_LEAVE_SINCE_VERSION         = 1
+	DATA_DEVICE_MOTION_SINCE_VERSION        = 1
+	DATA_DEVICE_DROP_SINCE_VERSION          = 1
+	DATA_DEVICE_SELECTION_SINCE_VERSION     = 1
+)
+
+var DataDeviceInterface = &proto.Interface{
+	Name:    "wl_data_device",
+	Version: 1,
+	Requests: []proto.Signature{
+		{Name: "start_drag", Since: 1, Args: []proto.ArgDesc{
+			{Name: "source", Type: proto.ArgObject, Interface: "wl_data_source", Nullable: true},
+			{Name: "origin", Type: proto.ArgObject, Interface: "wl_surface", Nullable: false},
+			{Name: "icon", Type: proto.ArgObject, Interface: "wl_surface", Nullable: true},
+			{Name: "serial", Type: proto.ArgUint, Interface: "", Nullable: false},
+		}},
+		{Name: "set_selection", Since: 1, Args: []proto.ArgDesc{
+			{Name: "source", Type: proto.ArgObject, Interface: "wl_data_source", Nullable: true},
+			{Name: "serial", Type: proto.ArgUint, Interface: "", Nullable: false},
+		}},
+	},
+	Events: []proto.Signature{
+		{Name: "data_offer", Since: 1, Args: []proto.ArgDesc{
+			{Name: "id", Type: proto.ArgNewId, Interface: "wl_data_offer", Nullable: false},
+		}},
+		{Name: "enter", Since: 1, Args: []proto.ArgDesc{
+			{Name: "serial", Type: proto.ArgUint, Interface: "", Nullable: false},
+			{Name: "surface", Type: proto.ArgObject, Interface: "wl_surface", Nullable: false},
+			{Name: "x", Type: proto.ArgFixed, Interface: "", Nullable: false},
+			{Name: "y", Type: proto.ArgFixed, Interface: "", Nullable: false},
+			{Name: "id", Type: proto.ArgObject, Interface: "wl_data_offer", Nullable: true},
+		}},
+		{Name: "leave", Since: 1, Args: []proto.ArgDesc{}},
+		{Name: "motion", Since: 1, Args: []proto.ArgDesc{
+			{Name: "time", Type: proto.ArgUint, Interface: "", Nullable: false},
+			{Name: "x", Type: proto.ArgFixed, Interface: "", Nullable: false},
+			{Name: "y", Type: proto.ArgFixed, Interface: "", Nullable: false},
+		}},
+		{Name: "drop", Since: 1, Args: []proto.ArgDesc{}},
+		{Name: "selection", Since: 1, Args: []proto.ArgDesc{
+			{Name: "id", Type: proto.ArgObject, Interface: "wl_data_offer", Nullable: true},
+		}},
+	},
+}
+
+func init() {
+	proto.RegisterInterface(DataDeviceInterface)
+}
+
+type ClientDataDeviceImplementation interface {
+
+	//
+	// The data_offer event introduces a new wl_data_offer object,
+	// which will subsequently be used in either the
+	// data_device.enter event (for drag-and-drop) or the
+	// data_device.selection event (for selections).  Immediately
+	// following the data_device_data_offer event, the new data_offer
+	// object will send out data_offer.offer events to describe the
+	// mime types it offers.
+	//
+	DataOffer(id proto.ObjectId) error
+
+	//
+	// This event is sent when an active drag-and-drop pointer enters
+	// a surface owned by the client.  The position of the pointer at
+	// enter time is provided by the x and y arguments, in surface
+	// local coordinates.
+	//
+	Enter(serial uint32, surface *ClientSurface, x proto.Fixed, y proto.Fixed, id *ClientDataOffer) error
+
+	//
+	// This event is sent when the drag-and-drop pointer leaves the
+	// surface and the session ends.  The client must destroy the
+	// wl_data_offer introduced at enter time at this point.
+	//
+	Leave() error
+
+	//
+	// This event is sent when the drag-and-drop pointer moves within
+	// the currently focused surface. The new position of the pointer
+	// is provided by the x and y arguments, in surface local
+	// coordinates.
+	//
+	Motion(time uint32, x proto.Fixed, y proto.Fixed) error
+
+	//
+	// The event is sent when a drag-and-drop operation is ended
+	// because the implicit grab is removed.
+	//
+	Drop() error
+
+	//
+	// The selection event is sent out to notify the client of a new
+	// wl_data_offer for the selection for this device.  The
+	// data_device.data_offer and the data_offer.offer events are
+	// sent out immediately before this event to introduce the data
+	// offer object.  The selection event is sent to a client
+	// immediately before receiving keyboard focus and when a new
+	// selection is set while the client has keyboard focus.  The
+	// data_offer is valid until a new data_offer or NULL is received
+	// or until the client loses keyboard focus.
+	//
+	Selection(id *ClientDataOffer) error
+}
+
+// ClientDataDeviceListener implements ClientDataDeviceImplementation with a
+// function per event. Events whose function is nil are ignored, and fds
+// they carry are closed.
+type ClientDataDeviceListener struct {
+	OnDataOffer func(id proto.ObjectId) error
+	OnEnter     func(serial uint32, surface *ClientSurface, x proto.Fixed, y proto.Fixed, id *ClientDataOffer) error
+	OnLeave     func() error
+	OnMotion    func(time uint32, x proto.Fixed, y proto.Fixed) error
+	OnDrop      func() error
+	OnSelection func(id *ClientDataOffer) error
+}
+
+func (l ClientDataDeviceListener) DataOffer(id proto.ObjectId) error {
+	if l.OnDataOffer == nil {
+		return nil
+	}
+	return l.OnDataOffer(id)
+}
+
+func (l ClientDataDeviceListener) Enter(serial uint32, surface *ClientSurface, x proto.Fixed, y proto.Fixed, id *ClientDataOffer) error {
+	if l.OnEnter == nil {
+		return nil
+	}
+	return l.OnEnter(serial, surface, x, y, id)
+}
+
+func (l ClientDataDeviceListener) Leave() error {
+	if l.OnLeave == nil {
+		return nil
+	}
+	return l.OnLeave()
+}
+
+func (l ClientDataDeviceListener) Motion(time uint32, x proto.Fixed, y proto.Fixed) error {
+	if l.OnMotion == nil {
+		return nil
+	}
+	return l.OnMotion(time, x, y)
+}
+
+func (l ClientDataDeviceListener) Drop() error {
+	if l.OnDrop == nil {
+		return nil
+	}
+	return l.OnDrop()
+}
+
+func (l ClientDataDeviceListener) Selection(id *ClientDataOffer) error {
+	if l.OnSelection == nil {
+		return nil
+	}
+	return l.OnSelection(id)
+}
+
+type ClientDataDevice struct {
+	c       *proto.Conn
+	id      proto.ObjectId
+	version uint32
+	i       ClientDataDeviceImplementation
+}
+
+// NewDataDevice creates an object at the given version, which is the
+// version it was bound at or the version of the object that created it.
+func (c Client) NewDataDevice(version uint32, i ClientDataDeviceImplementation) *ClientDataDevice {
+	return c.NewDataDeviceWithId(c.c.NextId(), version, i)
+}
+
+// NewDataDeviceWithId registers an object created by the other end
+// of the connection under the id it has chosen.
+func (c Client) NewDataDeviceWithId(id proto.ObjectId, version uint32, i ClientDataDeviceImplementation) *ClientDataDevice {
+	o := &ClientDataDevice{
+		c:       c.c,
+		id:      id,
+		version: version,
+		i:       i,
+	}
+	c.c.AddObject(o.id, o)
+	return o
+}
+
+func (o *ClientDataDevice) Id() proto.ObjectId {
+	return o.id
+}
+
+func (o *ClientDataDevice) Version() uint32 {
+	return o.version
+}
+
+func (o *ClientDataDevice) Conn() *proto.Conn {
+	return o.c
+}
+
+func (o *ClientDataDevice) Interface() *proto.Interface {
+	return DataDeviceInterface
+}
+
+func (o *ClientDataDevice) Handle(m *proto.Message) (err error) {
+	switch m.Opcode() {
+
+	case 0:
+
+		var (
+			id proto.ObjectId
+		)
+
+		if id, err = m.ReadNewId(); err != nil {
+			return
+		}
+
+		return o.i.DataOffer(id)
+
+	case 1:
+
+		var (
+			serial uint32
+
+			surface *ClientSurface
+
+			x proto.Fixed
+
+			y proto.Fixed
+
+			id *ClientDataOffer
+		)
+
+		if serial, err = m.ReadUint(); err != nil {
+			return
+		}
+
+		var surfaceObj proto.Object
+		if surfaceObj, err = m.ReadObject(false); err != nil {
+			return fmt.Errorf("wl_data_device.enter: argument surface: %w", err)
+		}
+		if surfaceObj != nil {
+			var ok bool
+			if surface, ok = surfaceObj.(*ClientSurface); !ok {
+				return fmt.Errorf("wl_data_device.enter: argument surface: %T is not a wl_surface", surfaceObj)
+			}
+		}
+
+		if x, err = m.ReadFixed(); err != nil {
+			return
+		}
+
+		if y, err = m.ReadFixed(); err != nil {
+			return
+		}
+
+		var idObj proto.Object
+		if idObj, err = m.ReadObject(true); err != nil {
+			return fmt.Errorf("wl_data_device.enter: argument id: %w", err)
+		}
+		if idObj != nil {
+			var ok bool
+			if id, ok = idObj.(*ClientDataOffer); !ok {
+				return fmt.Errorf("wl_data_device.enter: argument id: %T is not a wl_data_offer", idObj)
+			}
+		}
+
+		return o.i.Enter(serial, surface, x, y, id)
+
+	case 2:
+
+		var ()
+
+		return o.i.Leave()
+
+	case 3:
+
+		var (
+			time uint32
+
+			x proto.Fixed
+
+			y proto.Fixed
+		)
+
+		if time, err = m.ReadUint(); err != nil {
+			return
+		}
+
+		if x, err = m.ReadFixed(); err != nil {
+			return
+		}
+
+		if y, err = m.ReadFixed(); err != nil {
+			return
+		}
+
+		return o.i.Motion(time, x, y)
+
+	case 4:
+
+		var ()
+
+		return o.i.Drop()
+
+	case 5:
+
+		var (
+			id *ClientDataOffer
+		)
+
+		var idObj proto.Object
+		if idObj, err = m.ReadObject(true); err != nil {
+			return fmt.Errorf("wl_data_device.selection: argument id: %w", err)
+		}
+		if idObj != nil {
+			var ok bool
+			if id, ok = idObj.(*ClientDataOffer); !ok {
+				return fmt.Errorf("wl_data_device.selection: argument id: %T is not a wl_data_offer", idObj)
+			}
+		}
+
+		return o.i.Selection(id)
+
+	default:
+		return fmt.Errorf("DataDevice: invalid event opcode: %d", m.Opcode())
+	}
+}
+
+// This request asks the compositor to start a drag-and-drop
+// operation on behalf of the client.
+//
+// The source argument is the data source that provides the data
+// for the eventual data transfer. If source is NULL, enter, leave
+// and motion events are sent only to the client that initiated the
+// drag and the client is expected to handle the data passing
+// internally.
+//
+// The origin surface is the surface where the drag originates and
+// the client must have an active implicit grab that matches the
+// serial.
+//
+// The icon surface is an optional (can be NULL) surface that
+// provides an icon to be moved around with the cursor.  Initially,
+// the top-left corner of the icon surface is placed at the cursor
+// hotspot, but subsequent wl_surface.attach request can move the
+// relative position. Attach requests must be confirmed with
+// wl_surface.commit as usual.
+//
+// The current and pending input regions of the icon wl_surface are
+// cleared, and wl_surface.set_input_region is ignored until the
+// wl_surface is no longer used as the icon surface. When the use
+// as an icon ends, the the current and pending input regions
+// become undefined, and the wl_surface is unmapped.
+func (o *ClientDataDevice) StartDrag(source *ClientDataSource, origin *ClientSurface, icon *ClientSurface, serial uint32) error {
+	var sourceId proto.ObjectId
+	if source != nil {
+		sourceId = source.Id()
+	}
+	var originId proto.ObjectId
+	if origin != nil {
+		originId = origin.Id()
+	} else {
+		return fmt.Errorf("wl_data_device.start_drag: argument origin is null")
+	}
+	var iconId proto.ObjectId
+	if icon != nil {
+		iconId = icon.Id()
+	}
+	m := proto.NewMessage(o.id, 0)
+
+	if err := m.WriteObjectId(sourceId); err != nil {
+		return err
+	}
+
+	if err := m.WriteObjectId(originId); err != nil {
+		return err
+	}
+
+	if err := m.WriteObjectId(iconId); err != nil {
+		return err
+	}
+
+	if err := m.WriteUint(serial); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// This request asks the compositor to set the selection
+// to the data from the source on behalf of the client.
+//
+// To unset the selection, set the source to NULL.
+func (o *ClientDataDevice) SetSelection(source *ClientDataSource, serial uint32) error {
+	var sourceId proto.ObjectId
+	if source != nil {
+		sourceId = source.Id()
+	}
+	m := proto.NewMessage(o.id, 1)
+
+	if err := m.WriteObjectId(sourceId); err != nil {
+		return err
+	}
+
+	if err := m.WriteUint(serial); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+type ServerDataDeviceImplementation interface {
+
+	//
+	// This request asks the compositor to start a drag-and-drop
+	// operation on behalf of the client.
+	//
+	// The source argument is the data source that provides the data
+	// for the eventual data transfer. If source is NULL, enter, leave
+	// and motion events are sent only to the client that initiated the
+	// drag and the client is expected to handle the data passing
+	// internally.
+	//
+	// The origin surface is the surface where the drag originates and
+	// the client must have an active implicit grab that matches the
+	// serial.
+	//
+	// The icon surface is an optional (can be NULL) surface that
+	// provides an icon to be moved around with the cursor.  Initially,
+	// the top-left corner of the icon surface is placed at the cursor
+	// hotspot, but subsequent wl_surface.attach request can move the
+	// relative position. Attach requests must be confirmed with
+	// wl_surface.commit as usual.
+	//
+	// The current and pending input regions of the icon wl_surface are
+	// cleared, and wl_surface.set_input_region is ignored until the
+	// wl_surface is no longer used as the icon surface. When the use
+	// as an icon ends, the the current and pending input regions
+	// become undefined, and the wl_surface is unmapped.
+	//
+	StartDrag(source *ServerDataSource, origin *ServerSurface, icon *ServerSurface, serial uint32) error
+
+	//
+	// This request asks the compositor to set the selection
+	// to the data from the source on behalf of the client.
+	//
+	// To unset the selection, set the source to NULL.
+	//
+	SetSelection(source *ServerDataSource, serial uint32) error
+}
+
+type ServerDataDevice struct {
+	c       *proto.Conn
+	id      proto.ObjectId
+	version uint32
+	i       ServerDataDeviceImplementation
+}
+
+// NewDataDevice creates an object at the given version, which is the
+// version it was bound at or the version of the object that created it.
+func (c Server) NewDataDevice(version uint32, i ServerDataDeviceImplementation) *ServerDataDevice {
+	return c.NewDataDeviceWithId(c.c.NextId(), version, i)
+}
+
+// NewDataDeviceWithId registers an object created by the other end
+// of the connection under the id it has chosen.
+func (c Server) NewDataDeviceWithId(id proto.ObjectId, version uint32, i ServerDataDeviceImplementation) *ServerDataDevice {
+	o := &ServerDataDevice{
+		c:       c.c,
+		id:      id,
+		version: version,
+		i:       i,
+	}
+	c.c.AddObject(o.id, o)
+	return o
+}
+
+func (o *ServerDataDevice) Id() proto.ObjectId {
+	return o.id
+}
+
+func (o *ServerDataDevice) Version() uint32 {
+	return o.version
+}
+
+func (o *ServerDataDevice) Conn() *proto.Conn {
+	return o.c
+}
+
+func (o *ServerDataDevice) Interface() *proto.Interface {
+	return DataDeviceInterface
+}
+
+func (o *ServerDataDevice) Handle(m *proto.Message) (err error) {
+	switch m.Opcode() {
+
+	case 0:
+
+		var (
+			source *ServerDataSource
+
+			origin *ServerSurface
+
+			icon *ServerSurface
+
+			serial uint32
+		)
+
+		var sourceObj proto.Object
+		if sourceObj, err = m.ReadObject(true); err != nil {
+			return fmt.Errorf("wl_data_device.start_drag: argument source: %w", err)
+		}
+		if sourceObj != nil {
+			var ok bool
+			if source, ok = sourceObj.(*ServerDataSource); !ok {
+				return fmt.Errorf("wl_data_device.start_drag: argument source: %T is not a wl_data_source", sourceObj)
+			}
+		}
+
+		var originObj proto.Object
+		if originObj, err = m.ReadObject(false); err != nil {
+			return fmt.Errorf("wl_data_device.start_drag: argument origin: %w", err)
+		}
+		if originObj != nil {
+			var ok bool
+			if origin, ok = originObj.(*ServerSurface); !ok {
+				return fmt.Errorf("wl_data_device.start_drag: argument origin: %T is not a wl_surface", originObj)
+			}
+		}
+
+		var iconObj proto.Object
+		if iconObj, err = m.ReadObject(true); err != nil {
+			return fmt.Errorf("wl_data_device.start_drag: argument icon: %w", err)
+		}
+		if iconObj != nil {
+			var ok bool
+			if icon, ok = iconObj.(*ServerSurface); !ok {
+				return fmt.Errorf("wl_data_device.start_drag: argument icon: %T is not a wl_surface", iconObj)
+			}
+		}
+
+		if serial, err = m.ReadUint(); err != nil {
+			return
+		}
+
+		return o.i.StartDrag(source, origin, icon, serial)
+
+	case 1:
+
+		var (
+			source *ServerDataSource
+
+			serial uint32
+		)
+
+		var sourceObj proto.Object
+		if sourceObj, err = m.ReadObject(true); err != nil {
+			return fmt.Errorf("wl_data_device.set_selection: argument source: %w", err)
+		}
+		if sourceObj != nil {
+			var ok bool
+			if source, ok = sourceObj.(*ServerDataSource); !ok {
+				return fmt.Errorf("wl_data_device.set_selection: argument source: %T is not a wl_data_source", sourceObj)
+			}
+		}
+
+		if serial, err = m.ReadUint(); err != nil {
+			return
+		}
+
+		return o.i.SetSelection(source, serial)
+
+	default:
+		return fmt.Errorf("DataDevice: invalid request opcode: %d", m.Opcode())
+	}
+}
+
+// The data_offer event introduces a new wl_data_offer object,
+// which will subsequently be used in either the
+// data_device.enter event (for drag-and-drop) or the
+// data_device.selection event (for selections).  Immediately
+// following the data_device_data_offer event, the new data_offer
+// object will send out data_offer.offer events to describe the
+// mime types it offers.
+func (o *ServerDataDevice) DataOffer(id proto.ObjectId) error {
+	m := proto.NewMessage(o.id, 0)
+
+	if err := m.WriteNewId(id); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// This event is sent when an active drag-and-drop pointer enters
+// a surface owned by the client.  The position of the pointer at
+// enter time is provided by the x and y arguments, in surface
+// local coordinates.
+func (o *ServerDataDevice) Enter(serial uint32, surface *ServerSurface, x proto.Fixed, y proto.Fixed, id *ServerDataOffer) error {
+	var surfaceId proto.ObjectId
+	if surface != nil {
+		surfaceId = surface.Id()
+	} else {
+		return fmt.Errorf("wl_data_device.enter: argument surface is null")
+	}
+	var idId proto.ObjectId
+	if id != nil {
+		idId = id.Id()
+	}
+	m := proto.NewMessage(o.id, 1)
+
+	if err := m.WriteUint(serial); err != nil {
+		return err
+	}
+
+	if err := m.WriteObjectId(surfaceId); err != nil {
+		return err
+	}
+
+	if err := m.WriteFixed(x); err != nil {
+		return err
+	}
+
+	if err := m.WriteFixed(y); err != nil {
+		return err
+	}
+
+	if err := m.WriteObjectId(idId); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// This event is sent when the drag-and-drop pointer leaves the
+// surface and the session ends.  The client must destroy the
+// wl_data_offer introduced at enter time at this point.
+func (o *ServerDataDevice) Leave() error {
+	m := proto.NewMessage(o.id, 2)
+
+	return o.c.WriteMessage(m)
+}
+
+// This event is sent when the drag-and-drop pointer moves within
+// the currently focused surface. The new position of the pointer
+// is provided by the x and y arguments, in surface local
+// coordinates.
+func (o *ServerDataDevice) Motion(time uint32, x proto.Fixed, y proto.Fixed) error {
+	m := proto.NewMessage(o.id, 3)
+
+	if err := m.WriteUint(time); err != nil {
+		return err
+	}
+
+	if err := m.WriteFixed(x); err != nil {
+		return err
+	}
+
+	if err := m.WriteFixed(y); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// The event is sent when a drag-and-drop operation is ended
+// because the implicit grab is removed.
+func (o *ServerDataDevice) Drop() error {
+	m := proto.NewMessage(o.id, 4)
+
+	return o.c.WriteMessage(m)
+}
+
+// The selection event is sent out to notify the client of a new
+// wl_data_offer for the selection for this device.  The
+// data_device.data_offer and the data_offer.offer events are
+// sent out immediately before this event to introduce the data
+// offer object.  The selection event is sent to a client
+// immediately before receiving keyboard focus and when a new
+// selection is set while the client has keyboard focus.  The
+// data_offer is valid until a new data_offer or NULL is received
+// or until the client loses keyboard focus.
+func (o *ServerDataDevice) Selection(id *ServerDataOffer) error {
+	var idId proto.ObjectId
+	if id != nil {
+		idId = id.Id()
+	}
+	m := proto.NewMessage(o.id, 5)
+
+	if err := m.WriteObjectId(idId); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// DataDeviceManager
+//
+// The wl_data_device_manager is a singleton global object that
+// provides access to inter-client data transfer mechanisms such as
+// copy-and-paste and drag-and-drop.  These mechanisms are tied to
+// a wl_seat and this interface lets a client get a wl_data_device
+// corresponding to a wl_seat.
+//
+
+// Versions that introduced each request and event.
+const (
+	DATA_DEVICE_MANAGER_CREATE_DATA_SOURCE_SINCE_VERSION = 1
+	DATA_DEVICE_MANAGER_GET_DATA_DEVICE_SINCE_VERSION    = 1
+)
+
+var DataDeviceManagerInterface = &proto.Interface{
+	Name:    "wl_data_device_manager",
+	Version: 1,
+	Requests: []proto.Signature{
+		{Name: "create_data_source", Since: 1, Args: []proto.ArgDesc{
+			{Name: "id", Type: proto.ArgNewId, Interface: "wl_data_source", Nullable: false},
+		}},
+		{Name: "get_data_device", Since: 1, Args: []proto.ArgDesc{
+			{Name: "id", Type: proto.ArgNewId, Interface: "wl_data_device", Nullable: false},
+			{Name: "seat", Type: proto.ArgObject, Interface: "wl_seat", Nullable: false},
+		}},
+	},
+	Events: []proto.Signature{},
+}
+
+func init() {
+	proto.RegisterInterface(DataDeviceManagerInterface)
+}
+
+type ClientDataDeviceManagerImplementation interface {
+}
+
+// ClientDataDeviceManagerListener implements ClientDataDeviceManagerImplementation with a
+// function per event. Events whose function is nil are ignored, and fds
+// they carry are closed.
+type ClientDataDeviceManagerListener struct {
+}
+
+type ClientDataDeviceManager struct {
+	c       *proto.Conn
+	id      proto.ObjectId
+	version uint32
+	i       ClientDataDeviceManagerImplementation
+}
+
+// NewDataDeviceManager creates an object at the given version, which is the
+// version it was bound at or the version of the object that created it.
+func (c Client) NewDataDeviceManager(version uint32, i ClientDataDeviceManagerImplementation) *ClientDataDeviceManager {
+	return c.NewDataDeviceManagerWithId(c.c.NextId(), version, i)
+}
+
+// NewDataDeviceManagerWithId registers an object created by the other end
+// of the connection under the id it has chosen.
+func (c Client) NewDataDeviceManagerWithId(id proto.ObjectId, version uint32, i ClientDataDeviceManagerImplementation) *ClientDataDeviceManager {
+	o := &ClientDataDeviceManager{
+		c:       c.c,
+		id:      id,
+		version: version,
+		i:       i,
+	}
+	c.c.AddObject(o.id, o)
+	return o
+}
+
+func (o *ClientDataDeviceManager) Id() proto.ObjectId {
+	return o.id
+}
+
+func (o *ClientDataDeviceManager) Version() uint32 {
+	return o.version
+}
+
+func (o *ClientDataDeviceManager) Conn() *proto.Conn {
+	return o.c
+}
+
+func (o *ClientDataDeviceManager) Interface() *proto.Interface {
+	return DataDeviceManagerInterface
+}
+
+func (o *ClientDataDeviceManager) Handle(m *proto.Message) (err error) {
+	switch m.Opcode() {
+
+	default:
+		return fmt.Errorf("DataDeviceManager: invalid event opcode: %d", m.Opcode())
+	}
+}
+
+// BindDataDeviceManager binds global, which must be a wl_data_device_manager, at the
+// lower of its advertised version and maxVersion, and returns the proxy.
+// maxVersion is capped at 1, the version known to this package.
+func BindDataDeviceManager(registry proto.Registry, global proto.Global, maxVersion uint32, i ClientDataDeviceManagerImplementation) (*ClientDataDeviceManager, error) {
+	if maxVersion > 1 {
+		maxVersion = 1
+	}
+	return proto.BindGlobal(registry, global, maxVersion, func(version uint32) *ClientDataDeviceManager {
+		return NewClient(registry.Conn()).NewDataDeviceManager(version, i)
+	})
+}
+
+// Create a new data source.
+func (o *ClientDataDeviceManager) CreateDataSource(i ClientDataSourceImplementation) (*ClientDataSource, error) {
+	obj := NewClient(o.c).NewDataSource(o.version, i)
+	m := proto.NewMessage(o.id, 0)
+
+	if err := m.WriteNewId(obj.Id()); err != nil {
+		o.c.DeleteObject(obj.Id())
+		return nil, err
+	}
+
+	if err := o.c.WriteMessage(m); err != nil {
+		o.c.DeleteObject(obj.Id())
+		return nil, err
+	}
+	return obj, nil
+}
+
+// Create a new data device for a given seat.
+func (o *ClientDataDeviceManager) GetDataDevice(i ClientDataDeviceImplementation, seat *ClientSeat) (*ClientDataDevice, error) {
+	var seatId proto.ObjectId
+	if seat != nil {
+		seatId = seat.Id()
+	} else {
+		return nil, fmt.Errorf("wl_data_device_manager.get_data_device: argument seat is null")
+	}
+	obj := NewClient(o.c).NewDataDevice(o.version, i)
+	m := proto.NewMessage(o.id, 1)
+
+	if err := m.WriteNewId(obj.Id()); err != nil {
+		o.c.DeleteObject(obj.Id())
+		return nil, err
+	}
+
+	if err := m.WriteObjectId(seatId); err != nil {
+		o.c.DeleteObject(obj.Id())
+		return nil, err
+	}
+
+	if err := o.c.WriteMessage(m); err != nil {
+		o.c.DeleteObject(obj.Id())
+		return nil, err
+	}
+	return obj, nil
+}
+
+type ServerDataDeviceManagerImplementation interface {
+
+	//
+	// Create a new data source.
+	//
+	CreateDataSource(id proto.ObjectId) error
+
+	//
+	// Create a new data device for a given seat.
+	//
+	GetDataDevice(id proto.ObjectId, seat *ServerSeat) error
+}
+
+type ServerDataDeviceManager struct {
+	c       *proto.Conn
+	id      proto.ObjectId
+	version uint32
+	i       ServerDataDeviceManagerImplementation
+}
+
+// NewDataDeviceManager creates an object at the given version, which is the
+// version it was bound at or the version of the object that created it.
+func (c Server) NewDataDeviceManager(version uint32, i ServerDataDeviceManagerImplementation) *ServerDataDeviceManager {
+	return c.NewDataDeviceManagerWithId(c.c.NextId(), version, i)
+}
+
+// NewDataDeviceManagerWithId registers an object created by the other end
+// of the connection under the id it has chosen.
+func (c Server) NewDataDeviceManagerWithId(id proto.ObjectId, version uint32, i ServerDataDeviceManagerImplementation) *ServerDataDeviceManager {
+	o := &ServerDataDeviceManager{
+		c:       c.c,
+		id:      id,
+		version: version,
+		i:       i,
+	}
+	c.c.AddObject(o.id, o)
+	return o
+}
+
+func (o *ServerDataDeviceManager) Id() proto.ObjectId {
+	return o.id
+}
+
+func (o *ServerDataDeviceManager) Version() uint32 {
+	return o.version
+}
+
+func (o *ServerDataDeviceManager) Conn() *proto.Conn {
+	return o.c
+}
+
+func (o *ServerDataDeviceManager) Interface() *proto.Interface {
+	return DataDeviceManagerInterface
+}
+
+func (o *ServerDataDeviceManager) Handle(m *proto.Message) (err error) {
+	switch m.Opcode() {
+
+	case 0:
+
+		var (
+			id proto.ObjectId
+		)
+
+		if id, err = m.ReadNewId(); err != nil {
+			return
+		}
+
+		return o.i.CreateDataSource(id)
+
+	case 1:
+
+		var (
+			id proto.ObjectId
+
+			seat *ServerSeat
+		)
+
+		if id, err = m.ReadNewId(); err != nil {
+			return
+		}
+
+		var seatObj proto.Object
+		if seatObj, err = m.ReadObject(false); err != nil {
+			return fmt.Errorf("wl_data_device_manager.get_data_device: argument seat: %w", err)
+		}
+		if seatObj != nil {
+			var ok bool
+			if seat, ok = seatObj.(*ServerSeat); !ok {
+				return fmt.Errorf("wl_data_device_manager.get_data_device: argument seat: %T is not a wl_seat", seatObj)
+			}
+		}
+
+		return o.i.GetDataDevice(id, seat)
+
+	default:
+		return fmt.Errorf("DataDeviceManager: invalid request opcode: %d", m.Opcode())
+	}
+}
+
+// Shell
+//
+// This interface is implemented by servers that provide
+// desktop-style user interfaces.
+//
+// It allows clients to associate a wl_shell_surface with
+// a basic surface.
+//
+
+// Versions that introduced each request and event.
+const (
+	SHELL_GET_SHELL_SURFACE_SINCE_VERSION = 1
+)
+
+var ShellInterface = &proto.Interface{
+	Name:    "wl_shell",
+	Version: 1,
+	Requests: []proto.Signature{
+		{Name: "get_shell_surface", Since: 1, Args: []proto.ArgDesc{
+			{Name: "id", Type: proto.ArgNewId, Interface: "wl_shell_surface", Nullable: false},
+			{Name: "surface", Type: proto.ArgObject, Interface: "wl_surface", Nullable: false},
+		}},
+	},
+	Events: []proto.Signature{},
+}
+
+func init() {
+	proto.RegisterInterface(ShellInterface)
+}
+
+type ClientShellImplementation interface {
+}
+
+// ClientShellListener implements ClientShellImplementation with a
+// function per event. Events whose function is nil are ignored, and fds
+// they carry are closed.
+type ClientShellListener struct {
+}
+
+type ClientShell struct {
+	c       *proto.Conn
+	id      proto.ObjectId
+	version uint32
+	i       ClientShellImplementation
+}
+
+// NewShell creates an object at the given version, which is the
+// version it was bound at or the version of the object that created it.
+func (c Client) NewShell(version uint32, i ClientShellImplementation) *ClientShell {
+	return c.NewShellWithId(c.c.NextId(), version, i)
+}
+
+// NewShellWithId registers an object created by the other end
+// of the connection under the id it has chosen.
+func (c Client) NewShellWithId(id proto.ObjectId, version uint32, i ClientShellImplementation) *ClientShell {
+	o := &ClientShell{
+		c:       c.c,
+		id:      id,
+		version: version,
+		i:       i,
+	}
+	c.c.AddObject(o.id, o)
+	return o
+}
+
+func (o *ClientShell) Id() proto.ObjectId {
+	return o.id
+}
+
+func (o *ClientShell) Version() uint32 {
+	return o.version
+}
+
+func (o *ClientShell) Conn() *proto.Conn {
+	return o.c
+}
+
+func (o *ClientShell) Interface() *proto.Interface {
+	return ShellInterface
+}
+
+func (o *ClientShell) Handle(m *proto.Message) (err error) {
+	switch m.Opcode() {
+
+	default:
+		return fmt.Errorf("Shell: invalid event opcode: %d", m.Opcode())
+	}
+}
+
+// BindShell binds global, which must be a wl_shell, at the
+// lower of its advertised version and maxVersion, and returns the proxy.
+// maxVersion is capped at 1, the version known to this package.
+func BindShell(registry proto.Registry, global proto.Global, maxVersion uint32, i ClientShellImplementation) (*ClientShell, error) {
+	if maxVersion > 1 {
+		maxVersion = 1
+	}
+	return proto.BindGlobal(registry, global, maxVersion, func(version uint32) *ClientShell {
+		return NewClient(registry.Conn()).NewShell(version, i)
+	})
+}
+
+// Create a shell surface for an existing surface.
+//
+// Only one shell surface can be associated with a given surface.
+func (o *ClientShell) GetShellSurface(i ClientShellSurfaceImplementation, surface *ClientSurface) (*ClientShellSurface, error) {
+	var surfaceId proto.ObjectId
+	if surface != nil {
+		surfaceId = surface.Id()
+	} else {
+		return nil, fmt.Errorf("wl_shell.get_shell_surface: argument surface is null")
+	}
+	obj := NewClient(o.c).NewShellSurface(o.version, i)
+	m := proto.NewMessage(o.id, 0)
+
+	if err := m.WriteNewId(obj.Id()); err != nil {
+		o.c.DeleteObject(obj.Id())
+		return nil, err
+	}
+
+	if err := m.WriteObjectId(surfaceId); err != nil {
+		o.c.DeleteObject(obj.Id())
+		return nil, err
+	}
+
+	if err := o.c.WriteMessage(m); err != nil {
+		o.c.DeleteObject(obj.Id())
+		return nil, err
+	}
+	return obj, nil
+}
+
+type ServerShellImplementation interface {
+
+	//
+	// Create a shell surface for an existing surface.
+	//
+	// Only one shell surface can be associated with a given surface.
+	//
+	GetShellSurface(id proto.ObjectId, surface *ServerSurface) error
+}
+
+type ServerShell struct {
+	c       *proto.Conn
+	id      proto.ObjectId
+	version uint32
+	i       ServerShellImplementation
+}
+
+// NewShell creates an object at the given version, which is the
+// version it was bound at or the version of the object that created it.
+func (c Server) NewShell(version uint32, i ServerShellImplementation) *ServerShell {
+	return c.NewShellWithId(c.c.NextId(), version, i)
+}
+
+// NewShellWithId registers an object created by the other end
+// of the connection under the id it has chosen.
+func (c Server) NewShellWithId(id proto.ObjectId, version uint32, i ServerShellImplementation) *ServerShell {
+	o := &ServerShell{
+		c:       c.c,
+		id:      id,
+		version: version,
+		i:       i,
+	}
+	c.c.AddObject(o.id, o)
+	return o
+}
+
+func (o *ServerShell) Id() proto.ObjectId {
+	return o.id
+}
+
+func (o *ServerShell) Version() uint32 {
+	return o.version
+}
+
+func (o *ServerShell) Conn() *proto.Conn {
+	return o.c
+}
+
+func (o *ServerShell) Interface() *proto.Interface {
+	return ShellInterface
+}
+
+func (o *ServerShell) Handle(m *proto.Message) (err error) {
+	switch m.Opcode() {
+
+	case 0:
+
+		var (
+			id proto.ObjectId
+
+			surface *ServerSurface
+		)
+
+		if id, err = m.ReadNewId(); err != nil {
+			return
+		}
+
+		var surfaceObj proto.Object
+		if surfaceObj, err = m.ReadObject(false); err != nil {
+			return fmt.Errorf("wl_shell.get_shell_surface: argument surface: %w", err)
+		}
+		if surfaceObj != nil {
+			var ok bool
+			if surface, ok = surfaceObj.(*ServerSurface); !ok {
+				return fmt.Errorf("wl_shell.get_shell_surface: argument surface: %T is not a wl_surface", surfaceObj)
+			}
+		}
+
+		return o.i.GetShellSurface(id, surface)
+
+	default:
+		return fmt.Errorf("Shell: invalid request opcode: %d", m.Opcode())
+	}
+}
+
+// ShellSurface
+//
+// An interface that may be implemented by a wl_surface, for
+// implementations that provide a desktop-style user interface.
+//
+// It provides requests to treat surfaces like toplevel, fullscreen
+// or popup windows, move, resize or maximize them, associate
+// metadata like title and class, etc.
+//
+// On the server side the object is automatically destroyed when
+// the related wl_surface is destroyed.  On client side,
+// wl_shell_surface_destroy() must be called before destroying
+// the wl_surface object.
+//
+
+// These values are used to indicate which edge of a surface
+// is being dragged in a resize operation. The server may
+// use this information to adapt its behavior, e.g. choose
+// an appropriate cursor image.
+type ShellSurfaceResize uint32
+
+const (
+
+	//
+	SHELL_SURFACE_RESIZE_NONE ShellSurfaceResize = 0
+
+	//
+	SHELL_SURFACE_RESIZE_TOP ShellSurfaceResize = 1
+
+	//
+	SHELL_SURFACE_RESIZE_BOTTOM ShellSurfaceResize = 2
+
+	//
+	SHELL_SURFACE_RESIZE_LEFT ShellSurfaceResize = 4
+
+	//
+	SHELL_SURFACE_RESIZE_TOP_LEFT ShellSurfaceResize = 5
+
+	//
+	SHELL_SURFACE_RESIZE_BOTTOM_LEFT ShellSurfaceResize = 6
+
+	//
+	SHELL_SURFACE_RESIZE_RIGHT ShellSurfaceResize = 8
+
+	//
+	SHELL_SURFACE_RESIZE_TOP_RIGHT ShellSurfaceResize = 9
+
+	//
+	SHELL_SURFACE_RESIZE_BOTTOM_RIGHT ShellSurfaceResize = 10
+)
+
+// Has reports whether all flags set in v are set in e.
+func (e ShellSurfaceResize) Has(v ShellSurfaceResize) bool {
+	return e&v == v
+}
+
+// String returns the names of the flags set in e, separated by "|".
+func (e ShellSurfaceResize) String() string {
+	if e == 0 {
+		return "none"
+	}
+	s := ""
+	for _, f := range []struct {
+		v    ShellSurfaceResize
+		name string
+	}{
+		{SHELL_SURFACE_RESIZE_TOP, "top"},
+		{SHELL_SURFACE_RESIZE_BOTTOM, "bottom"},
+		{SHELL_SURFACE_RESIZE_LEFT, "left"},
+		{SHELL_SURFACE_RESIZE_TOP_LEFT, "top_left"},
+		{SHELL_SURFACE_RESIZE_BOTTOM_LEFT, "bottom_left"},
+		{SHELL_SURFACE_RESIZE_RIGHT, "right"},
+		{SHELL_SURFACE_RESIZE_TOP_RIGHT, "top_right"},
+		{SHELL_SURFACE_RESIZE_BOTTOM_RIGHT, "bottom_right"},
+	} {
+		if e&f.v == f.v {
+			if s != "" {
+				s += "|"
+			}
+			s += f.name
+			e &^= f.v
+		}
+	}
+	if e != 0 || s == "" {
+		if s != "" {
+			s += "|"
+		}
+		s += fmt.Sprintf("%#x", uint32(e))
+	}
+	return s
+}
+
+// These flags specify details of the expected behaviour
+// of transient surfaces. Used in the set_transient request.
+type ShellSurfaceTransient uint32
+
+const (
+
+	// do not set keyboard focus
+	SHELL_SURFACE_TRANSIENT_INACTIVE ShellSurfaceTransient = 0x1
+)
+
+// Has reports whether all flags set in v are set in e.
+func (e ShellSurfaceTransient) Has(v ShellSurfaceTransient) bool {
+	return e&v == v
+}
+
+// String returns the names of the flags set in e, separated by "|".
+func (e ShellSurfaceTransient) String() string {
+	s := ""
+	for _, f := range []struct {
+		v    ShellSurfaceTransient
+		name string
+	}{
+		{SHELL_SURFACE_TRANSIENT_INACTIVE, "inactive"},
+	} {
+		if e&f.v == f.v {
+			if s != "" {
+				s += "|"
+			}
+			s += f.name
+			e &^= f.v
+		}
+	}
+	if e != 0 || s == "" {
+		if s != "" {
+			s += "|"
+		}
+		s += fmt.Sprintf("%#x", uint32(e))
+	}
+	return s
+}
+
+// Hints to indicate to the compositor how to deal with a conflict
+// between the dimensions of the surface and the dimensions of the
+// output. The compositor is free to ignore this parameter.
+type ShellSurfaceFullscreenMethod uint32
+
+const (
+
+	// no preference, apply default policy
+	SHELL_SURFACE_FULLSCREEN_METHOD_DEFAULT ShellSurfaceFullscreenMethod = 0
+
+	// scale, preserve the surface's aspect ratio and center on output
+	SHELL_SURFACE_FULLSCREEN_METHOD_SCALE ShellSurfaceFullscreenMethod = 1
+
+	// switch output mode to the smallest mode that can fit the surface, add black borders to compensate size mismatch
+	SHELL_SURFACE_FULLSCREEN_METHOD_DRIVER ShellSurfaceFullscreenMethod = 2
+
+	// no upscaling, center on output and add black borders to compensate size mismatch
+	SHELL_SURFACE_FULLSCREEN_METHOD_FILL ShellSurfaceFullscreenMethod = 3
+)
+
+func (e ShellSurfaceFullscreenMethod) String() string {
+	switch e {
+	case SHELL_SURFACE_FULLSCREEN_METHOD_DEFAULT:
+		return "default"
+	case SHELL_SURFACE_FULLSCREEN_METHOD_SCALE:
+		return "scale"
+	case SHELL_SURFACE_FULLSCREEN_METHOD_DRIVER:
+		return "driver"
+	case SHELL_SURFACE_FULLSCREEN_METHOD_FILL:
+		return "fill"
+	}
+	return fmt.Sprintf("ShellSurfaceFullscreenMethod(%d)", uint32(e))
+}
+
+// Versions that introduced each request and event.
+const (
+	SHELL_SURFACE_PONG_SINCE_VERSION           = 1
+	SHELL_SURFACE_MOVE_SINCE_VERSION           = 1
+	SHELL_SURFACE_RESIZE_SINCE_VERSION         = 1
+	SHELL_SURFACE_SET_TOPLEVEL_SINCE_VERSION   = 1
+	SHELL_SURFACE_SET_TRANSIENT_SINCE_VERSION  = 1
+	SHELL_SURFACE_SET_FULLSCREEN_SINCE_VERSION = 1
+	SHELL_SURFACE_SET_POPUP_SINCE_VERSION      = 1
+	SHELL_SURFACE_SET_MAXIMIZED_SINCE_VERSION  = 1
+	SHELL_SURFACE_SET_TITLE_SINCE_VERSION      = 1
+	SHELL_SURFACE_SET_CLASS_SINCE_VERSION      = 1
+	SHELL_SURFACE_PING_SINCE_VERSION           = 1
+	SHELL_SURFACE_CONFIGURE_SINCE_VERSION      = 1
+	SHELL_SURFACE_POPUP_DONE_SINCE_VERSION     = 1
+)
+
+var ShellSurfaceInterface = &proto.Interface{
+	Name:    "wl_shell_surface",
+	Version: 1,
+	Requests: []proto.Signature{
+		{Name: "pong", Since: 1, Args: []proto.ArgDesc{
+			{Name: "serial", Type: proto.ArgUint, Interface: "", Nullable: false},
+		}},
+		{Name: "move", Since: 1, Args: []proto.ArgDesc{
+			{Name: "seat", Type: proto.ArgObject, Interface: "wl_seat", Nullable: false},
+			{Name: "serial", Type: proto.ArgUint, Interface: "", Nullable: false},
+		}},
+		{Name: "resize", Since: 1, Args: []proto.ArgDesc{
+			{Name: "seat", Type: proto.ArgObject, Interface: "wl_seat", Nullable: false},
+			{Name: "serial", Type: proto.ArgUint, Interface: "", Nullable: false},
+			{Name: "edges", Type: proto.ArgUint, Interface: "", Nullable: false},
+		}},
+		{Name: "set_toplevel", Since: 1, Args: []proto.ArgDesc{}},
+		{Name: "set_transient", Since: 1, Args: []proto.ArgDesc{
+			{Name: "parent", Type: proto.ArgObject, Interface: "wl_surface", Nullable: false},
+			{Name: "x", Type: proto.ArgInt, Interface: "", Nullable: false},
+			{Name: "y", Type: proto.ArgInt, Interface: "", Nullable: false},
+			{Name: "flags", Type: proto.ArgUint, Interface: "", Nullable: false},
+		}},
+		{Name: "set_fullscreen", Since: 1, Args: []proto.ArgDesc{
+			{Name: "method", Type: proto.ArgUint, Interface: "", Nullable: false},
+			{Name: "framerate", Type: proto.ArgUint, Interface: "", Nullable: false},
+			{Name: "output", Type: proto.ArgObject, Interface: "wl_output", Nullable: true},
+		}},
+		{Name: "set_popup", Since: 1, Args: []proto.ArgDesc{
+			{Name: "seat", Type: proto.ArgObject, Interface: "wl_seat", Nullable: false},
+			{Name: "serial", Type: proto.ArgUint, Interface: "", Nullable: false},
+			{Name: "parent", Type: proto.ArgObject, Interface: "wl_surface", Nullable: false},
+			{Name: "x", Type: proto.ArgInt, Interface: "", Nullable: false},
+			{Name: "y", Type: proto.ArgInt, Interface: "", Nullable: false},
+			{Name: "flags", Type: proto.ArgUint, Interface: "", Nullable: false},
+		}},
+		{Name: "set_maximized", Since: 1, Args: []proto.ArgDesc{
+			{Name: "output", Type: proto.ArgObject, Interface: "wl_output", Nullable: true},
+		}},
+		{Name: "set_title", Since: 1, Args: []proto.ArgDesc{
+			{Name: "title", Type: proto.ArgString, Interface: "", Nullable: false},
+		}},
+		{Name: "set_class", Since: 1, Args: []proto.ArgDesc{
+			{Name: "class_", Type: proto.ArgString, Interface: "", Nullable: false},
+		}},
+	},
+	Events: []proto.Signature{
+		{Name: "ping", Since: 1, Args: []proto.ArgDesc{
+			{Name: "serial", Type: proto.ArgUint, Interface: "", Nullable: false},
+		}},
+		{Name: "configure", Since: 1, Args: []proto.ArgDesc{
+			{Name: "edges", Type: proto.ArgUint, Interface: "", Nullable: false},
+			{Name: "width", Type: proto.ArgInt, Interface: "", Nullable: false},
+			{Name: "height", Type: proto.ArgInt, Interface: "", Nullable: false},
+		}},
+		{Name: "popup_done", Since: 1, Args: []proto.ArgDesc{}},
+	},
+}
+
+func init() {
+	proto.RegisterInterface(ShellSurfaceInterface)
+}
+
+type ClientShellSurfaceImplementation interface {
+
+	//
+	// Ping a client to check if it is receiving events and sending
+	// requests. A client is expected to reply with a pong request.
+	//
+	Ping(serial uint32) error
+
+	//
+	// The configure event asks the client to resize its surface.
+	//
+	// The size is a hint, in the sense that the client is free to
+	// ignore it if it doesn't resize, pick a smaller size (to
+	// satisfy aspect ratio or resize in steps of NxM pixels).
+	//
+	// The edges parameter provides a hint about how the surface
+	// was resized. The client may use this information to decide
+	// how to adjust its content to the new size (e.g. a scrolling
+	// area might adjust its content position to leave the viewable
+	// content unmoved).
+	//
+	// The client is free to dismiss all but the last configure
+	// event it received.
+	//
+	// The width and height arguments specify the size of the window
+	// in surface local coordinates.
+	//
+	Configure(edges ShellSurfaceResize, width int32, height int32) error
+
+	//
+	// The popup_done event is sent out when a popup grab is broken,
+	// that is, when the user clicks a surface that doesn't belong
+	// to the client owning the popup surface.
+	//
+	PopupDone() error
+}
+
+// ClientShellSurfaceListener implements ClientShellSurfaceImplementation with a
+// function per event. Events whose function is nil are ignored, and fds
+// they carry are closed.
+type ClientShellSurfaceListener struct {
+	OnPing      func(serial uint32) error
+	OnConfigure func(edges ShellSurfaceResize, width int32, height int32) error
+	OnPopupDone func() error
+}
+
+func (l ClientShellSurfaceListener) Ping(serial uint32) error {
+	if l.OnPing == nil {
+		return nil
+	}
+	return l.OnPing(serial)
+}
+
+func (l ClientShellSurfaceListener) Configure(edges ShellSurfaceResize, width int32, height int32) error {
+	if l.OnConfigure == nil {
+		return nil
+	}
+	return l.OnConfigure(edges, width, height)
+}
+
+func (l ClientShellSurfaceListener) PopupDone() error {
+	if l.OnPopupDone == nil {
+		return nil
+	}
+	return l.OnPopupDone()
+}
+
+type ClientShellSurface struct {
+	c       *proto.Conn
+	id      proto.ObjectId
+	version uint32
+	i       ClientShellSurfaceImplementation
+}
+
+// NewShellSurface creates an object at the given version, which is the
+// version it was bound at or the version of the object that created it.
+func (c Client) NewShellSurface(version uint32, i ClientShellSurfaceImplementation) *ClientShellSurface {
+	return c.NewShellSurfaceWithId(c.c.NextId(), version, i)
+}
+
+// NewShellSurfaceWithId registers an object created by the other end
+// of the connection under the id it has chosen.
+func (c Client) NewShellSurfaceWithId(id proto.ObjectId, version uint32, i ClientShellSurfaceImplementation) *ClientShellSurface {
+	o := &ClientShellSurface{
+		c:       c.c,
+		id:      id,
+		version: version,
+		i:       i,
+	}
+	c.c.AddObject(o.id, o)
+	return o
+}
+
+func (o *ClientShellSurface) Id() proto.ObjectId {
+	return o.id
+}
+
+func (o *ClientShellSurface) Version() uint32 {
+	return o.version
+}
+
+func (o *ClientShellSurface) Conn() *proto.Conn {
+	return o.c
+}
+
+func (o *ClientShellSurface) Interface() *proto.Interface {
+	return ShellSurfaceInterface
+}
+
+func (o *ClientShellSurface) Handle(m *proto.Message) (err error) {
+	switch m.Opcode() {
+
+	case 0:
+
+		var (
+			serial uint32
+		)
+
+		if serial, err = m.ReadUint(); err != nil {
+			return
+		}
+
+		return o.i.Ping(serial)
+
+	case 1:
+
+		var (
+			edges ShellSurfaceResize
+
+			width int32
+
+			height int32
+		)
+
+		var edgesRaw uint32
+		if edgesRaw, err = m.ReadUint(); err != nil {
+			return
+		}
+		edges = ShellSurfaceResize(edgesRaw)
+
+		if width, err = m.ReadInt(); err != nil {
+			return
+		}
+
+		if height, err = m.ReadInt(); err != nil {
+			return
+		}
+
+		return o.i.Configure(edges, width, height)
+
+	case 2:
+
+		var ()
+
+		return o.i.PopupDone()
+
+	default:
+		return fmt.Errorf("ShellSurface: invalid event opcode: %d", m.Opcode())
+	}
+}
+
+// A client must respond to a ping event with a pong request or
+// the client may be deemed unresponsive.
+func (o *ClientShellSurface) Pong(serial uint32) error {
+	m := proto.NewMessage(o.id, 0)
+
+	if err := m.WriteUint(serial); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// Start a pointer-driven move of the surface.
+//
+// This request must be used in response to a button press event.
+// The server may ignore move requests depending on the state of
+// the surface (e.g. fullscreen or maximized).
+func (o *ClientShellSurface) Move(seat *ClientSeat, serial uint32) error {
+	var seatId proto.ObjectId
+	if seat != nil {
+		seatId = seat.Id()
+	} else {
+		return fmt.Errorf("wl_shell_surface.move: argument seat is null")
+	}
+	m := proto.NewMessage(o.id, 1)
+
+	if err := m.WriteObjectId(seatId); err != nil {
+		return err
+	}
+
+	if err := m.WriteUint(serial); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// Start a pointer-driven resizing of the surface.
+//
+// This request must be used in response to a button press event.
+// The server may ignore resize requests depending on the state of
+// the surface (e.g. fullscreen or maximized).
+func (o *ClientShellSurface) Resize(seat *ClientSeat, serial uint32, edges ShellSurfaceResize) error {
+	var seatId proto.ObjectId
+	if seat != nil {
+		seatId = seat.Id()
+	} else {
+		return fmt.Errorf("wl_shell_surface.resize: argument seat is null")
+	}
+	m := proto.NewMessage(o.id, 2)
+
+	if err := m.WriteObjectId(seatId); err != nil {
+		return err
+	}
+
+	if err := m.WriteUint(serial); err != nil {
+		return err
+	}
+
+	if err := m.WriteUint(uint32(edges)); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// Map the surface as a toplevel surface.
+//
+// A toplevel surface is not fullscreen, maximized or transient.
+func (o *ClientShellSurface) SetToplevel() error {
+	m := proto.NewMessage(o.id, 3)
+
+	return o.c.WriteMessage(m)
+}
+
+// Map the surface relative to an existing surface.
+//
+// The x and y arguments specify the locations of the upper left
+// corner of the surface relative to the upper left corner of the
+// parent surface, in surface local coordinates.
+//
+// The flags argument controls details of the transient behaviour.
+func (o *ClientShellSurface) SetTransient(parent *ClientSurface, x int32, y int32, flags ShellSurfaceTransient) error {
+	var parentId proto.ObjectId
+	if parent != nil {
+		parentId = parent.Id()
+	} else {
+		return fmt.Errorf("wl_shell_surface.set_transient: argument parent is null")
+	}
+	m := proto.NewMessage(o.id, 4)
+
+	if err := m.WriteObjectId(parentId); err != nil {
+		return err
+	}
+
+	if err := m.WriteInt(x); err != nil {
+		return err
+	}
+
+	if err := m.WriteInt(y); err != nil {
+		return err
+	}
+
+	if err := m.WriteUint(uint32(flags)); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// Map the surface as a fullscreen surface.
+//
+// If an output parameter is given then the surface will be made
+// fullscreen on that output. If the client does not specify the
+// output then the compositor will apply its policy - usually
+// choosing the output on which the surface has the biggest surface
+// area.
+//
+// The client may specify a method to resolve a size conflict
+// between the output size and the surface size - this is provided
+// through the method parameter.
+//
+// The framerate parameter is used only when the method is set
+// to "driver", to indicate the preferred framerate. A value of 0
+// indicates that the app does not care about framerate.  The
+// framerate is specified in mHz, that is framerate of 60000 is 60Hz.
+//
+// A method of "scale" or "driver" implies a scaling operation of
+// the surface, either via a direct scaling operation or a change of
+// the output mode. This will override any kind of output scaling, so
+// that mapping a surface with a buffer size equal to the mode can
+// fill the screen independent of buffer_scale.
+//
+// A method of "fill" means we don't scale up the buffer, however
+// any output scale is applied. This means that you may run into
+// an edge case where the application maps a buffer with the same
+// size of the output mode but buffer_scale 1 (thus making a
+// surface larger than the output). In this case it is allowed to
+// downscale the results to fit the screen.
+//
+// The compositor must reply to this request with a configure event
+// with the dimensions for the output on which the surface will
+// be made fullscreen.
+func (o *ClientShellSurface) SetFullscreen(method ShellSurfaceFullscreenMethod, framerate uint32, output *ClientOutput) error {
+	var outputId proto.ObjectId
+	if output != nil {
+		outputId = output.Id()
+	}
+	m := proto.NewMessage(o.id, 5)
+
+	if err := m.WriteUint(uint32(method)); err != nil {
+		return err
+	}
+
+	if err := m.WriteUint(framerate); err != nil {
+		return err
+	}
+
+	if err := m.WriteObjectId(outputId); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// Map the surface as a popup.
+//
+// A popup surface is a transient surface with an added pointer
+// grab.
+//
+// An existing implicit grab will be changed to owner-events mode,
+// and the popup grab will continue after the implicit grab ends
+// (i.e. releasing the mouse button does not cause the popup to
+// be unmapped).
+//
+// The popup grab continues until the window is destroyed or a
+// mouse button is pressed in any other clients window. A click
+// in any of the clients surfaces is reported as normal, however,
+// clicks in other clients surfaces will be discarded and trigger
+// the callback.
+//
+// The x and y arguments specify the locations of the upper left
+// corner of the surface relative to the upper left corner of the
+// parent surface, in surface local coordinates.
+func (o *ClientShellSurface) SetPopup(seat *ClientSeat, serial uint32, parent *ClientSurface, x int32, y int32, flags ShellSurfaceTransient) error {
+	var seatId proto.ObjectId
+	if seat != nil {
+		seatId = seat.Id()
+	} else {
+		return fmt.Errorf("wl_shell_surface.set_popup: argument seat is null")
+	}
+	var parentId proto.ObjectId
+	if parent != nil {
+		parentId = parent.Id()
+	} else {
+		return fmt.Errorf("wl_shell_surface.set_popup: argument parent is null")
+	}
+	m := proto.NewMessage(o.id, 6)
+
+	if err := m.WriteObjectId(seatId); err != nil {
+		return err
+	}
+
+	if err := m.WriteUint(serial); err != nil {
+		return err
+	}
+
+	if err := m.WriteObjectId(parentId); err != nil {
+		return err
+	}
+
+	if err := m.WriteInt(x); err != nil {
+		return err
+	}
+
+	if err := m.WriteInt(y); err != nil {
+		return err
+	}
+
+	if err := m.WriteUint(uint32(flags)); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// Map the surface as a maximized surface.
+//
+// If an output parameter is given then the surface will be
+// maximized on that output. If the client does not specify the
+// output then the compositor will apply its policy - usually
+// choosing the output on which the surface has the biggest surface
+// area.
+//
+// The compositor will reply with a configure event telling
+// the expected new surface size. The operation is completed
+// on the next buffer attach to this surface.
+//
+// A maximized surface typically fills the entire output it is
+// bound to, except for desktop element such as panels. This is
+// the main difference between a maximized shell surface and a
+// fullscreen shell surface.
+//
+// The details depend on the compositor implementation.
+func (o *ClientShellSurface) SetMaximized(output *ClientOutput) error {
+	var outputId proto.ObjectId
+	if output != nil {
+		outputId = output.Id()
+	}
+	m := proto.NewMessage(o.id, 7)
+
+	if err := m.WriteObjectId(outputId); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// Set a short title for the surface.
+//
+// This string may be used to identify the surface in a task bar,
+// window list, or other user interface elements provided by the
+// compositor.
+//
+// The string must be encoded in UTF-8.
+func (o *ClientShellSurface) SetTitle(title string) error {
+	m := proto.NewMessage(o.id, 8)
+
+	if err := m.WriteString(title); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// Set a class for the surface.
+//
+// The surface class identifies the general class of applications
+// to which the surface belongs. A common convention is to use the
+// file name (or the full path if it is a non-standard location) of
+// the application's .desktop file as the class.
+func (o *ClientShellSurface) SetClass(class string) error {
+	m := proto.NewMessage(o.id, 9)
+
+	if err := m.WriteString(class); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+type ServerShellSurfaceImplementation interface {
+
+	//
+	// A client must respond to a ping event with a pong request or
+	// the client may be deemed unresponsive.
+	//
+	Pong(serial uint32) error
+
+	//
+	// Start a pointer-driven move of the surface.
+	//
+	// This request must be used in response to a button press event.
+	// The server may ignore move requests depending on the state of
+	// the surface (e.g. fullscreen or maximized).
+	//
+	Move(seat *ServerSeat, serial uint32) error
+
+	//
+	// Start a pointer-driven resizing of the surface.
+	//
+	// This request must be used in response to a button press event.
+	// The server may ignore resize requests depending on the state of
+	// the surface (e.g. fullscreen or maximized).
+	//
+	Resize(seat *ServerSeat, serial uint32, edges ShellSurfaceResize) error
+
+	//
+	// Map the surface as a toplevel surface.
+	//
+	// A toplevel surface is not fullscreen, maximized or transient.
+	//
+	SetToplevel() error
+
+	//
+	// Map the surface relative to an existing surface.
+	//
+	// The x and y arguments specify the locations of the upper left
+	// corner of the surface relative to the upper left corner of the
+	// parent surface, in surface local coordinates.
+	//
+	// The flags argument controls details of the transient behaviour.
+	//
+	SetTransient(parent *ServerSurface, x int32, y int32, flags ShellSurfaceTransient) error
+
+	//
+	// Map the surface as a fullscreen surface.
+	//
+	// If an output parameter is given then the surface will be made
+	// fullscreen on that output. If the client does not specify the
+	// output then the compositor will apply its policy - usually
+	// choosing the output on which the surface has the biggest surface
+	// area.
+	//
+	// The client may specify a method to resolve a size conflict
+	// between the output size and the surface size - this is provided
+	// through the method parameter.
+	//
+	// The framerate parameter is used only when the method is set
+	// to "driver", to indicate the preferred framerate. A value of 0
+	// indicates that the app does not care about framerate.  The
+	// framerate is specified in mHz, that is framerate of 60000 is 60Hz.
+	//
+	// A method of "scale" or "driver" implies a scaling operation of
+	// the surface, either via a direct scaling operation or a change of
+	// the output mode. This will override any kind of output scaling, so
+	// that mapping a surface with a buffer size equal to the mode can
+	// fill the screen independent of buffer_scale.
+	//
+	// A method of "fill" means we don't scale up the buffer, however
+	// any output scale is applied. This means that you may run into
+	// an edge case where the application maps a buffer with the same
+	// size of the output mode but buffer_scale 1 (thus making a
+	// surface larger than the output). In this case it is allowed to
+	// downscale the results to fit the screen.
+	//
+	// The compositor must reply to this request with a configure event
+	// with the dimensions for the output on which the surface will
+	// be made fullscreen.
+	//
+	SetFullscreen(method ShellSurfaceFullscreenMethod, framerate uint32, output *ServerOutput) error
+
+	//
+	// Map the surface as a popup.
+	//
+	// A popup surface is a transient surface with an added pointer
+	// grab.
+	//
+	// An existing implicit grab will be changed to owner-events mode,
+	// and the popup grab will continue after the implicit grab ends
+	// (i.e. releasing the mouse button does not cause the popup to
+	// be unmapped).
+	//
+	// The popup grab continues until the window is destroyed or a
+	// mouse button is pressed in any other clients window. A click
+	// in any of the clients surfaces is reported as normal, however,
+	// clicks in other clients surfaces will be discarded and trigger
+	// the callback.
+	//
+	// The x and y arguments specify the locations of the upper left
+	// corner of the surface relative to the upper left corner of the
+	// parent surface, in surface local coordinates.
+	//
+	SetPopup(seat *ServerSeat, serial uint32, parent *ServerSurface, x int32, y int32, flags ShellSurfaceTransient) error
+
+	//
+	// Map the surface as a maximized surface.
+	//
+	// If an output parameter is given then the surface will be
+	// maximized on that output. If the client does not specify the
+	// output then the compositor will apply its policy - usually
+	// choosing the output on which the surface has the biggest surface
+	// area.
+	//
+	// The compositor will reply with a configure event telling
+	// the expected new surface size. The operation is completed
+	// on the next buffer attach to this surface.
+	//
+	// A maximized surface typically fills the entire output it is
+	// bound to, except for desktop element such as panels. This is
+	// the main difference between a maximized shell surface and a
+	// fullscreen shell surface.
+	//
+	// The details depend on the compositor implementation.
+	//
+	SetMaximized(output *ServerOutput) error
+
+	//
+	// Set a short title for the surface.
+	//
+	// This string may be used to identify the surface in a task bar,
+	// window list, or other user interface elements provided by the
+	// compositor.
+	//
+	// The string must be encoded in UTF-8.
+	//
+	SetTitle(title string) error
+
+	//
+	// Set a class for the surface.
+	//
+	// The surface class identifies the general class of applications
+	// to which the surface belongs. A common convention is to use the
+	// file name (or the full path if it is a non-standard location) of
+	// the application's .desktop file as the class.
+	//
+	SetClass(class string) error
+}
+
+type ServerShellSurface struct {
+	c       *proto.Conn
+	id      proto.ObjectId
+	version uint32
+	i       ServerShellSurfaceImplementation
+}
+
+// NewShellSurface creates an object at the given version, which is the
+// version it was bound at or the version of the object that created it.
+func (c Server) NewShellSurface(version uint32, i ServerShellSurfaceImplementation) *ServerShellSurface {
+	return c.NewShellSurfaceWithId(c.c.NextId(), version, i)
+}
+
+// NewShellSurfaceWithId registers an object created by the other end
+// of the connection under the id it has chosen.
+func (c Server) NewShellSurfaceWithId(id proto.ObjectId, version uint32, i ServerShellSurfaceImplementation) *ServerShellSurface {
+	o := &ServerShellSurface{
+		c:       c.c,
+		id:      id,
+		version: version,
+		i:       i,
+	}
+	c.c.AddObject(o.id, o)
+	return o
+}
+
+func (o *ServerShellSurface) Id() proto.ObjectId {
+	return o.id
+}
+
+func (o *ServerShellSurface) Version() uint32 {
+	return o.version
+}
+
+func (o *ServerShellSurface) Conn() *proto.Conn {
+	return o.c
+}
+
+func (o *ServerShellSurface) Interface() *proto.Interface {
+	return ShellSurfaceInterface
+}
+
+func (o *ServerShellSurface) Handle(m *proto.Message) (err error) {
+	switch m.Opcode() {
+
+	case 0:
+
+		var (
+			serial uint32
+		)
+
+		if serial, err = m.ReadUint(); err != nil {
+			return
+		}
+
+		return o.i.Pong(serial)
+
+	case 1:
+
+		var (
+			seat *ServerSeat
+
+			serial uint32
+		)
+
+		var seatObj proto.Object
+		if seatObj, err = m.ReadObject(false); err != nil {
+			return fmt.Errorf("wl_shell_surface.move: argument seat: %w", err)
+		}
+		if seatObj != nil {
+			var ok bool
+			if seat, ok = seatObj.(*ServerSeat); !ok {
+				return fmt.Errorf("wl_shell_surface.move: argument seat: %T is not a wl_seat", seatObj)
+			}
+		}
+
+		if serial, err = m.ReadUint(); err != nil {
+			return
+		}
+
+		return o.i.Move(seat, serial)
+
+	case 2:
+
+		var (
+			seat *ServerSeat
+
+			serial uint32
+
+			edges ShellSurfaceResize
+		)
+
+		var seatObj proto.Object
+		if seatObj, err = m.ReadObject(false); err != nil {
+			return fmt.Errorf("wl_shell_surface.resize: argument seat: %w", err)
+		}
+		if seatObj != nil {
+			var ok bool
+			if seat, ok = seatObj.(*ServerSeat); !ok {
+				return fmt.Errorf("wl_shell_surface.resize: argument seat: %T is not a wl_seat", seatObj)
+			}
+		}
+
+		if serial, err = m.ReadUint(); err != nil {
+			return
+		}
+
+		var edgesRaw uint32
+		if edgesRaw, err = m.ReadUint(); err != nil {
+			return
+		}
+		edges = ShellSurfaceResize(edgesRaw)
+
+		return o.i.Resize(seat, serial, edges)
+
+	case 3:
+
+		var ()
+
+		return o.i.SetToplevel()
+
+	case 4:
+
+		var (
+			parent *ServerSurface
+
+			x int32
+
+			y int32
+
+			flags ShellSurfaceTransient
+		)
+
+		var parentObj proto.Object
+		if parentObj, err = m.ReadObject(false); err != nil {
+			return fmt.Errorf("wl_shell_surface.set_transient: argument parent: %w", err)
+		}
+		if parentObj != nil {
+			var ok bool
+			if parent, ok = parentObj.(*ServerSurface); !ok {
+				return fmt.Errorf("wl_shell_surface.set_transient: argument parent: %T is not a wl_surface", parentObj)
+			}
+		}
+
+		if x, err = m.ReadInt(); err != nil {
+			return
+		}
+
+		if y, err = m.ReadInt(); err != nil {
+			return
+		}
+
+		var flagsRaw uint32
+		if flagsRaw, err = m.ReadUint(); err != nil {
+			return
+		}
+		flags = ShellSurfaceTransient(flagsRaw)
+
+		return o.i.SetTransient(parent, x, y, flags)
+
+	case 5:
+
+		var (
+			method ShellSurfaceFullscreenMethod
+
+			framerate uint32
+
+			output *ServerOutput
+		)
+
+		var methodRaw uint32
+		if methodRaw, err = m.ReadUint(); err != nil {
+			return
+		}
+		method = ShellSurfaceFullscreenMethod(methodRaw)
+
+		if framerate, err = m.ReadUint(); err != nil {
+			return
+		}
+
+		var outputObj proto.Object
+		if outputObj, err = m.ReadObject(true); err != nil {
+			return fmt.Errorf("wl_shell_surface.set_fullscreen: argument output: %w", err)
+		}
+		if outputObj != nil {
+			var ok bool
+			if output, ok = outputObj.(*ServerOutput); !ok {
+				return fmt.Errorf("wl_shell_surface.set_fullscreen: argument output: %T is not a wl_output", outputObj)
+			}
+		}
+
+		return o.i.SetFullscreen(method, framerate, output)
+
+	case 6:
+
+		var (
+			seat *ServerSeat
+
+			serial uint32
+
+			parent *ServerSurface
+
+			x int32
+
+			y int32
+
+			flags ShellSurfaceTransient
+		)
+
+		var seatObj proto.Object
+		if seatObj, err = m.ReadObject(false); err != nil {
+			return fmt.Errorf("wl_shell_surface.set_popup: argument seat: %w", err)
+		}
+		if seatObj != nil {
+			var ok bool
+			if seat, ok = seatObj.(*ServerSeat); !ok {
+				return fmt.Errorf("wl_shell_surface.set_popup: argument seat: %T is not a wl_seat", seatObj)
+			}
+		}
+
+		if serial, err = m.ReadUint(); err != nil {
+			return
+		}
+
+		var parentObj proto.Object
+		if parentObj, err = m.ReadObject(false); err != nil {
+			return fmt.Errorf("wl_shell_surface.set_popup: argument parent: %w", err)
+		}
+		if parentObj != nil {
+			var ok bool
+			if parent, ok = parentObj.(*ServerSurface); !ok {
+				return fmt.Errorf("wl_shell_surface.set_popup: argument parent: %T is not a wl_surface", parentObj)
+			}
+		}
+
+		if x, err = m.ReadInt(); err != nil {
+			return
+		}
+
+		if y, err = m.ReadInt(); err != nil {
+			return
+		}
+
+		var flagsRaw uint32
+		if flagsRaw, err = m.ReadUint(); err != nil {
+			return
+		}
+		flags = ShellSurfaceTransient(flagsRaw)
+
+		return o.i.SetPopup(seat, serial, parent, x, y, flags)
+
+	case 7:
+
+		var (
+			output *ServerOutput
+		)
+
+		var outputObj proto.Object
+		if outputObj, err = m.ReadObject(true); err != nil {
+			return fmt.Errorf("wl_shell_surface.set_maximized: argument output: %w", err)
+		}
+		if outputObj != nil {
+			var ok bool
+			if output, ok = outputObj.(*ServerOutput); !ok {
+				return fmt.Errorf("wl_shell_surface.set_maximized: argument output: %T is not a wl_output", outputObj)
+			}
+		}
+
+		return o.i.SetMaximized(output)
+
+	case 8:
+
+		var (
+			title string
+		)
+
+		if title, err = m.ReadString(); err != nil {
+			return
+		}
+
+		return o.i.SetTitle(title)
+
+	case 9:
+
+		var (
+			class string
+		)
+
+		if class, err = m.ReadString(); err != nil {
+			return
+		}
+
+		return o.i.SetClass(class)
+
+	default:
+		return fmt.Errorf("ShellSurface: invalid request opcode: %d", m.Opcode())
+	}
+}
+
+// Ping a client to check if it is receiving events and sending
+// requests. A client is expected to reply with a pong request.
+func (o *ServerShellSurface) Ping(serial uint32) error {
+	m := proto.NewMessage(o.id, 0)
+
+	if err := m.WriteUint(serial); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// The configure event asks the client to resize its surface.
+//
+// The size is a hint, in the sense that the client is free to
+// ignore it if it doesn't resize, pick a smaller size (to
+// satisfy aspect ratio or resize in steps of NxM pixels).
+//
+// The edges parameter provides a hint about how the surface
+// was resized. The client may use this information to decide
+// how to adjust its content to the new size (e.g. a scrolling
+// area might adjust its content position to leave the viewable
+// content unmoved).
+//
+// The client is free to dismiss all but the last configure
+// event it received.
+//
+// The width and height arguments specify the size of the window
+// in surface local coordinates.
+func (o *ServerShellSurface) Configure(edges ShellSurfaceResize, width int32, height int32) error {
+	m := proto.NewMessage(o.id, 1)
+
+	if err := m.WriteUint(uint32(edges)); err != nil {
+		return err
+	}
+
+	if err := m.WriteInt(width); err != nil {
+		return err
+	}
+
+	if err := m.WriteInt(height); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// The popup_done event is sent out when a popup grab is broken,
+// that is, when the user clicks a surface that doesn't belong
+// to the client owning the popup surface.
+func (o *ServerShellSurface) PopupDone() error {
+	m := proto.NewMessage(o.id, 2)
+
+	return o.c.WriteMessage(m)
+}
+
+// Surface
+//
+// A surface is a rectangular area that is displayed on the screen.
+// It has a location, size and pixel contents.
+//
+// The size of a surface (and relative positions on it) is described
+// in surface local coordinates, which may differ from the buffer
+// local coordinates of the pixel content, in case a buffer_transform
+// or a buffer_scale is used.
+//
+// Surfaces are also used for some special purposes, e.g. as
+// cursor images for pointers, drag icons, etc.
+//
+
+// Versions that introduced each request and event.
+const (
+	SURFACE_DESTROY_SINCE_VERSION              = 1
+	SURFACE_ATTACH_SINCE_VERSION               = 1
+	SURFACE_DAMAGE_SINCE_VERSION               = 1
+	SURFACE_FRAME_SINCE_VERSION                = 1
+	SURFACE_SET_OPAQUE_REGION_SINCE_VERSION    = 1
+	SURFACE_SET_INPUT_REGION_SINCE_VERSION     = 1
+	SURFACE_COMMIT_SINCE_VERSION               = 1
+	SURFACE_SET_BUFFER_TRANSFORM_SINCE_VERSION = 2
+	SURFACE_SET_BUFFER_SCALE_SINCE_VERSION     = 3
+	SURFACE_ENTER_SINCE_VERSION                = 1
+	SURFACE_LEAVE_SINCE_VERSION                = 1
+)
+
+var SurfaceInterface = &proto.Interface{
+	Name:    "wl_surface",
+	Version: 3,
+	Requests: []proto.Signature{
+		{Name: "destroy", Since: 1, Args: []proto.ArgDesc{}},
+		{Name: "attach", Since: 1, Args: []proto.ArgDesc{
+			{Name: "buffer", Type: proto.ArgObject, Interface: "wl_buffer", Nullable: true},
+			{Name: "x", Type: proto.ArgInt, Interface: "", Nullable: false},
+			{Name: "y", Type: proto.ArgInt, Interface: "", Nullable: false},
+		}},
+		{Name: "damage", Since: 1, Args: []proto.ArgDesc{
+			{Name: "x", Type: proto.ArgInt, Interface: "", Nullable: false},
+			{Name: "y", Type: proto.ArgInt, Interface: "", Nullable: false},
+			{Name: "width", Type: proto.ArgInt, Interface: "", Nullable: false},
+			{Name: "height", Type: proto.ArgInt, Interface: "", Nullable: false},
+		}},
+		{Name: "frame", Since: 1, Args: []proto.ArgDesc{
+			{Name: "callback", Type: proto.ArgNewId, Interface: "wl_callback", Nullable: false},
+		}},
+		{Name: "set_opaque_region", Since: 1, Args: []proto.ArgDesc{
+			{Name: "region", Type: proto.ArgObject, Interface: "wl_region", Nullable: true},
+		}},
+		{Name: "set_input_region", Since: 1, Args: []proto.ArgDesc{
+			{Name: "region", Type: proto.ArgObject, Interface: "wl_region", Nullable: true},
+		}},
+		{Name: "commit", Since: 1, Args: []proto.ArgDesc{}},
+		{Name: "set_buffer_transform", Since: 2, Args: []proto.ArgDesc{
+			{Name: "transform", Type: proto.ArgInt, Interface: "", Nullable: false},
+		}},
+		{Name: "set_buffer_scale", Since: 3, Args: []proto.ArgDesc{
+			{Name: "scale", Type: proto.ArgInt, Interface: "", Nullable: false},
+		}},
+	},
+	Events: []proto.Signature{
+		{Name: "enter", Since: 1, Args: []proto.ArgDesc{
+			{Name: "output", Type: proto.ArgObject, Interface: "wl_output", Nullable: false},
+		}},
+		{Name: "leave", Since: 1, Args: []proto.ArgDesc{
+			{Name: "output", Type: proto.ArgObject, Interface: "wl_output", Nullable: false},
+		}},
+	},
+}
+
+func init() {
+	proto.RegisterInterface(SurfaceInterface)
+}
+
+type ClientSurfaceImplementation interface {
+
+	//
+	// This is emitted whenever a surface's creation, movement, or resizing
+	// results in some part of it being within the scanout region of an
+	// output.
+	//
+	// Note that a surface may be overlapping with zero or more outputs.
+	//
+	Enter(output *ClientOutput) error
+
+	//
+	// This is emitted whenever a surface's creation, movement, or resizing
+	// results in it no longer having any part of it within the scanout region
+	// of an output.
+	//
+	Leave(output *ClientOutput) error
+}
+
+// ClientSurfaceListener implements ClientSurfaceImplementation with a
+// function per event. Events whose function is nil are ignored, and fds
+// they carry are closed.
+type ClientSurfaceListener struct {
+	OnEnter func(output *ClientOutput) error
+	OnLeave func(output *ClientOutput) error
+}
+
+func (l ClientSurfaceListener) Enter(output *ClientOutput) error {
+	if l.OnEnter == nil {
+		return nil
+	}
+	return l.OnEnter(output)
+}
+
+func (l ClientSurfaceListener) Leave(output *ClientOutput) error {
+	if l.OnLeave == nil {
+		return nil
+	}
+	return l.OnLeave(output)
+}
+
+type ClientSurface struct {
+	c         *proto.Conn
+	id        proto.ObjectId
+	version   uint32
+	i         ClientSurfaceImplementation
+	destroyed bool
+}
+
+// NewSurface creates an object at the given version, which is the
+// version it was bound at or the version of the object that created it.
+func (c Client) NewSurface(version uint32, i ClientSurfaceImplementation) *ClientSurface {
+	return c.NewSurfaceWithId(c.c.NextId(), version, i)
+}
+
+// NewSurfaceWithId registers an object created by the other end
+// of the connection under the id it has chosen.
+func (c Client) NewSurfaceWithId(id proto.ObjectId, version uint32, i ClientSurfaceImplementation) *ClientSurface {
+	o := &ClientSurface{
+		c:       c.c,
+		id:      id,
+		version: version,
+		i:       i,
+	}
+	c.c.AddObject(o.id, o)
+	return o
+}
+
+func (o *ClientSurface) Id() proto.ObjectId {
+	return o.id
+}
+
+func (o *ClientSurface) Version() uint32 {
+	return o.version
+}
+
+func (o *ClientSurface) Conn() *proto.Conn {
+	return o.c
+}
+
+func (o *ClientSurface) Interface() *proto.Interface {
+	return SurfaceInterface
+}
+
+func (o *ClientSurface) Handle(m *proto.Message) (err error) {
+	switch m.Opcode() {
+
+	case 0:
+
+		var (
+			output *ClientOutput
+		)
+
+		var outputObj proto.Object
+		if outputObj, err = m.ReadObject(false); err != nil {
+			return fmt.Errorf("wl_surface.enter: argument output: %w", err)
+		}
+		if outputObj != nil {
+			var ok bool
+			if output, ok = outputObj.(*ClientOutput); !ok {
+				return fmt.Errorf("wl_surface.enter: argument output: %T is not a wl_output", outputObj)
+			}
+		}
+
+		return o.i.Enter(output)
+
+	case 1:
+
+		var (
+			output *ClientOutput
+		)
+
+		var outputObj proto.Object
+		if outputObj, err = m.ReadObject(false); err != nil {
+			return fmt.Errorf("wl_surface.leave: argument output: %w", err)
+		}
+		if outputObj != nil {
+			var ok bool
+			if output, ok = outputObj.(*ClientOutput); !ok {
+				return fmt.Errorf("wl_surface.leave: argument output: %T is not a wl_output", outputObj)
+			}
+		}
+
+		return o.i.Leave(output)
+
+	default:
+		return fmt.Errorf("Surface: invalid event opcode: %d", m.Opcode())
+	}
+}
+
+// Deletes the surface and invalidates its object ID.
+func (o *ClientSurface) Destroy() error {
+	if o.destroyed {
+		return fmt.Errorf("wl_surface@%d.destroy: %w", o.id, proto.ErrDestroyed)
+	}
+	m := proto.NewMessage(o.id, 0)
+
+	if err := o.c.WriteMessage(m); err != nil {
+		return err
+	}
+	o.destroyed = true
+	return o.c.DestroyObject(o.id)
+}
+
+// Set a buffer as the content of this surface.
+//
+// The new size of the surface is calculated based on the buffer
+// size transformed by the inverse buffer_transform and the
+// inverse buffer_scale. This means that the supplied buffer
+// must be an integer multiple of the buffer_scale.
+//
+// The x and y arguments specify the location of the new pending
+// buffer's upper left corner, relative to the current buffer's upper
+// left corner, in surface local coordinates. In other words, the
+// x and y, combined with the new surface size define in which
+// directions the surface's size changes.
+//
+// Surface contents are double-buffered state, see wl_surface.commit.
+//
+// The initial surface contents are void; there is no content.
+// wl_surface.attach assigns the given wl_buffer as the pending
+// wl_buffer. wl_surface.commit makes the pending wl_buffer the new
+// surface contents, and the size of the surface becomes the size
+// calculated from the wl_buffer, as described above. After commit,
+// there is no pending buffer until the next attach.
+//
+// Committing a pending wl_buffer allows the compositor to read the
+// pixels in the wl_buffer. The compositor may access the pixels at
+// any time after the wl_surface.commit request. When the compositor
+// will not access the pixels anymore, it will send the
+// wl_buffer.release event. Only after receiving wl_buffer.release,
+// the client may re-use the wl_buffer. A wl_buffer that has been
+// attached and then replaced by another attach instead of committed
+// will not receive a release event, and is not used by the
+// compositor.
+//
+// Destroying the wl_buffer after wl_buffer.release does not change
+// the surface contents. However, if the client destroys the
+// wl_buffer before receiving the wl_buffer.release event, the surface
+// contents become undefined immediately.
+//
+// If wl_surface.attach is sent with a NULL wl_buffer, the
+// following wl_surface.commit will remove the surface content.
+func (o *ClientSurface) Attach(buffer *ClientBuffer, x int32, y int32) error {
+	if o.destroyed {
+		return fmt.Errorf("wl_surface@%d.attach: %w", o.id, proto.ErrDestroyed)
+	}
+	var bufferId proto.ObjectId
+	if buffer != nil {
+		bufferId = buffer.Id()
+	}
+	m := proto.NewMessage(o.id, 1)
+
+	if err := m.WriteObjectId(bufferId); err != nil {
+		return err
+	}
+
+	if err := m.WriteInt(x); err != nil {
+		return err
+	}
+
+	if err := m.WriteInt(y); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// This request is used to describe the regions where the pending
+// buffer is different from the current surface contents, and where
+// the surface therefore needs to be repainted. The pending buffer
+// must be set by wl_surface.attach before sending damage. The
+// compositor ignores the parts of the damage that fall outside of
+// the surface.
+//
+// Damage is double-buffered state, see wl_surface.commit.
+//
+// The damage rectangle is specified in surface local coordinates.
+//
+// The initial value for pending damage is empty: no damage.
+// wl_surface.damage adds pending damage: the new pending damage
+// is the union of old pending damage and the given rectangle.
+//
+// wl_surface.commit assigns pending damage as the current damage,
+// and clears pending damage. The server will clear the current
+// damage as it repaints the surface.
+func (o *ClientSurface) Damage(x int32, y int32, width int32, height int32) error {
+	if o.destroyed {
+		return fmt.Errorf("wl_surface@%d.damage: %w", o.id, proto.ErrDestroyed)
+	}
+	m := proto.NewMessage(o.id, 2)
+
+	if err := m.WriteInt(x); err != nil {
+		return err
+	}
+
+	if err := m.WriteInt(y); err != nil {
+		return err
+	}
+
+	if err := m.WriteInt(width); err != nil {
+		return err
+	}
+
+	if err := m.WriteInt(height); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// Request a notification when it is a good time start drawing a new
+// frame, by creating a frame callback. This is useful for throttling
+// redrawing operations, and driving animations.
+//
+// When a client is animating on a wl_surface, it can use the 'frame'
+// request to get notified when it is a good time to draw and commit the
+// next frame of animation. If the client commits an update earlier than
+// that, it is likely that some updates will not make it to the display,
+// and the client is wasting resources by drawing too often.
+//
+// The frame request will take effect on the next wl_surface.commit.
+// The notification will only be posted for one frame unless
+// requested again. For a wl_surface, the notifications are posted in
+// the order the frame requests were committed.
+//
+// The server must send the notifications so that a client
+// will not send excessive updates, while still allowing
+// the highest possible update rate for clients that wait for the reply
+// before drawing again. The server should give some time for the client
+// to draw and commit after sending the frame callback events to let them
+// hit the next output refresh.
+//
+// A server should avoid signalling the frame callbacks if the
+// surface is not visible in any way, e.g. the surface is off-screen,
+// or completely obscured by other opaque surfaces.
+//
+// The object returned by this request will be destroyed by the
+// compositor after the callback is fired and as such the client must not
+// attempt to use it after that point.
+//
+// The callback_data passed in the callback is the current time, in
+// milliseconds.
+func (o *ClientSurface) Frame(i ClientCallbackImplementation) (*ClientCallback, error) {
+	if o.destroyed {
+		return nil, fmt.Errorf("wl_surface@%d.frame: %w", o.id, proto.ErrDestroyed)
+	}
+	obj := NewClient(o.c).NewCallback(o.version, i)
+	m := proto.NewMessage(o.id, 3)
+
+	if err := m.WriteNewId(obj.Id()); err != nil {
+		o.c.DeleteObject(obj.Id())
+		return nil, err
+	}
+
+	if err := o.c.WriteMessage(m); err != nil {
+		o.c.DeleteObject(obj.Id())
+		return nil, err
+	}
+	return obj, nil
+}
+
+// This request sets the region of the surface that contains
+// opaque content.
+//
+// The opaque region is an optimization hint for the compositor
+// that lets it optimize out redrawing of content behind opaque
+// regions.  Setting an opaque region is not required for correct
+// behaviour, but marking transparent content as opaque will result
+// in repaint artifacts.
+//
+// The opaque region is specified in surface local coordinates.
+//
+// The compositor ignores the parts of the opaque region that fall
+// outside of the surface.
+//
+// Opaque region is double-buffered state, see wl_surface.commit.
+//
+// wl_surface.set_opaque_region changes the pending opaque region.
+// wl_surface.commit copies the pending region to the current region.
+// Otherwise, the pending and current regions are never changed.
+//
+// The initial value for opaque region is empty. Setting the pending
+// opaque region has copy semantics, and the wl_region object can be
+// destroyed immediately. A NULL wl_region causes the pending opaque
+// region to be set to empty.
+func (o *ClientSurface) SetOpaqueRegion(region *ClientRegion) error {
+	if o.destroyed {
+		return fmt.Errorf("wl_surface@%d.set_opaque_region: %w", o.id, proto.ErrDestroyed)
+	}
+	var regionId proto.ObjectId
+	if region != nil {
+		regionId = region.Id()
+	}
+	m := proto.NewMessage(o.id, 4)
+
+	if err := m.WriteObjectId(regionId); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// This request sets the region of the surface that can receive
+// pointer and touch events.
+//
+// Input events happening outside of this region will try the next
+// surface in the server surface stack. The compositor ignores the
+// parts of the input region that fall outside of the surface.
+//
+// The input region is specified in surface local coordinates.
+//
+// Input region is double-buffered state, see wl_surface.commit.
+//
+// wl_surface.set_input_region changes the pending input region.
+// wl_surface.commit copies the pending region to the current region.
+// Otherwise the pending and current regions are never changed,
+// except cursor and icon surfaces are special cases, see
+// wl_pointer.set_cursor and wl_data_device.start_drag.
+//
+// The initial value for input region is infinite. That means the
+// whole surface will accept input. Setting the pending input region
+// has copy semantics, and the wl_region object can be destroyed
+// immediately. A NULL wl_region causes the input region to be set
+// to infinite.
+func (o *ClientSurface) SetInputRegion(region *ClientRegion) error {
+	if o.destroyed {
+		return fmt.Errorf("wl_surface@%d.set_input_region: %w", o.id, proto.ErrDestroyed)
+	}
+	var regionId proto.ObjectId
+	if region != nil {
+		regionId = region.Id()
+	}
+	m := proto.NewMessage(o.id, 5)
+
+	if err := m.WriteObjectId(regionId); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// Surface state (input, opaque, and damage regions, attached buffers,
+// etc.) is double-buffered. Protocol requests modify the pending
+// state, as opposed to current state in use by the compositor. Commit
+// request atomically applies all pending state, replacing the current
+// state. After commit, the new pending state is as documented for each
+// related request.
+//
+// On commit, a pending wl_buffer is applied first, all other state
+// second. This means that all coordinates in double-buffered state are
+// relative to the new wl_buffer coming into use, except for
+// wl_surface.attach itself. If there is no pending wl_buffer, the
+// coordinates are relative to the current surface contents.
+//
+// All requests that need a commit to become effective are documented
+// to affect double-buffered state.
+//
+// Other interfaces may add further double-buffered surface state.
+func (o *ClientSurface) Commit() error {
+	if o.destroyed {
+		return fmt.Errorf("wl_surface@%d.commit: %w", o.id, proto.ErrDestroyed)
+	}
+	m := proto.NewMessage(o.id, 6)
+
+	return o.c.WriteMessage(m)
+}
+
+// This request sets an optional transformation on how the compositor
+// interprets the contents of the buffer attached to the surface. The
+// accepted values for the transform parameter are the values for
+// wl_output.transform.
+//
+// Buffer transform is double-buffered state, see wl_surface.commit.
+//
+// A newly created surface has its buffer transformation set to normal.
+//
+// wl_surface.set_buffer_transform changes the pending buffer
+// transformation. wl_surface.commit copies the pending buffer
+// transformation to the current one. Otherwise, the pending and current
+// values are never changed.
+//
+// The purpose of this request is to allow clients to render content
+// according to the output transform, thus permiting the compositor to
+// use certain optimizations even if the display is rotated. Using
+// hardware overlays and scanning out a client buffer for fullscreen
+// surfaces are examples of such optimizations. Those optimizations are
+// highly dependent on the compositor implementation, so the use of this
+// request should be considered on a case-by-case basis.
+//
+// Note that if the transform value includes 90 or 270 degree rotation,
+// the width of the buffer will become the surface height and the height
+// of the buffer will become the surface width.
+func (o *ClientSurface) SetBufferTransform(transform OutputTransform) error {
+	if o.destroyed {
+		return fmt.Errorf("wl_surface@%d.set_buffer_transform: %w", o.id, proto.ErrDestroyed)
+	}
+	if o.version < SURFACE_SET_BUFFER_TRANSFORM_SINCE_VERSION {
+		return &proto.VersionError{
+			ObjectId:  o.id,
+			Interface: "wl_surface",
+			Request:   "set_buffer_transform",
+			Since:     SURFACE_SET_BUFFER_TRANSFORM_SINCE_VERSION,
+			Version:   o.version,
+		}
+	}
+	m := proto.NewMessage(o.id, 7)
+
+	if err := m.WriteInt(int32(transform)); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// This request sets an optional scaling factor on how the compositor
+// interprets the contents of the buffer attached to the window.
+//
+// Buffer scale is double-buffered state, see wl_surface.commit.
+//
+// A newly created surface has its buffer scale set to 1.
+//
+// wl_surface.set_buffer_scale changes the pending buffer scale.
+// wl_surface.commit copies the pending buffer scale to the current one.
+// Otherwise, the pending and current values are never changed.
+//
+// The purpose of this request is to allow clients to supply higher
+// resolution buffer data for use on high resolution outputs. Its
+// intended that you pick the same	buffer scale as the scale of the
+// output that the surface is displayed on.This means the compositor
+// can avoid scaling when rendering the surface on that output.
+//
+// Note that if the scale is larger than 1, then you have to attach
+// a buffer that is larger (by a factor of scale in each dimension)
+// than the desired surface size.
+func (o *ClientSurface) SetBufferScale(scale int32) error {
+	if o.destroyed {
+		return fmt.Errorf("wl_surface@%d.set_buffer_scale: %w", o.id, proto.ErrDestroyed)
+	}
+	if o.version < SURFACE_SET_BUFFER_SCALE_SINCE_VERSION {
+		return &proto.VersionError{
+			ObjectId:  o.id,
+			Interface: "wl_surface",
+			Request:   "set_buffer_scale",
+			Since:     SURFACE_SET_BUFFER_SCALE_SINCE_VERSION,
+			Version:   o.version,
+		}
+	}
+	m := proto.NewMessage(o.id, 8)
+
+	if err := m.WriteInt(scale); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+type ServerSurfaceImplementation interface {
+
+	//
+	// Deletes the surface and invalidates its object ID.
+	//
+	Destroy() error
+
+	//
+	// Set a buffer as the content of this surface.
+	//
+	// The new size of the surface is calculated based on the buffer
+	// size transformed by the inverse buffer_transform and the
+	// inverse buffer_scale. This means that the supplied buffer
+	// must be an integer multiple of the buffer_scale.
+	//
+	// The x and y arguments specify the location of the new pending
+	// buffer's upper left corner, relative to the current buffer's upper
+	// left corner, in surface local coordinates. In other words, the
+	// x and y, combined with the new surface size define in which
+	// directions the surface's size changes.
+	//
+	// Surface contents are double-buffered state, see wl_surface.commit.
+	//
+	// The initial surface contents are void; there is no content.
+	// wl_surface.attach assigns the given wl_buffer as the pending
+	// wl_buffer. wl_surface.commit makes the pending wl_buffer the new
+	// surface contents, and the size of the surface becomes the size
+	// calculated from the wl_buffer, as described above. After commit,
+	// there is no pending buffer until the next attach.
+	//
+	// Committing a pending wl_buffer allows the compositor to read the
+	// pixels in the wl_buffer. The compositor may access the pixels at
+	// any time after the wl_surface.commit request. When the compositor
+	// will not access the pixels anymore, it will send the
+	// wl_buffer.release event. Only after receiving wl_buffer.release,
+	// the client may re-use the wl_buffer. A wl_buffer that has been
+	// attached and then replaced by another attach instead of committed
+	// will not receive a release event, and is not used by the
+	// compositor.
+	//
+	// Destroying the wl_buffer after wl_buffer.release does not change
+	// the surface contents. However, if the client destroys the
+	// wl_buffer before receiving the wl_buffer.release event, the surface
+	// contents become undefined immediately.
+	//
+	// If wl_surface.attach is sent with a NULL wl_buffer, the
+	// following wl_surface.commit will remove the surface content.
+	//
+	Attach(buffer *ServerBuffer, x int32, y int32) error
+
+	//
+	// This request is used to describe the regions where the pending
+	// buffer is different from the current surface contents, and where
+	// the surface therefore needs to be repainted. The pending buffer
+	// must be set by wl_surface.attach before sending damage. The
+	// compositor ignores the parts of the damage that fall outside of
+	// the surface.
+	//
+	// Damage is double-buffered state, see wl_surface.commit.
+	//
+	// The damage rectangle is specified in surface local coordinates.
+	//
+	// The initial value for pending damage is empty: no damage.
+	// wl_surface.damage adds pending damage: the new pending damage
+	// is the union of old pending damage and the given rectangle.
+	//
+	// wl_surface.commit assigns pending damage as the current damage,
+	// and clears pending damage. The server will clear the current
+	// damage as it repaints the surface.
+	//
+	Damage(x int32, y int32, width int32, height int32) error
+
+	//
+	// Request a notification when it is a good time start drawing a new
+	// frame, by creating a frame callback. This is useful for throttling
+	// redrawing operations, and driving animations.
+	//
+	// When a client is animating on a wl_surface, it can use the 'frame'
+	// request to get notified when it is a good time to draw and commit the
+	// next frame of animation. If the client commits an update earlier than
+	// that, it is likely that some updates will not make it to the display,
+	// and the client is wasting resources by drawing too often.
+	//
+	// The frame request will take effect on the next wl_surface.commit.
+	// The notification will only be posted for one frame unless
+	// requested again. For a wl_surface, the notifications are posted in
+	// the order the frame requests were committed.
+	//
+	// The server must send the notifications so that a client
+	// will not send excessive updates, while still allowing
+	// the highest possible update rate for clients that wait for the reply
+	// before drawing again. The server should give some time for the client
+	// to draw and commit after sending the frame callback events to let them
+	// hit the next output refresh.
+	//
+	// A server should avoid signalling the frame callbacks if the
+	// surface is not visible in any way, e.g. the surface is off-screen,
+	// or completely obscured by other opaque surfaces.
+	//
+	// The object returned by this request will be destroyed by the
+	// compositor after the callback is fired and as such the client must not
+	// attempt to use it after that point.
+	//
+	// The callback_data passed in the callback is the current time, in
+	// milliseconds.
+	//
+	Frame(callback proto.ObjectId) error
+
+	//
+	// This request sets the region of the surface that contains
+	// opaque content.
+	//
+	// The opaque region is an optimization hint for the compositor
+	// that lets it optimize out redrawing of content behind opaque
+	// regions.  Setting an opaque region is not required for correct
+	// behaviour, but marking transparent content as opaque will result
+	// in repaint artifacts.
+	//
+	// The opaque region is specified in surface local coordinates.
+	//
+	// The compositor ignores the parts of the opaque region that fall
+	// outside of the surface.
+	//
+	// Opaque region is double-buffered state, see wl_surface.commit.
+	//
+	// wl_surface.set_opaque_region changes the pending opaque region.
+	// wl_surface.commit copies the pending region to the current region.
+	// Otherwise, the pending and current regions are never changed.
+	//
+	// The initial value for opaque region is empty. Setting the pending
+	// opaque region has copy semantics, and the wl_region object can be
+	// destroyed immediately. A NULL wl_region causes the pending opaque
+	// region to be set to empty.
+	//
+	SetOpaqueRegion(region *ServerRegion) error
+
+	//
+	// This request sets the region of the surface that can receive
+	// pointer and touch events.
+	//
+	// Input events happening outside of this region will try the next
+	// surface in the server surface stack. The compositor ignores the
+	// parts of the input region that fall outside of the surface.
+	//
+	// The input region is specified in surface local coordinates.
+	//
+	// Input region is double-buffered state, see wl_surface.commit.
+	//
+	// wl_surface.set_input_region changes the pending input region.
+	// wl_surface.commit copies the pending region to the current region.
+	// Otherwise the pending and current regions are never changed,
+	// except cursor and icon surfaces are special cases, see
+	// wl_pointer.set_cursor and wl_data_device.start_drag.
+	//
+	// The initial value for input region is infinite. That means the
+	// whole surface will accept input. Setting the pending input region
+	// has copy semantics, and the wl_region object can be destroyed
+	// immediately. A NULL wl_region causes the input region to be set
+	// to infinite.
+	//
+	SetInputRegion(region *ServerRegion) error
+
+	//
+	// Surface state (input, opaque, and damage regions, attached buffers,
+	// etc.) is double-buffered. Protocol requests modify the pending
+	// state, as opposed to current state in use by the compositor. Commit
+	// request atomically applies all pending state, replacing the current
+	// state. After commit, the new pending state is as documented for each
+	// related request.
+	//
+	// On commit, a pending wl_buffer is applied first, all other state
+	// second. This means that all coordinates in double-buffered state are
+	// relative to the new wl_buffer coming into use, except for
+	// wl_surface.attach itself. If there is no pending wl_buffer, the
+	// coordinates are relative to the current surface contents.
+	//
+	// All requests that need a commit to become effective are documented
+	// to affect double-buffered state.
+	//
+	// Other interfaces may add further double-buffered surface state.
+	//
+	Commit() error
+
+	//
+	// This request sets an optional transformation on how the compositor
+	// interprets the contents of the buffer attached to the surface. The
+	// accepted values for the transform parameter are the values for
+	// wl_output.transform.
+	//
+	// Buffer transform is double-buffered state, see wl_surface.commit.
+	//
+	// A newly created surface has its buffer transformation set to normal.
+	//
+	// wl_surface.set_buffer_transform changes the pending buffer
+	// transformation. wl_surface.commit copies the pending buffer
+	// transformation to the current one. Otherwise, the pending and current
+	// values are never changed.
+	//
+	// The purpose of this request is to allow clients to render content
+	// according to the output transform, thus permiting the compositor to
+	// use certain optimizations even if the display is rotated. Using
+	// hardware overlays and scanning out a client buffer for fullscreen
+	// surfaces are examples of such optimizations. Those optimizations are
+	// highly dependent on the compositor implementation, so the use of this
+	// request should be considered on a case-by-case basis.
+	//
+	// Note that if the transform value includes 90 or 270 degree rotation,
+	// the width of the buffer will become the surface height and the height
+	// of the buffer will become the surface width.
+	//
+	SetBufferTransform(transform OutputTransform) error
+
+	//
+	// This request sets an optional scaling factor on how the compositor
+	// interprets the contents of the buffer attached to the window.
+	//
+	// Buffer scale is double-buffered state, see wl_surface.commit.
+	//
+	// A newly created surface has its buffer scale set to 1.
+	//
+	// wl_surface.set_buffer_scale changes the pending buffer scale.
+	// wl_surface.commit copies the pending buffer scale to the current one.
+	// Otherwise, the pending and current values are never changed.
+	//
+	// The purpose of this request is to allow clients to supply higher
+	// resolution buffer data for use on high resolution outputs. Its
+	// intended that you pick the same	buffer scale as the scale of the
+	// output that the surface is displayed on.This means the compositor
+	// can avoid scaling when rendering the surface on that output.
+	//
+	// Note that if the scale is larger than 1, then you have to attach
+	// a buffer that is larger (by a factor of scale in each dimension)
+	// than the desired surface size.
+	//
+	SetBufferScale(scale int32) error
+}
+
+type ServerSurface struct {
+	c         *proto.Conn
+	id        proto.ObjectId
+	version   uint32
+	i         ServerSurfaceImplementation
+	destroyed bool
+}
+
+// NewSurface creates an object at the given version, which is the
+// version it was bound at or the version of the object that created it.
+func (c Server) NewSurface(version uint32, i ServerSurfaceImplementation) *ServerSurface {
+	return c.NewSurfaceWithId(c.c.NextId(), version, i)
+}
+
+// NewSurfaceWithId registers an object created by the other end
+// of the connection under the id it has chosen.
+func (c Server) NewSurfaceWithId(id proto.ObjectId, version uint32, i ServerSurfaceImplementation) *ServerSurface {
+	o := &ServerSurface{
+		c:       c.c,
+		id:      id,
+		version: version,
+		i:       i,
+	}
+	c.c.AddObject(o.id, o)
+	return o
+}
+
+func (o *ServerSurface) Id() proto.ObjectId {
+	return o.id
+}
+
+func (o *ServerSurface) Version() uint32 {
+	return o.version
+}
+
+func (o *ServerSurface) Conn() *proto.Conn {
+	return o.c
+}
+
+func (o *ServerSurface) Interface() *proto.Interface {
+	return SurfaceInterface
+}
+
+func (o *ServerSurface) Handle(m *proto.Message) (err error) {
+	switch m.Opcode() {
+
+	case 0:
+
+		var ()
+
+		err = o.i.Destroy()
+		o.destroyed = true
+		if derr := o.c.DestroyObject(o.id); err == nil {
+			err = derr
+		}
+		return
+
+	case 1:
+
+		var (
+			buffer *ServerBuffer
+
+			x int32
+
+			y int32
+		)
+
+		var bufferObj proto.Object
+		if bufferObj, err = m.ReadObject(true); err != nil {
+			return fmt.Errorf("wl_surface.attach: argument buffer: %w", err)
+		}
+		if bufferObj != nil {
+			var ok bool
+			if buffer, ok = bufferObj.(*ServerBuffer); !ok {
+				return fmt.Errorf("wl_surface.attach: argument buffer: %T is not a wl_buffer", bufferObj)
+			}
+		}
+
+		if x, err = m.ReadInt(); err != nil {
+			return
+		}
+
+		if y, err = m.ReadInt(); err != nil {
+			return
+		}
+
+		return o.i.Attach(buffer, x, y)
+
+	case 2:
+
+		var (
+			x int32
+
+			y int32
+
+			width int32
+
+			height int32
+		)
+
+		if x, err = m.ReadInt(); err != nil {
+			return
+		}
+
+		if y, err = m.ReadInt(); err != nil {
+			return
+		}
+
+		if width, err = m.ReadInt(); err != nil {
+			return
+		}
+
+		if height, err = m.ReadInt(); err != nil {
+			return
+		}
+
+		return o.i.Damage(x, y, width, height)
+
+	case 3:
+
+		var (
+			callback proto.ObjectId
+		)
+
+		if callback, err = m.ReadNewId(); err != nil {
+			return
+		}
+
+		return o.i.Frame(callback)
+
+	case 4:
+
+		var (
+			region *ServerRegion
+		)
+
+		var regionObj proto.Object
+		if regionObj, err = m.ReadObject(true); err != nil {
+			return fmt.Errorf("wl_surface.set_opaque_region: argument region: %w", err)
+		}
+		if regionObj != nil {
+			var ok bool
+			if region, ok = regionObj.(*ServerRegion); !ok {
+				return fmt.Errorf("wl_surface.set_opaque_region: argument region: %T is not a wl_region", regionObj)
+			}
+		}
+
+		return o.i.SetOpaqueRegion(region)
+
+	case 5:
+
+		var (
+			region *ServerRegion
+		)
+
+		var regionObj proto.Object
+		if regionObj, err = m.ReadObject(true); err != nil {
+			return fmt.Errorf("wl_surface.set_input_region: argument region: %w", err)
+		}
+		if regionObj != nil {
+			var ok bool
+			if region, ok = regionObj.(*ServerRegion); !ok {
+				return fmt.Errorf("wl_surface.set_input_region: argument region: %T is not a wl_region", regionObj)
+			}
+		}
+
+		return o.i.SetInputRegion(region)
+
+	case 6:
+
+		var ()
+
+		return o.i.Commit()
+
+	case 7:
+
+		var (
+			transform OutputTransform
+		)
+
+		var transformRaw int32
+		if transformRaw, err = m.ReadInt(); err != nil {
+			return
+		}
+		transform = OutputTransform(transformRaw)
+
+		return o.i.SetBufferTransform(transform)
+
+	case 8:
+
+		var (
+			scale int32
+		)
+
+		if scale, err = m.ReadInt(); err != nil {
+			return
+		}
+
+		return o.i.SetBufferScale(scale)
+
+	default:
+		return fmt.Errorf("Surface: invalid request opcode: %d", m.Opcode())
+	}
+}
+
+// This is emitted whenever a surface's creation, movement, or resizing
+// results in some part of it being within the scanout region of an
+// output.
+//
+// Note that a surface may be overlapping with zero or more outputs.
+func (o *ServerSurface) Enter(output *ServerOutput) error {
+	if o.destroyed {
+		return fmt.Errorf("wl_surface@%d.enter: %w", o.id, proto.ErrDestroyed)
+	}
+	var outputId proto.ObjectId
+	if output != nil {
+		outputId = output.Id()
+	} else {
+		return fmt.Errorf("wl_surface.enter: argument output is null")
+	}
+	m := proto.NewMessage(o.id, 0)
+
+	if err := m.WriteObjectId(outputId); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// This is emitted whenever a surface's creation, movement, or resizing
+// results in it no longer having any part of it within the scanout region
+// of an output.
+func (o *ServerSurface) Leave(output *ServerOutput) error {
+	if o.destroyed {
+		return fmt.Errorf("wl_surface@%d.leave: %w", o.id, proto.ErrDestroyed)
+	}
+	var outputId proto.ObjectId
+	if output != nil {
+		outputId = output.Id()
+	} else {
+		return fmt.Errorf("wl_surface.leave: argument output is null")
+	}
+	m := proto.NewMessage(o.id, 1)
+
+	if err := m.WriteObjectId(outputId); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// Seat
+//
+// A seat is a group of keyboards, pointer and touch devices. This
+// object is published as a global during start up, or when such a
+// device is hot plugged.  A seat typically has a pointer and
+// maintains a keyboard focus and a pointer focus.
+//
+
+// This is a bitmask of capabilities this seat has; if a member is
+// set, then it is present on the seat.
+type SeatCapability uint32
+
+const (
+
+	// The seat has pointer devices
+	SEAT_CAPABILITY_POINTER SeatCapability = 1
+
+	// The seat has one or more keyboards
+	SEAT_CAPABILITY_KEYBOARD SeatCapability = 2
+
+	// The seat has touch devices
+	SEAT_CAPABILITY_TOUCH SeatCapability = 4
+)
+
+// Has reports whether all flags set in v are set in e.
+func (e SeatCapability) Has(v SeatCapability) bool {
+	return e&v == v
+}
+
+// String returns the names of the flags set in e, separated by "|".
+func (e SeatCapability) String() string {
+	s := ""
+	for _, f := range []struct {
+		v    SeatCapability
+		name string
+	}{
+		{SEAT_CAPABILITY_POINTER, "pointer"},
+		{SEAT_CAPABILITY_KEYBOARD, "keyboard"},
+		{SEAT_CAPABILITY_TOUCH, "touch"},
+	} {
+		if e&f.v == f.v {
+			if s != "" {
+				s += "|"
+			}
+			s += f.name
+			e &^= f.v
+		}
+	}
+	if e != 0 || s == "" {
+		if s != "" {
+			s += "|"
+		}
+		s += fmt.Sprintf("%#x", uint32(e))
+	}
+	return s
+}
+
+// Versions that introduced each request and event.
+const (
+	SEAT_GET_POINTER_SINCE_VERSION  = 1
+	SEAT_GET_KEYBOARD_SINCE_VERSION = 1
+	SEAT_GET_TOUCH_SINCE_VERSION    = 1
+	SEAT_CAPABILITIES_SINCE_VERSION = 1
+	SEAT_NAME_SINCE_VERSION         = 2
+)
+
+var SeatInterface = &proto.Interface{
+	Name:    "wl_seat",
+	Version: 3,
+	Requests: []proto.Signature{
+		{Name: "get_pointer", Since: 1, Args: []proto.ArgDesc{
+			{Name: "id", Type: proto.ArgNewId, Interface: "wl_pointer", Nullable: false},
+		}},
+		{Name: "get_keyboard", Since: 1, Args: []proto.ArgDesc{
+			{Name: "id", Type: proto.ArgNewId, Interface: "wl_keyboard", Nullable: false},
+		}},
+		{Name: "get_touch", Since: 1, Args: []proto.ArgDesc{
+			{Name: "id", Type: proto.ArgNewId, Interface: "wl_touch", Nullable: false},
+		}},
+	},
+	Events: []proto.Signature{
+		{Name: "capabilities", Since: 1, Args: []proto.ArgDesc{
+			{Name: "capabilities", Type: proto.ArgUint, Interface: "", Nullable: false},
+		}},
+		{Name: "name", Since: 2, Args: []proto.ArgDesc{
+			{Name: "name", Type: proto.ArgString, Interface: "", Nullable: false},
+		}},
+	},
+}
+
+func init() {
+	proto.RegisterInterface(SeatInterface)
+}
+
+type ClientSeatImplementation interface {
+
+	//
+	// This is emitted whenever a seat gains or loses the pointer,
+	// keyboard or touch capabilities.  The argument is a capability
+	// enum containing the complete set of capabilities this seat has.
+	//
+	Capabilities(capabilities SeatCapability) error
+
+	//
+	// In a multiseat configuration this can be used by the client to help
+	// identify which physical devices the seat represents. Based on
+	// the seat configuration used by the compositor.
+	//
+	Name(name string) error
+}
+
+// ClientSeatListener implements ClientSeatImplementation with a
+// function per event. Events whose function is nil are ignored, and fds
+// they carry are closed.
+type ClientSeatListener struct {
+	OnCapabilities func(capabilities SeatCapability) error
+	OnName         func(name string) error
+}
+
+func (l ClientSeatListener) Capabilities(capabilities SeatCapability) error {
+	if l.OnCapabilities == nil {
+		return nil
+	}
+	return l.OnCapabilities(capabilities)
+}
+
+func (l ClientSeatListener) Name(name string) error {
+	if l.OnName == nil {
+		return nil
+	}
+	return l.OnName(name)
+}
+
+type ClientSeat struct {
+	c       *proto.Conn
+	id      proto.ObjectId
+	version uint32
+	i       ClientSeatImplementation
+}
+
+// NewSeat creates an object at the given version, which is the
+// version it was bound at or the version of the object that created it.
+func (c Client) NewSeat(version uint32, i ClientSeatImplementation) *ClientSeat {
+	return c.NewSeatWithId(c.c.NextId(), version, i)
+}
+
+// NewSeatWithId registers an object created by the other end
+// of the connection under the id it has chosen.
+func (c Client) NewSeatWithId(id proto.ObjectId, version uint32, i ClientSeatImplementation) *ClientSeat {
+	o := &ClientSeat{
+		c:       c.c,
+		id:      id,
+		version: version,
+		i:       i,
+	}
+	c.c.AddObject(o.id, o)
+	return o
+}
+
+func (o *ClientSeat) Id() proto.ObjectId {
+	return o.id
+}
+
+func (o *ClientSeat) Version() uint32 {
+	return o.version
+}
+
+func (o *ClientSeat) Conn() *proto.Conn {
+	return o.c
+}
+
+func (o *ClientSeat) Interface() *proto.Interface {
+	return SeatInterface
+}
+
+func (o *ClientSeat) Handle(m *proto.Message) (err error) {
+	switch m.Opcode() {
+
+	case 0:
+
+		var (
+			capabilities SeatCapability
+		)
+
+		var capabilitiesRaw uint32
+		if capabilitiesRaw, err = m.ReadUint(); err != nil {
+			return
+		}
+		capabilities = SeatCapability(capabilitiesRaw)
+
+		return o.i.Capabilities(capabilities)
+
+	case 1:
+
+		var (
+			name string
+		)
+
+		if name, err = m.ReadString(); err != nil {
+			return
+		}
+
+		return o.i.Name(name)
+
+	default:
+		return fmt.Errorf("Seat: invalid event opcode: %d", m.Opcode())
+	}
+}
+
+// BindSeat binds global, which must be a wl_seat, at the
+// lower of its advertised version and maxVersion, and returns the proxy.
+// maxVersion is capped at 3, the version known to this package.
+func BindSeat(registry proto.Registry, global proto.Global, maxVersion uint32, i ClientSeatImplementation) (*ClientSeat, error) {
+	if maxVersion > 3 {
+		maxVersion = 3
+	}
+	return proto.BindGlobal(registry, global, maxVersion, func(version uint32) *ClientSeat {
+		return NewClient(registry.Conn()).NewSeat(version, i)
+	})
+}
+
+// The ID provided will be initialized to the wl_pointer interface
+// for this seat.
+//
+// This request only takes effect if the seat has the pointer
+// capability.
+func (o *ClientSeat) GetPointer(i ClientPointerImplementation) (*ClientPointer, error) {
+	obj := NewClient(o.c).NewPointer(o.version, i)
+	m := proto.NewMessage(o.id, 0)
+
+	if err := m.WriteNewId(obj.Id()); err != nil {
+		o.c.DeleteObject(obj.Id())
+		return nil, err
+	}
+
+	if err := o.c.WriteMessage(m); err != nil {
+		o.c.DeleteObject(obj.Id())
+		return nil, err
+	}
+	return obj, nil
+}
+
+// The ID provided will be initialized to the wl_keyboard interface
+// for this seat.
+//
+// This request only takes effect if the seat has the keyboard
+// capability.
+func (o *ClientSeat) GetKeyboard(i ClientKeyboardImplementation) (*ClientKeyboard, error) {
+	obj := NewClient(o.c).NewKeyboard(o.version, i)
+	m := proto.NewMessage(o.id, 1)
+
+	if err := m.WriteNewId(obj.Id()); err != nil {
+		o.c.DeleteObject(obj.Id())
+		return nil, err
+	}
+
+	if err := o.c.WriteMessage(m); err != nil {
+		o.c.DeleteObject(obj.Id())
+		return nil, err
+	}
+	return obj, nil
+}
+
+// The ID provided will be initialized to the wl_touch interface
+// for this seat.
+//
+// This request only takes effect if the seat has the touch
+// capability.
+func (o *ClientSeat) GetTouch(i ClientTouchImplementation) (*ClientTouch, error) {
+	obj := NewClient(o.c).NewTouch(o.version, i)
+	m := proto.NewMessage(o.id, 2)
+
+	if err := m.WriteNewId(obj.Id()); err != nil {
+		o.c.DeleteObject(obj.Id())
+		return nil, err
+	}
+
+	if err := o.c.WriteMessage(m); err != nil {
+		o.c.DeleteObject(obj.Id())
+		return nil, err
+	}
+	return obj, nil
+}
+
+type ServerSeatImplementation interface {
+
+	//
+	// The ID provided will be initialized to the wl_pointer interface
+	// for this seat.
+	//
+	// This request only takes effect if the seat has the pointer
+	// capability.
+	//
+	GetPointer(id proto.ObjectId) error
+
+	//
+	// The ID provided will be initialized to the wl_keyboard interface
+	// for this seat.
+	//
+	// This request only takes effect if the seat has the keyboard
+	// capability.
+	//
+	GetKeyboard(id proto.ObjectId) error
+
+	//
+	// The ID provided will be initialized to the wl_touch interface
+	// for this seat.
+	//
+	// This request only takes effect if the seat has the touch
+	// capability.
+	//
+	GetTouch(id proto.ObjectId) error
+}
+
+type ServerSeat struct {
+	c       *proto.Conn
+	id      proto.ObjectId
+	version uint32
+	i       ServerSeatImplementation
+}
+
+// NewSeat creates an object at the given version, which is the
+// version it was bound at or the version of the object that created it.
+func (c Server) NewSeat(version uint32, i ServerSeatImplementation) *ServerSeat {
+	return c.NewSeatWithId(c.c.NextId(), version, i)
+}
+
+// NewSeatWithId registers an object created by the other end
+// of the connection under the id it has chosen.
+func (c Server) NewSeatWithId(id proto.ObjectId, version uint32, i ServerSeatImplementation) *ServerSeat {
+	o := &ServerSeat{
+		c:       c.c,
+		id:      id,
+		version: version,
+		i:       i,
+	}
+	c.c.AddObject(o.id, o)
+	return o
+}
+
+func (o *ServerSeat) Id() proto.ObjectId {
+	return o.id
+}
+
+func (o *ServerSeat) Version() uint32 {
+	return o.version
+}
+
+func (o *ServerSeat) Conn() *proto.Conn {
+	return o.c
+}
+
+func (o *ServerSeat) Interface() *proto.Interface {
+	return SeatInterface
+}
+
+func (o *ServerSeat) Handle(m *proto.Message) (err error) {
+	switch m.Opcode() {
+
+	case 0:
+
+		var (
+			id proto.ObjectId
+		)
+
+		if id, err = m.ReadNewId(); err != nil {
+			return
+		}
+
+		return o.i.GetPointer(id)
+
+	case 1:
+
+		var (
+			id proto.ObjectId
+		)
+
+		if id, err = m.ReadNewId(); err != nil {
+			return
+		}
+
+		return o.i.GetKeyboard(id)
+
+	case 2:
+
+		var (
+			id proto.ObjectId
+		)
+
+		if id, err = m.ReadNewId(); err != nil {
+			return
+		}
+
+		return o.i.GetTouch(id)
+
+	default:
+		return fmt.Errorf("Seat: invalid request opcode: %d", m.Opcode())
+	}
+}
+
+// This is emitted whenever a seat gains or loses the pointer,
+// keyboard or touch capabilities.  The argument is a capability
+// enum containing the complete set of capabilities this seat has.
+func (o *ServerSeat) Capabilities(capabilities SeatCapability) error {
+	m := proto.NewMessage(o.id, 0)
+
+	if err := m.WriteUint(uint32(capabilities)); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// In a multiseat configuration this can be used by the client to help
+// identify which physical devices the seat represents. Based on
+// the seat configuration used by the compositor.
+//
+// The event is not sent to clients that bound a version older than 2.
+func (o *ServerSeat) Name(name string) error {
+	if o.version < SEAT_NAME_SINCE_VERSION {
+		return nil
+	}
+	m := proto.NewMessage(o.id, 1)
+
+	if err := m.WriteString(name); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// Pointer
+//
+// The wl_pointer interface represents one or more input devices,
+// such as mice, which control the pointer location and pointer_focus
+// of a seat.
+//
+// The wl_pointer interface generates motion, enter and leave
+// events for the surfaces that the pointer is located over,
+// and button and axis events for button presses, button releases
+// and scrolling.
+//
+
+// Describes the physical state of a button which provoked the button
+// event.
+type PointerButtonState uint32
+
+const (
+
+	// The button is not pressed
+	POINTER_BUTTON_STATE_RELEASED PointerButtonState = 0
+
+	// The button is pressed
+	POINTER_BUTTON_STATE_PRESSED PointerButtonState = 1
+)
+
+func (e PointerButtonState) String() string {
+	switch e {
+	case POINTER_BUTTON_STATE_RELEASED:
+		return "released"
+	case POINTER_BUTTON_STATE_PRESSED:
+		return "pressed"
+	}
+	return fmt.Sprintf("PointerButtonState(%d)", uint32(e))
+}
+
+// Describes the axis types of scroll events.
+type PointerAxis uint32
+
+const (
+
+	//
+	POINTER_AXIS_VERTICAL_SCROLL PointerAxis = 0
+
+	//
+	POINTER_AXIS_HORIZONTAL_SCROLL PointerAxis = 1
+)
+
+func (e PointerAxis) String() string {
+	switch e {
+	case POINTER_AXIS_VERTICAL_SCROLL:
+		return "vertical_scroll"
+	case POINTER_AXIS_HORIZONTAL_SCROLL:
+		return "horizontal_scroll"
+	}
+	return fmt.Sprintf("PointerAxis(%d)", uint32(e))
+}
+
+// Versions that introduced each request and event.
+const (
+	POINTER_SET_CURSOR_SINCE_VERSION = 1
+	POINTER_RELEASE_SINCE_VERSION    = 3
+	POINTER_ENTER_SINCE_VERSION      = 1
+	POINTER_LEAVE_SINCE_VERSION      = 1
+	POINTER_MOTION_SINCE_VERSION     = 1
+	POINTER_BUTTON_SINCE_VERSION     = 1
+	POINTER_AXIS_SINCE_VERSION       = 1
+)
+
+var PointerInterface = &proto.Interface{
+	Name:    "wl_pointer",
+	Version: 3,
+	Requests: []proto.Signature{
+		{Name: "set_cursor", Since: 1, Args: []proto.ArgDesc{
+			{Name: "serial", Type: proto.ArgUint, Interface: "", Nullable: false},
+			{Name: "surface", Type: proto.ArgObject, Interface: "wl_surface", Nullable: true},
+			{Name: "hotspot_x", Type: proto.ArgInt, Interface: "", Nullable: false},
+			{Name: "hotspot_y", Type: proto.ArgInt, Interface: "", Nullable: false},
+		}},
+		{Name: "release", Since: 3, Args: []proto.ArgDesc{}},
+	},
+	Events: []proto.Signature{
+		{Name: "enter", Since: 1, Args: []proto.ArgDesc{
+			{Name: "serial", Type: proto.ArgUint, Interface: "", Nullable: false},
+			{Name: "surface", Type: proto.ArgObject, Interface: "wl_surface", Nullable: false},
+			{Name: "surface_x", Type: proto.ArgFixed, Interface: "", Nullable: false},
+			{Name: "surface_y", Type: proto.ArgFixed, Interface: "", Nullable: false},
+		}},
+		{Name: "leave", Since: 1, Args: []proto.ArgDesc{
+			{Name: "serial", Type: proto.ArgUint, Interface: "", Nullable: false},
+			{Name: "surface", Type: proto.ArgObject, Interface: "wl_surface", Nullable: false},
+		}},
+		{Name: "motion", Since: 1, Args: []proto.ArgDesc{
+			{Name: "time", Type: proto.ArgUint, Interface: "", Nullable: false},
+			{Name: "surface_x", Type: proto.ArgFixed, Interface: "", Nullable: false},
+			{Name: "surface_y", Type: proto.ArgFixed, Interface: "", Nullable: false},
+		}},
+		{Name: "button", Since: 1, Args: []proto.ArgDesc{
+			{Name: "serial", Type: proto.ArgUint, Interface: "", Nullable: false},
+			{Name: "time", Type: proto.ArgUint, Interface: "", Nullable: false},
+			{Name: "button", Type: proto.ArgUint, Interface: "", Nullable: false},
+			{Name: "state", Type: proto.ArgUint, Interface: "", Nullable: false},
+		}},
+		{Name: "axis", Since: 1, Args: []proto.ArgDesc{
+			{Name: "time", Type: proto.ArgUint, Interface: "", Nullable: false},
+			{Name: "axis", Type: proto.ArgUint, Interface: "", Nullable: false},
+			{Name: "value", Type: proto.ArgFixed, Interface: "", Nullable: false},
+		}},
+	},
+}
+
+func init() {
+	proto.RegisterInterface(PointerInterface)
+}
+
+type ClientPointerImplementation interface {
+
+	//
+	// Notification that this seat's pointer is focused on a certain
+	// surface.
+	//
+	// When an seat's focus enters a surface, the pointer image
+	// is undefined and a client should respond to this event by setting
+	// an appropriate pointer image with the set_cursor request.
+	//
+	Enter(serial uint32, surface *ClientSurface, surfaceX proto.Fixed, surfaceY proto.Fixed) error
+
+	//
+	// Notification that this seat's pointer is no longer focused on
+	// a certain surface.
+	//
+	// The leave notification is sent before the enter notification
+	// for the new focus.
+	//
+	Leave(serial uint32, surface *ClientSurface) error
+
+	//
+	// Notification of pointer location change. The arguments
+	// surface_x and surface_y are the location relative to the
+	// focused surface.
+	//
+	Motion(time uint32, surfaceX proto.Fixed, surfaceY proto.Fixed) error
+
+	//
+	// Mouse button click and release notifications.
+	//
+	// The location of the click is given by the last motion or
+	// enter event.
+	// The time argument is a timestamp with millisecond
+	// granularity, with an undefined base.
+	//
+	Button(serial uint32, time uint32, button uint32, state PointerButtonState) error
+
+	//
+	// Scroll and other axis notifications.
+	//
+	// For scroll events (vertical and horizontal scroll axes), the
+	// value parameter is the length of a vector along the specified
+	// axis in a coordinate space identical to those of motion events,
+	// representing a relative movement along the specified axis.
+	//
+	// For devices that support movements non-parallel to axes multiple
+	// axis events will be emitted.
+	//
+	// When applicable, for example for touch pads, the server can
+	// choose to emit scroll events where the motion vector is
+	// equivalent to a motion event vector.
+	//
+	// When applicable, clients can transform its view relative to the
+	// scroll distance.
+	//
+	Axis(time uint32, axis PointerAxis, value proto.Fixed) error
+}
+
+// ClientPointerListener implements ClientPointerImplementation with a
+// function per event. Events whose function is nil are ignored, and fds
+// they carry are closed.
+type ClientPointerListener struct {
+	OnEnter  func(serial uint32, surface *ClientSurface, surfaceX proto.Fixed, surfaceY proto.Fixed) error
+	OnLeave  func(serial uint32, surface *ClientSurface) error
+	OnMotion func(time uint32, surfaceX proto.Fixed, surfaceY proto.Fixed) error
+	OnButton func(serial uint32, time uint32, button uint32, state PointerButtonState) error
+	OnAxis   func(time uint32, axis PointerAxis, value proto.Fixed) error
+}
+
+func (l ClientPointerListener) Enter(serial uint32, surface *ClientSurface, surfaceX proto.Fixed, surfaceY proto.Fixed) error {
+	if l.OnEnter == nil {
+		return nil
+	}
+	return l.OnEnter(serial, surface, surfaceX, surfaceY)
+}
+
+func (l ClientPointerListener) Leave(serial uint32, surface *ClientSurface) error {
+	if l.OnLeave == nil {
+		return nil
+	}
+	return l.OnLeave(serial, surface)
+}
+
+func (l ClientPointerListener) Motion(time uint32, surfaceX proto.Fixed, surfaceY proto.Fixed) error {
+	if l.OnMotion == nil {
+		return nil
+	}
+	return l.OnMotion(time, surfaceX, surfaceY)
+}
+
+func (l ClientPointerListener) Button(serial uint32, time uint32, button uint32, state PointerButtonState) error {
+	if l.OnButton == nil {
+		return nil
+	}
+	return l.OnButton(serial, time, button, state)
+}
+
+func (l ClientPointerListener) Axis(time uint32, axis PointerAxis, value proto.Fixed) error {
+	if l.OnAxis == nil {
+		return nil
+	}
+	return l.OnAxis(time, axis, value)
+}
+
+type ClientPointer struct {
+	c         *proto.Conn
+	id        proto.ObjectId
+	version   uint32
+	i         ClientPointerImplementation
+	destroyed bool
+}
+
+// NewPointer creates an object at the given version, which is the
+// version it was bound at or the version of the object that created it.
+func (c Client) NewPointer(version uint32, i ClientPointerImplementation) *ClientPointer {
+	return c.NewPointerWithId(c.c.NextId(), version, i)
+}
+
+// NewPointerWithId registers an object created by the other end
+// of the connection under the id it has chosen.
+func (c Client) NewPointerWithId(id proto.ObjectId, version uint32, i ClientPointerImplementation) *ClientPointer {
+	o := &ClientPointer{
+		c:       c.c,
+		id:      id,
+		version: version,
+		i:       i,
+	}
+	c.c.AddObject(o.id, o)
+	return o
+}
+
+func (o *ClientPointer) Id() proto.ObjectId {
+	return o.id
+}
+
+func (o *ClientPointer) Version() uint32 {
+	return o.version
+}
+
+func (o *ClientPointer) Conn() *proto.Conn {
+	return o.c
+}
+
+func (o *ClientPointer) Interface() *proto.Interface {
+	return PointerInterface
+}
+
+func (o *ClientPointer) Handle(m *proto.Message) (err error) {
+	switch m.Opcode() {
+
+	case 0:
+
+		var (
+			serial uint32
+
+			surface *ClientSurface
+
+			surfaceX proto.Fixed
+
+			surfaceY proto.Fixed
+		)
+
+		if serial, err = m.ReadUint(); err != nil {
+			return
+		}
+
+		var surfaceObj proto.Object
+		if surfaceObj, err = m.ReadObject(false); err != nil {
+			return fmt.Errorf("wl_pointer.enter: argument surface: %w", err)
+		}
+		if surfaceObj != nil {
+			var ok bool
+			if surface, ok = surfaceObj.(*ClientSurface); !ok {
+				return fmt.Errorf("wl_pointer.enter: argument surface: %T is not a wl_surface", surfaceObj)
+			}
+		}
+
+		if surfaceX, err = m.ReadFixed(); err != nil {
+			return
+		}
+
+		if surfaceY, err = m.ReadFixed(); err != nil {
+			return
+		}
+
+		return o.i.Enter(serial, surface, surfaceX, surfaceY)
+
+	case 1:
+
+		var (
+			serial uint32
+
+			surface *ClientSurface
+		)
+
+		if serial, err = m.ReadUint(); err != nil {
+			return
+		}
+
+		var surfaceObj proto.Object
+		if surfaceObj, err = m.ReadObject(false); err != nil {
+			return fmt.Errorf("wl_pointer.leave: argument surface: %w", err)
+		}
+		if surfaceObj != nil {
+			var ok bool
+			if surface, ok = surfaceObj.(*ClientSurface); !ok {
+				return fmt.Errorf("wl_pointer.leave: argument surface: %T is not a wl_surface", surfaceObj)
+			}
+		}
+
+		return o.i.Leave(serial, surface)
+
+	case 2:
+
+		var (
+			time uint32
+
+			surfaceX proto.Fixed
+
+			surfaceY proto.Fixed
+		)
+
+		if time, err = m.ReadUint(); err != nil {
+			return
+		}
+
+		if surfaceX, err = m.ReadFixed(); err != nil {
+			return
+		}
+
+		if surfaceY, err = m.ReadFixed(); err != nil {
+			return
+		}
+
+		return o.i.Motion(time, surfaceX, surfaceY)
+
+	case 3:
+
+		var (
+			serial uint32
+
+			time uint32
+
+			button uint32
+
+			state PointerButtonState
+		)
+
+		if serial, err = m.ReadUint(); err != nil {
+			return
+		}
+
+		if time, err = m.ReadUint(); err != nil {
+			return
+		}
+
+		if button, err = m.ReadUint(); err != nil {
+			return
+		}
+
+		var stateRaw uint32
+		if stateRaw, err = m.ReadUint(); err != nil {
+			return
+		}
+		state = PointerButtonState(stateRaw)
+
+		return o.i.Button(serial, time, button, state)
+
+	case 4:
+
+		var (
+			time uint32
+
+			axis PointerAxis
+
+			value proto.Fixed
+		)
+
+		if time, err = m.ReadUint(); err != nil {
+			return
+		}
+
+		var axisRaw uint32
+		if axisRaw, err = m.ReadUint(); err != nil {
+			return
+		}
+		axis = PointerAxis(axisRaw)
+
+		if value, err = m.ReadFixed(); err != nil {
+			return
+		}
+
+		return o.i.Axis(time, axis, value)
+
+	default:
+		return fmt.Errorf("Pointer: invalid event opcode: %d", m.Opcode())
+	}
+}
+
+// Set the pointer surface, i.e., the surface that contains the
+// pointer image (cursor). This request only takes effect if the pointer
+// focus for this device is one of the requesting client's surfaces
+// or the surface parameter is the current pointer surface. If
+// there was a previous surface set with this request it is
+// replaced. If surface is NULL, the pointer image is hidden.
+//
+// The parameters hotspot_x and hotspot_y define the position of
+// the pointer surface relative to the pointer location. Its
+// top-left corner is always at (x, y) - (hotspot_x, hotspot_y),
+// where (x, y) are the coordinates of the pointer location, in surface
+// local coordinates.
+//
+// On surface.attach requests to the pointer surface, hotspot_x
+// and hotspot_y are decremented by the x and y parameters
+// passed to the request. Attach must be confirmed by
+// wl_surface.commit as usual.
+//
+// The hotspot can also be updated by passing the currently set
+// pointer surface to this request with new values for hotspot_x
+// and hotspot_y.
+//
+// The current and pending input regions of the wl_surface are
+// cleared, and wl_surface.set_input_region is ignored until the
+// wl_surface is no longer used as the cursor. When the use as a
+// cursor ends, the current and pending input regions become
+// undefined, and the wl_surface is unmapped.
+func (o *ClientPointer) SetCursor(serial uint32, surface *ClientSurface, hotspotX int32, hotspotY int32) error {
+	if o.destroyed {
+		return fmt.Errorf("wl_pointer@%d.set_cursor: %w", o.id, proto.ErrDestroyed)
+	}
+	var surfaceId proto.ObjectId
+	if surface != nil {
+		surfaceId = surface.Id()
+	}
+	m := proto.NewMessage(o.id, 0)
+
+	if err := m.WriteUint(serial); err != nil {
+		return err
+	}
+
+	if err := m.WriteObjectId(surfaceId); err != nil {
+		return err
+	}
+
+	if err := m.WriteInt(hotspotX); err != nil {
+		return err
+	}
+
+	if err := m.WriteInt(hotspotY); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+func (o *ClientPointer) Release() error {
+	if o.destroyed {
+		return fmt.Errorf("wl_pointer@%d.release: %w", o.id, proto.ErrDestroyed)
+	}
+	if o.version < POINTER_RELEASE_SINCE_VERSION {
+		return &proto.VersionError{
+			ObjectId:  o.id,
+			Interface: "wl_pointer",
+			Request:   "release",
+			Since:     POINTER_RELEASE_SINCE_VERSION,
+			Version:   o.version,
+		}
+	}
+	m := proto.NewMessage(o.id, 1)
+
+	if err := o.c.WriteMessage(m); err != nil {
+		return err
+	}
+	o.destroyed = true
+	return o.c.DestroyObject(o.id)
+}
+
+type ServerPointerImplementation interface {
+
+	//
+	// Set the pointer surface, i.e., the surface that contains the
+	// pointer image (cursor). This request only takes effect if the pointer
+	// focus for this device is one of the requesting client's surfaces
+	// or the surface parameter is the current pointer surface. If
+	// there was a previous surface set with this request it is
+	// replaced. If surface is NULL, the pointer image is hidden.
+	//
+	// The parameters hotspot_x and hotspot_y define the position of
+	// the pointer surface relative to the pointer location. Its
+	// top-left corner is always at (x, y) - (hotspot_x, hotspot_y),
+	// where (x, y) are the coordinates of the pointer location, in surface
+	// local coordinates.
+	//
+	// On surface.attach requests to the pointer surface, hotspot_x
+	// and hotspot_y are decremented by the x and y parameters
+	// passed to the request. Attach must be confirmed by
+	// wl_surface.commit as usual.
+	//
+	// The hotspot can also be updated by passing the currently set
+	// pointer surface to this request with new values for hotspot_x
+	// and hotspot_y.
+	//
+	// The current and pending input regions of the wl_surface are
+	// cleared, and wl_surface.set_input_region is ignored until the
+	// wl_surface is no longer used as the cursor. When the use as a
+	// cursor ends, the current and pending input regions become
+	// undefined, and the wl_surface is unmapped.
+	//
+	SetCursor(serial uint32, surface *ServerSurface, hotspotX int32, hotspotY int32) error
+
+	//
+	Release() error
+}
+
+type ServerPointer struct {
+	c         *proto.Conn
+	id        proto.ObjectId
+	version   uint32
+	i         ServerPointerImplementation
+	destroyed bool
+}
+
+// NewPointer creates an object at the given version, which is the
+// version it was bound at or the version of the object that created it.
+func (c Server) NewPointer(version uint32, i ServerPointerImplementation) *ServerPointer {
+	return c.NewPointerWithId(c.c.NextId(), version, i)
+}
+
+// NewPointerWithId registers an object created by the other end
+// of the connection under the id it has chosen.
+func (c Server) NewPointerWithId(id proto.ObjectId, version uint32, i ServerPointerImplementation) *ServerPointer {
+	o := &ServerPointer{
+		c:       c.c,
+		id:      id,
+		version: version,
+		i:       i,
+	}
+	c.c.AddObject(o.id, o)
+	return o
+}
+
+func (o *ServerPointer) Id() proto.ObjectId {
+	return o.id
+}
+
+func (o *ServerPointer) Version() uint32 {
+	return o.version
+}
+
+func (o *ServerPointer) Conn() *proto.Conn {
+	return o.c
+}
+
+func (o *ServerPointer) Interface() *proto.Interface {
+	return PointerInterface
+}
+
+func (o *ServerPointer) Handle(m *proto.Message) (err error) {
+	switch m.Opcode() {
+
+	case 0:
+
+		var (
+			serial uint32
+
+			surface *ServerSurface
+
+			hotspotX int32
+
+			hotspotY int32
+		)
+
+		if serial, err = m.ReadUint(); err != nil {
+			return
+		}
+
+		var surfaceObj proto.Object
+		if surfaceObj, err = m.ReadObject(true); err != nil {
+			return fmt.Errorf("wl_pointer.set_cursor: argument surface: %w", err)
+		}
+		if surfaceObj != nil {
+			var ok bool
+			if surface, ok = surfaceObj.(*ServerSurface); !ok {
+				return fmt.Errorf("wl_pointer.set_cursor: argument surface: %T is not a wl_surface", surfaceObj)
+			}
+		}
+
+		if hotspotX, err = m.ReadInt(); err != nil {
+			return
+		}
+
+		if hotspotY, err = m.ReadInt(); err != nil {
+			return
+		}
+
+		return o.i.SetCursor(serial, surface, hotspotX, hotspotY)
+
+	case 1:
+
+		var ()
+
+		err = o.i.Release()
+		o.destroyed = true
+		if derr := o.c.DestroyObject(o.id); err == nil {
+			err = derr
+		}
+		return
+
+	default:
+		return fmt.Errorf("Pointer: invalid request opcode: %d", m.Opcode())
+	}
+}
+
+// Notification that this seat's pointer is focused on a certain
+// surface.
+//
+// When an seat's focus enters a surface, the pointer image
+// is undefined and a client should respond to this event by setting
+// an appropriate pointer image with the set_cursor request.
+func (o *ServerPointer) Enter(serial uint32, surface *ServerSurface, surfaceX proto.Fixed, surfaceY proto.Fixed) error {
+	if o.destroyed {
+		return fmt.Errorf("wl_pointer@%d.enter: %w", o.id, proto.ErrDestroyed)
+	}
+	var surfaceId proto.ObjectId
+	if surface != nil {
+		surfaceId = surface.Id()
+	} else {
+		return fmt.Errorf("wl_pointer.enter: argument surface is null")
+	}
+	m := proto.NewMessage(o.id, 0)
+
+	if err := m.WriteUint(serial); err != nil {
+		return err
+	}
+
+	if err := m.WriteObjectId(surfaceId); err != nil {
+		return err
+	}
+
+	if err := m.WriteFixed(surfaceX); err != nil {
+		return err
+	}
+
+	if err := m.WriteFixed(surfaceY); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// Notification that this seat's pointer is no longer focused on
+// a certain surface.
+//
+// The leave notification is sent before the enter notification
+// for the new focus.
+func (o *ServerPointer) Leave(serial uint32, surface *ServerSurface) error {
+	if o.destroyed {
+		return fmt.Errorf("wl_pointer@%d.leave: %w", o.id, proto.ErrDestroyed)
+	}
+	var surfaceId proto.ObjectId
+	if surface != nil {
+		surfaceId = surface.Id()
+	} else {
+		return fmt.Errorf("wl_pointer.leave: argument surface is null")
+	}
+	m := proto.NewMessage(o.id, 1)
+
+	if err := m.WriteUint(serial); err != nil {
+		return err
+	}
+
+	if err := m.WriteObjectId(surfaceId); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// Notification of pointer location change. The arguments
+// surface_x and surface_y are the location relative to the
+// focused surface.
+func (o *ServerPointer) Motion(time uint32, surfaceX proto.Fixed, surfaceY proto.Fixed) error {
+	if o.destroyed {
+		return fmt.Errorf("wl_pointer@%d.motion: %w", o.id, proto.ErrDestroyed)
+	}
+	m := proto.NewMessage(o.id, 2)
+
+	if err := m.WriteUint(time); err != nil {
+		return err
+	}
+
+	if err := m.WriteFixed(surfaceX); err != nil {
+		return err
+	}
+
+	if err := m.WriteFixed(surfaceY); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// Mouse button click and release notifications.
+//
+// The location of the click is given by the last motion or
+// enter event.
+// The time argument is a timestamp with millisecond
+// granularity, with an undefined base.
+func (o *ServerPointer) Button(serial uint32, time uint32, button uint32, state PointerButtonState) error {
+	if o.destroyed {
+		return fmt.Errorf("wl_pointer@%d.button: %w", o.id, proto.ErrDestroyed)
+	}
+	m := proto.NewMessage(o.id, 3)
+
+	if err := m.WriteUint(serial); err != nil {
+		return err
+	}
+
+	if err := m.WriteUint(time); err != nil {
+		return err
+	}
+
+	if err := m.WriteUint(button); err != nil {
+		return err
+	}
+
+	if err := m.WriteUint(uint32(state)); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// Scroll and other axis notifications.
+//
+// For scroll events (vertical and horizontal scroll axes), the
+// value parameter is the length of a vector along the specified
+// axis in a coordinate space identical to those of motion events,
+// representing a relative movement along the specified axis.
+//
+// For devices that support movements non-parallel to axes multiple
+// axis events will be emitted.
+//
+// When applicable, for example for touch pads, the server can
+// choose to emit scroll events where the motion vector is
+// equivalent to a motion event vector.
+//
+// When applicable, clients can transform its view relative to the
+// scroll distance.
+func (o *ServerPointer) Axis(time uint32, axis PointerAxis, value proto.Fixed) error {
+	if o.destroyed {
+		return fmt.Errorf("wl_pointer@%d.axis: %w", o.id, proto.ErrDestroyed)
+	}
+	m := proto.NewMessage(o.id, 4)
+
+	if err := m.WriteUint(time); err != nil {
+		return err
+	}
+
+	if err := m.WriteUint(uint32(axis)); err != nil {
+		return err
+	}
+
+	if err := m.WriteFixed(value); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// Keyboard
+//
+// The wl_keyboard interface represents one or more keyboards
+// associated with a seat.
+//
+
+// This specifies the format of the keymap provided to the
+// client with the wl_keyboard.keymap event.
+type KeyboardKeymapFormat uint32
+
+const (
+
+	// no keymap; client must understand how to interpret the raw keycode
+	KEYBOARD_KEYMAP_FORMAT_NO_KEYMAP KeyboardKeymapFormat = 0
+
+	// libxkbcommon compatible
+	KEYBOARD_KEYMAP_FORMAT_XKB_V1 KeyboardKeymapFormat = 1
+)
+
+func (e KeyboardKeymapFormat) String() string {
+	switch e {
+	case KEYBOARD_KEYMAP_FORMAT_NO_KEYMAP:
+		return "no_keymap"
+	case KEYBOARD_KEYMAP_FORMAT_XKB_V1:
+		return "xkb_v1"
+	}
+	return fmt.Sprintf("KeyboardKeymapFormat(%d)", uint32(e))
+}
+
+// Describes the physical state of a key which provoked the key event.
+type KeyboardKeyState uint32
+
+const (
+
+	// key is not pressed
+	KEYBOARD_KEY_STATE_RELEASED KeyboardKeyState = 0
+
+	// key is pressed
+	KEYBOARD_KEY_STATE_PRESSED KeyboardKeyState = 1
+)
+
+func (e KeyboardKeyState) String() string {
+	switch e {
+	case KEYBOARD_KEY_STATE_RELEASED:
+		return "released"
+	case KEYBOARD_KEY_STATE_PRESSED:
+		return "pressed"
+	}
+	return fmt.Sprintf("KeyboardKeyState(%d)", uint32(e))
+}
+
+// Versions that introduced each request and event.
+const (
+	KEYBOARD_RELEASE_SINCE_VERSION   = 3
+	KEYBOARD_KEYMAP_SINCE_VERSION    = 1
+	KEYBOARD_ENTER_SINCE_VERSION     = 1
+	KEYBOARD_LEAVE_SINCE_VERSION     = 1
+	KEYBOARD_KEY_SINCE_VERSION       = 1
+	KEYBOARD_MODIFIERS_SINCE_VERSION = 1
+)
+
+var KeyboardInterface = &proto.Interface{
+	Name:    "wl_keyboard",
+	Version: 3,
+	Requests: []proto.Signature{
+		{Name: "release", Since: 3, Args: []proto.ArgDesc{}},
+	},
+	Events: []proto.Signature{
+		{Name: "keymap", Since: 1, Args: []proto.ArgDesc{
+			{Name: "format", Type: proto.ArgUint, Interface: "", Nullable: false},
+			{Name: "fd", Type: proto.ArgFd, Interface: "", Nullable: false},
+			{Name: "size", Type: proto.ArgUint, Interface: "", Nullable: false},
+		}},
+		{Name: "enter", Since: 1, Args: []proto.ArgDesc{
+			{Name: "serial", Type: proto.ArgUint, Interface: "", Nullable: false},
+			{Name: "surface", Type: proto.ArgObject, Interface: "wl_surface", Nullable: false},
+			{Name: "keys", Type: proto.ArgArray, Interface: "", Nullable: false},
+		}},
+		{Name: "leave", Since: 1, Args: []proto.ArgDesc{
+			{Name: "serial", Type: proto.ArgUint, Interface: "", Nullable: false},
+			{Name: "surface", Type: proto.ArgObject, Interface: "wl_surface", Nullable: false},
+		}},
+		{Name: "key", Since: 1, Args: []proto.ArgDesc{
+			{Name: "serial", Type: proto.ArgUint, Interface: "", Nullable: false},
+			{Name: "time", Type: proto.ArgUint, Interface: "", Nullable: false},
+			{Name: "key", Type: proto.ArgUint, Interface: "", Nullable: false},
+			{Name: "state", Type: proto.ArgUint, Interface: "", Nullable: false},
+		}},
+		{Name: "modifiers", Since: 1, Args: []proto.ArgDesc{
+			{Name: "serial", Type: proto.ArgUint, Interface: "", Nullable: false},
+			{Name: "mods_depressed", Type: proto.ArgUint, Interface: "", Nullable: false},
+			{Name: "mods_latched", Type: proto.ArgUint, Interface: "", Nullable: false},
+			{Name: "mods_locked", Type: proto.ArgUint, Interface: "", Nullable: false},
+			{Name: "group", Type: proto.ArgUint, Interface: "", Nullable: false},
+		}},
+	},
+}
+
+func init() {
+	proto.RegisterInterface(KeyboardInterface)
+}
+
+type ClientKeyboardImplementation interface {
+
+	//
+	// This event provides a file descriptor to the client which can be
+	// memory-mapped to provide a keyboard mapping description.
+	//
+	Keymap(format KeyboardKeymapFormat, fd uintptr, size uint32) error
+
+	//
+	// Notification that this seat's keyboard focus is on a certain
+	// surface.
+	//
+	Enter(serial uint32, surface *ClientSurface, keys []byte) error
+
+	//
+	// Notification that this seat's keyboard focus is no longer on
+	// a certain surface.
+	//
+	// The leave notification is sent before the enter notification
+	// for the new focus.
+	//
+	Leave(serial uint32, surface *ClientSurface) error
+
+	//
+	// A key was pressed or released.
+	// The time argument is a timestamp with millisecond
+	// granularity, with an undefined base.
+	//
+	Key(serial uint32, time uint32, key uint32, state KeyboardKeyState) error
+
+	//
+	// Notifies clients that the modifier and/or group state has
+	// changed, and it should update its local state.
+	//
+	Modifiers(serial uint32, modsDepressed uint32, modsLatched uint32, modsLocked uint32, group uint32) error
+}
+
+// ClientKeyboardListener implements ClientKeyboardImplementation with a
+// function per event. Events whose function is nil are ignored, and fds
+// they carry are closed.
+type ClientKeyboardListener struct {
+	OnKeymap    func(format KeyboardKeymapFormat, fd uintptr, size uint32) error
+	OnEnter     func(serial uint32, surface *ClientSurface, keys []byte) error
+	OnLeave     func(serial uint32, surface *ClientSurface) error
+	OnKey       func(serial uint32, time uint32, key uint32, state KeyboardKeyState) error
+	OnModifiers func(serial uint32, modsDepressed uint32, modsLatched uint32, modsLocked uint32, group uint32) error
+}
+
+func (l ClientKeyboardListener) Keymap(format KeyboardKeymapFormat, fd uintptr, size uint32) error {
+	if l.OnKeymap == nil {
+		syscall.Close(int(fd))
+		return nil
+	}
+	return l.OnKeymap(format, fd, size)
+}
+
+func (l ClientKeyboardListener) Enter(serial uint32, surface *ClientSurface, keys []byte) error {
+	if l.OnEnter == nil {
+		return nil
+	}
+	return l.OnEnter(serial, surface, keys)
+}
+
+func (l ClientKeyboardListener) Leave(serial uint32, surface *ClientSurface) error {
+	if l.OnLeave == nil {
+		return nil
+	}
+	return l.OnLeave(serial, surface)
+}
+
+func (l ClientKeyboardListener) Key(serial uint32, time uint32, key uint32, state KeyboardKeyState) error {
+	if l.OnKey == nil {
+		return nil
+	}
+	return l.OnKey(serial, time, key, state)
+}
+
+func (l ClientKeyboardListener) Modifiers(serial uint32, modsDepressed uint32, modsLatched uint32, modsLocked uint32, group uint32) error {
+	if l.OnModifiers == nil {
+		return nil
+	}
+	return l.OnModifiers(serial, modsDepressed, modsLatched, modsLocked, group)
+}
+
+type ClientKeyboard struct {
+	c         *proto.Conn
+	id        proto.ObjectId
+	version   uint32
+	i         ClientKeyboardImplementation
+	destroyed bool
+}
+
+// NewKeyboard creates an object at the given version, which is the
+// version it was bound at or the version of the object that created it.
+func (c Client) NewKeyboard(version uint32, i ClientKeyboardImplementation) *ClientKeyboard {
+	return c.NewKeyboardWithId(c.c.NextId(), version, i)
+}
+
+// NewKeyboardWithId registers an object created by the other end
+// of the connection under the id it has chosen.
+func (c Client) NewKeyboardWithId(id proto.ObjectId, version uint32, i ClientKeyboardImplementation) *ClientKeyboard {
+	o := &ClientKeyboard{
+		c:       c.c,
+		id:      id,
+		version: version,
+		i:       i,
+	}
+	c.c.AddObject(o.id, o)
+	return o
+}
+
+func (o *ClientKeyboard) Id() proto.ObjectId {
+	return o.id
+}
+
+func (o *ClientKeyboard) Version() uint32 {
+	return o.version
+}
+
+func (o *ClientKeyboard) Conn() *proto.Conn {
+	return o.c
+}
+
+func (o *ClientKeyboard) Interface() *proto.Interface {
+	return KeyboardInterface
+}
+
+func (o *ClientKeyboard) Handle(m *proto.Message) (err error) {
+	switch m.Opcode() {
+
+	case 0:
+
+		var (
+			format KeyboardKeymapFormat
+
+			fd uintptr
+
+			size uint32
+		)
+
+		var formatRaw uint32
+		if formatRaw, err = m.ReadUint(); err != nil {
+			return
+		}
+		format = KeyboardKeymapFormat(formatRaw)
+
+		if fd, err = m.ReadFd(); err != nil {
+			return
+		}
+
+		if size, err = m.ReadUint(); err != nil {
+			return
+		}
+
+		return o.i.Keymap(format, fd, size)
+
+	case 1:
+
+		var (
+			serial uint32
+
+			surface *ClientSurface
+
+			keys []byte
+		)
+
+		if serial, err = m.ReadUint(); err != nil {
+			return
+		}
+
+		var surfaceObj proto.Object
+		if surfaceObj, err = m.ReadObject(false); err != nil {
+			return fmt.Errorf("wl_keyboard.enter: argument surface: %w", err)
+		}
+		if surfaceObj != nil {
+			var ok bool
+			if surface, ok = surfaceObj.(*ClientSurface); !ok {
+				return fmt.Errorf("wl_keyboard.enter: argument surface: %T is not a wl_surface", surfaceObj)
+			}
+		}
+
+		if keys, err = m.ReadArray(); err != nil {
+			return
+		}
+
+		return o.i.Enter(serial, surface, keys)
+
+	case 2:
+
+		var (
+			serial uint32
+
+			surface *ClientSurface
+		)
+
+		if serial, err = m.ReadUint(); err != nil {
+			return
+		}
+
+		var surfaceObj proto.Object
+		if surfaceObj, err = m.ReadObject(false); err != nil {
+			return fmt.Errorf("wl_keyboard.leave: argument surface: %w", err)
+		}
+		if surfaceObj != nil {
+			var ok bool
+			if surface, ok = surfaceObj.(*ClientSurface); !ok {
+				return fmt.Errorf("wl_keyboard.leave: argument surface: %T is not a wl_surface", surfaceObj)
+			}
+		}
+
+		return o.i.Leave(serial, surface)
+
+	case 3:
+
+		var (
+			serial uint32
+
+			time uint32
+
+			key uint32
+
+			state KeyboardKeyState
+		)
+
+		if serial, err = m.ReadUint(); err != nil {
+			return
+		}
+
+		if time, err = m.ReadUint(); err != nil {
+			return
+		}
+
+		if key, err = m.ReadUint(); err != nil {
+			return
+		}
+
+		var stateRaw uint32
+		if stateRaw, err = m.ReadUint(); err != nil {
+			return
+		}
+		state = KeyboardKeyState(stateRaw)
+
+		return o.i.Key(serial, time, key, state)
+
+	case 4:
+
+		var (
+			serial uint32
+
+			modsDepressed uint32
+
+			modsLatched uint32
+
+			modsLocked uint32
+
+			group uint32
+		)
+
+		if serial, err = m.ReadUint(); err != nil {
+			return
+		}
+
+		if modsDepressed, err = m.ReadUint(); err != nil {
+			return
+		}
+
+		if modsLatched, err = m.ReadUint(); err != nil {
+			return
+		}
+
+		if modsLocked, err = m.ReadUint(); err != nil {
+			return
+		}
+
+		if group, err = m.ReadUint(); err != nil {
+			return
+		}
+
+		return o.i.Modifiers(serial, modsDepressed, modsLatched, modsLocked, group)
+
+	default:
+		return fmt.Errorf("Keyboard: invalid event opcode: %d", m.Opcode())
+	}
+}
+
+func (o *ClientKeyboard) Release() error {
+	if o.destroyed {
+		return fmt.Errorf("wl_keyboard@%d.release: %w", o.id, proto.ErrDestroyed)
+	}
+	if o.version < KEYBOARD_RELEASE_SINCE_VERSION {
+		return &proto.VersionError{
+			ObjectId:  o.id,
+			Interface: "wl_keyboard",
+			Request:   "release",
+			Since:     KEYBOARD_RELEASE_SINCE_VERSION,
+			Version:   o.version,
+		}
+	}
+	m := proto.NewMessage(o.id, 0)
+
+	if err := o.c.WriteMessage(m); err != nil {
+		return err
+	}
+	o.destroyed = true
+	return o.c.DestroyObject(o.id)
+}
+
+type ServerKeyboardImplementation interface {
+
+	//
+	Release() error
+}
+
+type ServerKeyboard struct {
+	c         *proto.Conn
+	id        proto.ObjectId
+	version   uint32
+	i         ServerKeyboardImplementation
+	destroyed bool
+}
+
+// NewKeyboard creates an object at the given version, which is the
+// version it was bound at or the version of the object that created it.
+func (c Server) NewKeyboard(version uint32, i ServerKeyboardImplementation) *ServerKeyboard {
+	return c.NewKeyboardWithId(c.c.NextId(), version, i)
+}
+
+// NewKeyboardWithId registers an object created by the other end
+// of the connection under the id it has chosen.
+func (c Server) NewKeyboardWithId(id proto.ObjectId, version uint32, i ServerKeyboardImplementation) *ServerKeyboard {
+	o := &ServerKeyboard{
+		c:       c.c,
+		id:      id,
+		version: version,
+		i:       i,
+	}
+	c.c.AddObject(o.id, o)
+	return o
+}
+
+func (o *ServerKeyboard) Id() proto.ObjectId {
+	return o.id
+}
+
+func (o *ServerKeyboard) Version() uint32 {
+	return o.version
+}
+
+func (o *ServerKeyboard) Conn() *proto.Conn {
+	return o.c
+}
+
+func (o *ServerKeyboard) Interface() *proto.Interface {
+	return KeyboardInterface
+}
+
+func (o *ServerKeyboard) Handle(m *proto.Message) (err error) {
+	switch m.Opcode() {
+
+	case 0:
+
+		var ()
+
+		err = o.i.Release()
+		o.destroyed = true
+		if derr := o.c.DestroyObject(o.id); err == nil {
+			err = derr
+		}
+		return
+
+	default:
+		return fmt.Errorf("Keyboard: invalid request opcode: %d", m.Opcode())
+	}
+}
+
+// This event provides a file descriptor to the client which can be
+// memory-mapped to provide a keyboard mapping description.
+func (o *ServerKeyboard) Keymap(format KeyboardKeymapFormat, fd uintptr, size uint32) error {
+	if o.destroyed {
+		return fmt.Errorf("wl_keyboard@%d.keymap: %w", o.id, proto.ErrDestroyed)
+	}
+	m := proto.NewMessage(o.id, 0)
+
+	if err := m.WriteUint(uint32(format)); err != nil {
+		return err
+	}
+
+	if err := m.WriteFd(fd); err != nil {
+		return err
+	}
+
+	if err := m.WriteUint(size); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// Notification that this seat's keyboard focus is on a certain
+// surface.
+func (o *ServerKeyboard) Enter(serial uint32, surface *ServerSurface, keys []byte) error {
+	if o.destroyed {
+		return fmt.Errorf("wl_keyboard@%d.enter: %w", o.id, proto.ErrDestroyed)
+	}
+	var surfaceId proto.ObjectId
+	if surface != nil {
+		surfaceId = surface.Id()
+	} else {
+		return fmt.Errorf("wl_keyboard.enter: argument surface is null")
+	}
+	m := proto.NewMessage(o.id, 1)
+
+	if err := m.WriteUint(serial); err != nil {
+		return err
+	}
+
+	if err := m.WriteObjectId(surfaceId); err != nil {
+		return err
+	}
+
+	if err := m.WriteArray(keys); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// Notification that this seat's keyboard focus is no longer on
+// a certain surface.
+//
+// The leave notification is sent before the enter notification
+// for the new focus.
+func (o *ServerKeyboard) Leave(serial uint32, surface *ServerSurface) error {
+	if o.destroyed {
+		return fmt.Errorf("wl_keyboard@%d.leave: %w", o.id, proto.ErrDestroyed)
+	}
+	var surfaceId proto.ObjectId
+	if surface != nil {
+		surfaceId = surface.Id()
+	} else {
+		return fmt.Errorf("wl_keyboard.leave: argument surface is null")
+	}
+	m := proto.NewMessage(o.id, 2)
+
+	if err := m.WriteUint(serial); err != nil {
+		return err
+	}
+
+	if err := m.WriteObjectId(surfaceId); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// A key was pressed or released.
+// The time argument is a timestamp with millisecond
+// granularity, with an undefined base.
+func (o *ServerKeyboard) Key(serial uint32, time uint32, key uint32, state KeyboardKeyState) error {
+	if o.destroyed {
+		return fmt.Errorf("wl_keyboard@%d.key: %w", o.id, proto.ErrDestroyed)
+	}
+	m := proto.NewMessage(o.id, 3)
+
+	if err := m.WriteUint(serial); err != nil {
+		return err
+	}
+
+	if err := m.WriteUint(time); err != nil {
+		return err
+	}
+
+	if err := m.WriteUint(key); err != nil {
+		return err
+	}
+
+	if err := m.WriteUint(uint32(state)); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// Notifies clients that the modifier and/or group state has
+// changed, and it should update its local state.
+func (o *ServerKeyboard) Modifiers(serial uint32, modsDepressed uint32, modsLatched uint32, modsLocked uint32, group uint32) error {
+	if o.destroyed {
+		return fmt.Errorf("wl_keyboard@%d.modifiers: %w", o.id, proto.ErrDestroyed)
+	}
+	m := proto.NewMessage(o.id, 4)
+
+	if err := m.WriteUint(serial); err != nil {
+		return err
+	}
+
+	if err := m.WriteUint(modsDepressed); err != nil {
+		return err
+	}
+
+	if err := m.WriteUint(modsLatched); err != nil {
+		return err
+	}
+
+	if err := m.WriteUint(modsLocked); err != nil {
+		return err
+	}
+
+	if err := m.WriteUint(group); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// Touch
+//
+// The wl_touch interface represents a touchscreen
+// associated with a seat.
+//
+// Touch interactions can consist of one or more contacts.
+// For each contact, a series of events is generated, starting
+// with a down event, followed by zero or more motion events,
+// and ending with an up event. Events relating to the same
+// contact point can be identified by the ID of the sequence.
+//
+
+// Versions that introduced each request and event.
+const (
+	TOUCH_RELEASE_SINCE_VERSION = 3
+	TOUCH_DOWN_SINCE_VERSION    = 1
+	TOUCH_UP_SINCE_VERSION      = 1
+	TOUCH_MOTION_SINCE_VERSION  = 1
+	TOUCH_FRAME_SINCE_VERSION   = 1
+	TOUCH_CANCEL_SINCE_VERSION  = 1
+)
+
+var TouchInterface = &proto.Interface{
+	Name:    "wl_touch",
+	Version: 3,
+	Requests: []proto.Signature{
+		{Name: "release", Since: 3, Args: []proto.ArgDesc{}},
+	},
+	Events: []proto.Signature{
+		{Name: "down", Since: 1, Args: []proto.ArgDesc{
+			{Name: "serial", Type: proto.ArgUint, Interface: "", Nullable: false},
+			{Name: "time", Type: proto.ArgUint, Interface: "", Nullable: false},
+			{Name: "surface", Type: proto.ArgObject, Interface: "wl_surface", Nullable: false},
+			{Name: "id", Type: proto.ArgInt, Interface: "", Nullable: false},
+			{Name: "x", Type: proto.ArgFixed, Interface: "", Nullable: false},
+			{Name: "y", Type: proto.ArgFixed, Interface: "", Nullable: false},
+		}},
+		{Name: "up", Since: 1, Args: []proto.ArgDesc{
+			{Name: "serial", Type: proto.ArgUint, Interface: "", Nullable: false},
+			{Name: "time", Type: proto.ArgUint, Interface: "", Nullable: false},
+			{Name: "id", Type: proto.ArgInt, Interface: "", Nullable: false},
+		}},
+		{Name: "motion", Since: 1, Args: []proto.ArgDesc{
+			{Name: "time", Type: proto.ArgUint, Interface: "", Nullable: false},
+			{Name: "id", Type: proto.ArgInt, Interface: "", Nullable: false},
+			{Name: "x", Type: proto.ArgFixed, Interface: "", Nullable: false},
+			{Name: "y", Type: proto.ArgFixed, Interface: "", Nullable: false},
+		}},
+		{Name: "frame", Since: 1, Args: []proto.ArgDesc{}},
+		{Name: "cancel", Since: 1, Args: []proto.ArgDesc{}},
+	},
+}
+
+func init() {
+	proto.RegisterInterface(TouchInterface)
+}
+
+type ClientTouchImplementation interface {
+
+	//
+	// A new touch point has appeared on the surface. This touch point is
+	// assigned a unique @id. Future events from this touchpoint reference
+	// this ID. The ID ceases to be valid after a touch up event and may be
+	// re-used in the future.
+	//
+	Down(serial uint32, time uint32, surface *ClientSurface, id int32, x proto.Fixed, y proto.Fixed) error
+
+	//
+	// The touch point has disappeared. No further events will be sent for
+	// this touchpoint and the touch point's ID is released and may be
+	// re-used in a future touch down event.
+	//
+	Up(serial uint32, time uint32, id int32) error
+
+	//
+	// A touchpoint has changed coordinates.
+	//
+	Motion(time uint32, id int32, x proto.Fixed, y proto.Fixed) error
+
+	//
+	// Indicates the end of a contact point list.
+	//
+	Frame() error
+
+	//
+	// Sent if the compositor decides the touch stream is a global
+	// gesture. No further events are sent to the clients from that
+	// particular gesture. Touch cancellation applies to all touch points
+	// currently active on this client's surface. The client is
+	// responsible for finalizing the touch points, future touch points on
+	// this surface may re-use the touch point ID.
+	//
+	Cancel() error
+}
+
+// ClientTouchListener implements ClientTouchImplementation with a
+// function per event. Events whose function is nil are ignored, and fds
+// they carry are closed.
+type ClientTouchListener struct {
+	OnDown   func(serial uint32, time uint32, surface *ClientSurface, id int32, x proto.Fixed, y proto.Fixed) error
+	OnUp     func(serial uint32, time uint32, id int32) error
+	OnMotion func(time uint32, id int32, x proto.Fixed, y proto.Fixed) error
+	OnFrame  func() error
+	OnCancel func() error
+}
+
+func (l ClientTouchListener) Down(serial uint32, time uint32, surface *ClientSurface, id int32, x proto.Fixed, y proto.Fixed) error {
+	if l.OnDown == nil {
+		return nil
+	}
+	return l.OnDown(serial, time, surface, id, x, y)
+}
+
+func (l ClientTouchListener) Up(serial uint32, time uint32, id int32) error {
+	if l.OnUp == nil {
+		return nil
+	}
+	return l.OnUp(serial, time, id)
+}
+
+func (l ClientTouchListener) Motion(time uint32, id int32, x proto.Fixed, y proto.Fixed) error {
+	if l.OnMotion == nil {
+		return nil
+	}
+	return l.OnMotion(time, id, x, y)
+}
+
+func (l ClientTouchListener) Frame() error {
+	if l.OnFrame == nil {
+		return nil
+	}
+	return l.OnFrame()
+}
+
+func (l ClientTouchListener) Cancel() error {
+	if l.OnCancel == nil {
+		return nil
+	}
+	return l.OnCancel()
+}
+
+type ClientTouch struct {
+	c         *proto.Conn
+	id        proto.ObjectId
+	version   uint32
+	i         ClientTouchImplementation
+	destroyed bool
+}
+
+// NewTouch creates an object at the given version, which is the
+// version it was bound at or the version of the object that created it.
+func (c Client) NewTouch(version uint32, i ClientTouchImplementation) *ClientTouch {
+	return c.NewTouchWithId(c.c.NextId(), version, i)
+}
+
+// NewTouchWithId registers an object created by the other end
+// of the connection under the id it has chosen.
+func (c Client) NewTouchWithId(id proto.ObjectId, version uint32, i ClientTouchImplementation) *ClientTouch {
+	o := &ClientTouch{
+		c:       c.c,
+		id:      id,
+		version: version,
+		i:       i,
+	}
+	c.c.AddObject(o.id, o)
+	return o
+}
+
+func (o *ClientTouch) Id() proto.ObjectId {
+	return o.id
+}
+
+func (o *ClientTouch) Version() uint32 {
+	return o.version
+}
+
+func (o *ClientTouch) Conn() *proto.Conn {
+	return o.c
+}
+
+func (o *ClientTouch) Interface() *proto.Interface {
+	return TouchInterface
+}
+
+func (o *ClientTouch) Handle(m *proto.Message) (err error) {
+	switch m.Opcode() {
+
+	case 0:
+
+		var (
+			serial uint32
+
+			time uint32
+
+			surface *ClientSurface
+
+			id int32
+
+			x proto.Fixed
+
+			y proto.Fixed
+		)
+
+		if serial, err = m.ReadUint(); err != nil {
+			return
+		}
+
+		if time, err = m.ReadUint(); err != nil {
+			return
+		}
+
+		var surfaceObj proto.Object
+		if surfaceObj, err = m.ReadObject(false); err != nil {
+			return fmt.Errorf("wl_touch.down: argument surface: %w", err)
+		}
+		if surfaceObj != nil {
+			var ok bool
+			if surface, ok = surfaceObj.(*ClientSurface); !ok {
+				return fmt.Errorf("wl_touch.down: argument surface: %T is not a wl_surface", surfaceObj)
+			}
+		}
+
+		if id, err = m.ReadInt(); err != nil {
+			return
+		}
+
+		if x, err = m.ReadFixed(); err != nil {
+			return
+		}
+
+		if y, err = m.ReadFixed(); err != nil {
+			return
+		}
+
+		return o.i.Down(serial, time, surface, id, x, y)
+
+	case 1:
+
+		var (
+			serial uint32
+
+			time uint32
+
+			id int32
+		)
+
+		if serial, err = m.ReadUint(); err != nil {
+			return
+		}
+
+		if time, err = m.ReadUint(); err != nil {
+			return
+		}
+
+		if id, err = m.ReadInt(); err != nil {
+			return
+		}
+
+		return o.i.Up(serial, time, id)
+
+	case 2:
+
+		var (
+			time uint32
+
+			id int32
+
+			x proto.Fixed
+
+			y proto.Fixed
+		)
+
+		if time, err = m.ReadUint(); err != nil {
+			return
+		}
+
+		if id, err = m.ReadInt(); err != nil {
+			return
+		}
+
+		if x, err = m.ReadFixed(); err != nil {
+			return
+		}
+
+		if y, err = m.ReadFixed(); err != nil {
+			return
+		}
+
+		return o.i.Motion(time, id, x, y)
+
+	case 3:
+
+		var ()
+
+		return o.i.Frame()
+
+	case 4:
+
+		var ()
+
+		return o.i.Cancel()
+
+	default:
+		return fmt.Errorf("Touch: invalid event opcode: %d", m.Opcode())
+	}
+}
+
+func (o *ClientTouch) Release() error {
+	if o.destroyed {
+		return fmt.Errorf("wl_touch@%d.release: %w", o.id, proto.ErrDestroyed)
+	}
+	if o.version < TOUCH_RELEASE_SINCE_VERSION {
+		return &proto.VersionError{
+			ObjectId:  o.id,
+			Interface: "wl_touch",
+			Request:   "release",
+			Since:     TOUCH_RELEASE_SINCE_VERSION,
+			Version:   o.version,
+		}
+	}
+	m := proto.NewMessage(o.id, 0)
+
+	if err := o.c.WriteMessage(m); err != nil {
+		return err
+	}
+	o.destroyed = true
+	return o.c.DestroyObject(o.id)
+}
+
+type ServerTouchImplementation interface {
+
+	//
+	Release() error
+}
+
+type ServerTouch struct {
+	c         *proto.Conn
+	id        proto.ObjectId
+	version   uint32
+	i         ServerTouchImplementation
+	destroyed bool
+}
+
+// NewTouch creates an object at the given version, which is the
+// version it was bound at or the version of the object that created it.
+func (c Server) NewTouch(version uint32, i ServerTouchImplementation) *ServerTouch {
+	return c.NewTouchWithId(c.c.NextId(), version, i)
+}
+
+// NewTouchWithId registers an object created by the other end
+// of the connection under the id it has chosen.
+func (c Server) NewTouchWithId(id proto.ObjectId, version uint32, i ServerTouchImplementation) *ServerTouch {
+	o := &ServerTouch{
+		c:       c.c,
+		id:      id,
+		version: version,
+		i:       i,
+	}
+	c.c.AddObject(o.id, o)
+	return o
+}
+
+func (o *ServerTouch) Id() proto.ObjectId {
+	return o.id
+}
+
+func (o *ServerTouch) Version() uint32 {
+	return o.version
+}
+
+func (o *ServerTouch) Conn() *proto.Conn {
+	return o.c
+}
+
+func (o *ServerTouch) Interface() *proto.Interface {
+	return TouchInterface
+}
+
+func (o *ServerTouch) Handle(m *proto.Message) (err error) {
+	switch m.Opcode() {
+
+	case 0:
+
+		var ()
+
+		err = o.i.Release()
+		o.destroyed = true
+		if derr := o.c.DestroyObject(o.id); err == nil {
+			err = derr
+		}
+		return
+
+	default:
+		return fmt.Errorf("Touch: invalid request opcode: %d", m.Opcode())
+	}
+}
+
+// A new touch point has appeared on the surface. This touch point is
+// assigned a unique @id. Future events from this touchpoint reference
+// this ID. The ID ceases to be valid after a touch up event and may be
+// re-used in the future.
+func (o *ServerTouch) Down(serial uint32, time uint32, surface *ServerSurface, id int32, x proto.Fixed, y proto.Fixed) error {
+	if o.destroyed {
+		return fmt.Errorf("wl_touch@%d.down: %w", o.id, proto.ErrDestroyed)
+	}
+	var surfaceId proto.ObjectId
+	if surface != nil {
+		surfaceId = surface.Id()
+	} else {
+		return fmt.Errorf("wl_touch.down: argument surface is null")
+	}
+	m := proto.NewMessage(o.id, 0)
+
+	if err := m.WriteUint(serial); err != nil {
+		return err
+	}
+
+	if err := m.WriteUint(time); err != nil {
+		return err
+	}
+
+	if err := m.WriteObjectId(surfaceId); err != nil {
+		return err
+	}
+
+	if err := m.WriteInt(id); err != nil {
+		return err
+	}
+
+	if err := m.WriteFixed(x); err != nil {
+		return err
+	}
+
+	if err := m.WriteFixed(y); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// The touch point has disappeared. No further events will be sent for
+// this touchpoint and the touch point's ID is released and may be
+// re-used in a future touch down event.
+func (o *ServerTouch) Up(serial uint32, time uint32, id int32) error {
+	if o.destroyed {
+		return fmt.Errorf("wl_touch@%d.up: %w", o.id, proto.ErrDestroyed)
+	}
+	m := proto.NewMessage(o.id, 1)
+
+	if err := m.WriteUint(serial); err != nil {
+		return err
+	}
+
+	if err := m.WriteUint(time); err != nil {
+		return err
+	}
+
+	if err := m.WriteInt(id); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// A touchpoint has changed coordinates.
+func (o *ServerTouch) Motion(time uint32, id int32, x proto.Fixed, y proto.Fixed) error {
+	if o.destroyed {
+		return fmt.Errorf("wl_touch@%d.motion: %w", o.id, proto.ErrDestroyed)
+	}
+	m := proto.NewMessage(o.id, 2)
+
+	if err := m.WriteUint(time); err != nil {
+		return err
+	}
+
+	if err := m.WriteInt(id); err != nil {
+		return err
+	}
+
+	if err := m.WriteFixed(x); err != nil {
+		return err
+	}
+
+	if err := m.WriteFixed(y); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// Indicates the end of a contact point list.
+func (o *ServerTouch) Frame() error {
+	if o.destroyed {
+		return fmt.Errorf("wl_touch@%d.frame: %w", o.id, proto.ErrDestroyed)
+	}
+	m := proto.NewMessage(o.id, 3)
+
+	return o.c.WriteMessage(m)
+}
+
+// Sent if the compositor decides the touch stream is a global
+// gesture. No further events are sent to the clients from that
+// particular gesture. Touch cancellation applies to all touch points
+// currently active on this client's surface. The client is
+// responsible for finalizing the touch points, future touch points on
+// this surface may re-use the touch point ID.
+func (o *ServerTouch) Cancel() error {
+	if o.destroyed {
+		return fmt.Errorf("wl_touch@%d.cancel: %w", o.id, proto.ErrDestroyed)
+	}
+	m := proto.NewMessage(o.id, 4)
+
+	return o.c.WriteMessage(m)
+}
+
+// Output
+//
+// An output describes part of the compositor geometry.  The
+// compositor works in the 'compositor coordinate system' and an
+// output corresponds to rectangular area in that space that is
+// actually visible.  This typically corresponds to a monitor that
+// displays part of the compositor space.  This object is published
+// as global during start up, or when a monitor is hotplugged.
+//
+
+// This enumeration describes how the physical
+// pixels on an output are layed out.
+type OutputSubpixel uint32
+
+const (
+
+	//
+	OUTPUT_SUBPIXEL_UNKNOWN OutputSubpixel = 0
+
+	//
+	OUTPUT_SUBPIXEL_NONE OutputSubpixel = 1
+
+	//
+	OUTPUT_SUBPIXEL_HORIZONTAL_RGB OutputSubpixel = 2
+
+	//
+	OUTPUT_SUBPIXEL_HORIZONTAL_BGR OutputSubpixel = 3
+
+	//
+	OUTPUT_SUBPIXEL_VERTICAL_RGB OutputSubpixel = 4
+
+	//
+	OUTPUT_SUBPIXEL_VERTICAL_BGR OutputSubpixel = 5
+)
+
+func (e OutputSubpixel) String() string {
+	switch e {
+	case OUTPUT_SUBPIXEL_UNKNOWN:
+		return "unknown"
+	case OUTPUT_SUBPIXEL_NONE:
+		return "none"
+	case OUTPUT_SUBPIXEL_HORIZONTAL_RGB:
+		return "horizontal_rgb"
+	case OUTPUT_SUBPIXEL_HORIZONTAL_BGR:
+		return "horizontal_bgr"
+	case OUTPUT_SUBPIXEL_VERTICAL_RGB:
+		return "vertical_rgb"
+	case OUTPUT_SUBPIXEL_VERTICAL_BGR:
+		return "vertical_bgr"
+	}
+	return fmt.Sprintf("OutputSubpixel(%d)", uint32(e))
+}
+
+// This describes the transform that a compositor will apply to a
+// surface to compensate for the rotation or mirroring of an
+// output device.
+//
+// The flipped values correspond to an initial flip around a
+// vertical axis followed by rotation.
+//
+// The purpose is mainly to allow clients render accordingly and
+// tell the compositor, so that for fullscreen surfaces, the
+// compositor will still be able to scan out directly from client
+// surfaces.
+type OutputTransform uint32
+
+const (
+
+	//
+	OUTPUT_TRANSFORM_NORMAL OutputTransform = 0
+
+	//
+	OUTPUT_TRANSFORM_90 OutputTransform = 1
+
+	//
+	OUTPUT_TRANSFORM_180 OutputTransform = 2
+
+	//
+	OUTPUT_TRANSFORM_270 OutputTransform = 3
+
+	//
+	OUTPUT_TRANSFORM_FLIPPED OutputTransform = 4
+
+	//
+	OUTPUT_TRANSFORM_FLIPPED_90 OutputTransform = 5
+
+	//
+	OUTPUT_TRANSFORM_FLIPPED_180 OutputTransform = 6
+
+	//
+	OUTPUT_TRANSFORM_FLIPPED_270 OutputTransform = 7
+)
+
+func (e OutputTransform) String() string {
+	switch e {
+	case OUTPUT_TRANSFORM_NORMAL:
+		return "normal"
+	case OUTPUT_TRANSFORM_90:
+		return "90"
+	case OUTPUT_TRANSFORM_180:
+		return "180"
+	case OUTPUT_TRANSFORM_270:
+		return "270"
+	case OUTPUT_TRANSFORM_FLIPPED:
+		return "flipped"
+	case OUTPUT_TRANSFORM_FLIPPED_90:
+		return "flipped_90"
+	case OUTPUT_TRANSFORM_FLIPPED_180:
+		return "flipped_180"
+	case OUTPUT_TRANSFORM_FLIPPED_270:
+		return "flipped_270"
+	}
+	return fmt.Sprintf("OutputTransform(%d)", uint32(e))
+}
+
+// These flags describe properties of an output mode.
+// They are used in the flags bitfield of the mode event.
+type OutputMode uint32
+
+const (
+
+	// indicates this is the current mode
+	OUTPUT_MODE_CURRENT OutputMode = 0x1
+
+	// indicates this is the preferred mode
+	OUTPUT_MODE_PREFERRED OutputMode = 0x2
+)
+
+// Has reports whether all flags set in v are set in e.
+func (e OutputMode) Has(v OutputMode) bool {
+	return e&v == v
+}
+
+// String returns the names of the flags set in e, separated by "|".
+func (e OutputMode) String() string {
+	s := ""
+	for _, f := range []struct {
+		v    OutputMode
+		name string
+	}{
+		{OUTPUT_MODE_CURRENT, "current"},
+		{OUTPUT_MODE_PREFERRED, "preferred"},
+	} {
+		if e&f.v == f.v {
+			if s != "" {
+				s += "|"
+			}
+			s += f.name
+			e &^= f.v
+		}
+	}
+	if e != 0 || s == "" {
+		if s != "" {
+			s += "|"
+		}
+		s += fmt.Sprintf("%#x", uint32(e))
+	}
+	return s
+}
+
+// Versions that introduced each request and event.
+const (
+	OUTPUT_GEOMETRY_SINCE_VERSION = 1
+	OUTPUT_MODE_SINCE_VERSION     = 1
+	OUTPUT_DONE_SINCE_VERSION     = 2
+	OUTPUT_SCALE_SINCE_VERSION    = 2
+)
+
+var OutputInterface = &proto.Interface{
+	Name:     "wl_output",
+	Version:  2,
+	Requests: []proto.Signature{},
+	Events: []proto.Signature{
+		{Name: "geometry", Since: 1, Args: []proto.ArgDesc{
+			{Name: "x", Type: proto.ArgInt, Interface: "", Nullable: false},
+			{Name: "y", Type: proto.ArgInt, Interface: "", Nullable: false},
+			{Name: "physical_width", Type: proto.ArgInt, Interface: "", Nullable: false},
+			{Name: "physical_height", Type: proto.ArgInt, Interface: "", Nullable: false},
+			{Name: "subpixel", Type: proto.ArgInt, Interface: "", Nullable: false},
+			{Name: "make", Type: proto.ArgString, Interface: "", Nullable: false},
+			{Name: "model", Type: proto.ArgString, Interface: "", Nullable: false},
+			{Name: "transform", Type: proto.ArgInt, Interface: "", Nullable: false},
+		}},
+		{Name: "mode", Since: 1, Args: []proto.ArgDesc{
+			{Name: "flags", Type: proto.ArgUint, Interface: "", Nullable: false},
+			{Name: "width", Type: proto.ArgInt, Interface: "", Nullable: false},
+			{Name: "height", Type: proto.ArgInt, Interface: "", Nullable: false},
+			{Name: "refresh", Type: proto.ArgInt, Interface: "", Nullable: false},
+		}},
+		{Name: "done", Since: 2, Args: []proto.ArgDesc{}},
+		{Name: "scale", Since: 2, Args: []proto.ArgDesc{
+			{Name: "factor", Type: proto.ArgInt, Interface: "", Nullable: false},
+		}},
+	},
+}
+
+func init() {
+	proto.RegisterInterface(OutputInterface)
+}
+
+type ClientOutputImplementation interface {
+
+	//
+	// The geometry event describes geometric properties of the output.
+	// The event is sent when binding to the output object and whenever
+	// any of the properties change.
+	//
+	Geometry(x int32, y int32, physicalWidth int32, physicalHeight int32, subpixel OutputSubpixel, make string, model string, transform OutputTransform) error
+
+	//
+	// The mode event describes an available mode for the output.
+	//
+	// The event is sent when binding to the output object and there
+	// will always be one mode, the current mode.  The event is sent
+	// again if an output changes mode, for the mode that is now
+	// current.  In other words, the current mode is always the last
+	// mode that was received with the current flag set.
+	//
+	// The size of a mode is given in physical hardware units of
+	// the output device. This is not necessarily the same as
+	// the output size in the global compositor space. For instance,
+	// the output may be scaled, as described in wl_output.scale,
+	// or transformed , as described in wl_output.transform.
+	//
+	Mode(flags OutputMode, width int32, height int32, refresh int32) error
+
+	//
+	// This event is sent after all other properties has been
+	// sent after binding to the output object and after any
+	// other property changes done after that. This allows
+	// changes to the output properties to be seen as
+	// atomic, even if they happen via multiple events.
+	//
+	Done() error
+
+	//
+	// This event contains scaling geometry information
+	// that is not in the geometry event. It may be sent after
+	// binding the output object or if the output scale changes
+	// later. If it is not sent, the client should assume a
+	// scale of 1.
+	//
+	// A scale larger than 1 means that the compositor will
+	// automatically scale surface buffers by this amount
+	// when rendering. This is used for very high resolution
+	// displays where applications rendering at the native
+	// resolution would be too small to be legible.
+	//
+	// It is intended that scaling aware clients track the
+	// current output of a surface, and if it is on a scaled
+	// output it should use wl_surface.set_buffer_scale with
+	// the scale of the output. That way the compositor can
+	// avoid scaling the surface, and the client can supply
+	// a higher detail image.
+	//
+	Scale(factor int32) error
+}
+
+// ClientOutputListener implements ClientOutputImplementation with a
+// function per event. Events whose function is nil are ignored, and fds
+// they carry are closed.
+type ClientOutputListener struct {
+	OnGeometry func(x int32, y int32, physicalWidth int32, physicalHeight int32, subpixel OutputSubpixel, make string, model string, transform OutputTransform) error
+	OnMode     func(flags OutputMode, width int32, height int32, refresh int32) error
+	OnDone     func() error
+	OnScale    func(factor int32) error
+}
+
+func (l ClientOutputListener) Geometry(x int32, y int32, physicalWidth int32, physicalHeight int32, subpixel OutputSubpixel, make string, model string, transform OutputTransform) error {
+	if l.OnGeometry == nil {
+		return nil
+	}
+	return l.OnGeometry(x, y, physicalWidth, physicalHeight, subpixel, make, model, transform)
+}
+
+func (l ClientOutputListener) Mode(flags OutputMode, width int32, height int32, refresh int32) error {
+	if l.OnMode == nil {
+		return nil
+	}
+	return l.OnMode(flags, width, height, refresh)
+}
+
+func (l ClientOutputListener) Done() error {
+	if l.OnDone == nil {
+		return nil
+	}
+	return l.OnDone()
+}
+
+func (l ClientOutputListener) Scale(factor int32) error {
+	if l.OnScale == nil {
+		return nil
+	}
+	return l.OnScale(factor)
+}
+
+type ClientOutput struct {
+	c       *proto.Conn
+	id      proto.ObjectId
+	version uint32
+	i       ClientOutputImplementation
+}
+
+// NewOutput creates an object at the given version, which is the
+// version it was bound at or the version of the object that created it.
+func (c Client) NewOutput(version uint32, i ClientOutputImplementation) *ClientOutput {
+	return c.NewOutputWithId(c.c.NextId(), version, i)
+}
+
+// NewOutputWithId registers an object created by the other end
+// of the connection under the id it has chosen.
+func (c Client) NewOutputWithId(id proto.ObjectId, version uint32, i ClientOutputImplementation) *ClientOutput {
+	o := &ClientOutput{
+		c:       c.c,
+		id:      id,
+		version: version,
+		i:       i,
+	}
+	c.c.AddObject(o.id, o)
+	return o
+}
+
+func (o *ClientOutput) Id() proto.ObjectId {
+	return o.id
+}
+
+func (o *ClientOutput) Version() uint32 {
+	return o.version
+}
+
+func (o *ClientOutput) Conn() *proto.Conn {
+	return o.c
+}
+
+func (o *ClientOutput) Interface() *proto.Interface {
+	return OutputInterface
+}
+
+func (o *ClientOutput) Handle(m *proto.Message) (err error) {
+	switch m.Opcode() {
+
+	case 0:
+
+		var (
+			x int32
+
+			y int32
+
+			physicalWidth int32
+
+			physicalHeight int32
+
+			subpixel OutputSubpixel
+
+			make string
+
+			model string
+
+			transform OutputTransform
+		)
+
+		if x, err = m.ReadInt(); err != nil {
+			return
+		}
+
+		if y, err = m.ReadInt(); err != nil {
+			return
+		}
+
+		if physicalWidth, err = m.ReadInt(); err != nil {
+			return
+		}
+
+		if physicalHeight, err = m.ReadInt(); err != nil {
+			return
+		}
+
+		var subpixelRaw int32
+		if subpixelRaw, err = m.ReadInt(); err != nil {
+			return
+		}
+		subpixel = OutputSubpixel(subpixelRaw)
+
+		if make, err = m.ReadString(); err != nil {
+			return
+		}
+
+		if model, err = m.ReadString(); err != nil {
+			return
+		}
+
+		var transformRaw int32
+		if transformRaw, err = m.ReadInt(); err != nil {
+			return
+		}
+		transform = OutputTransform(transformRaw)
+
+		return o.i.Geometry(x, y, physicalWidth, physicalHeight, subpixel, make, model, transform)
+
+	case 1:
+
+		var (
+			flags OutputMode
+
+			width int32
+
+			height int32
+
+			refresh int32
+		)
+
+		var flagsRaw uint32
+		if flagsRaw, err = m.ReadUint(); err != nil {
+			return
+		}
+		flags = OutputMode(flagsRaw)
+
+		if width, err = m.ReadInt(); err != nil {
+			return
+		}
+
+		if height, err = m.ReadInt(); err != nil {
+			return
+		}
+
+		if refresh, err = m.ReadInt(); err != nil {
+			return
+		}
+
+		return o.i.Mode(flags, width, height, refresh)
+
+	case 2:
+
+		var ()
+
+		return o.i.Done()
+
+	case 3:
+
+		var (
+			factor int32
+		)
+
+		if factor, err = m.ReadInt(); err != nil {
+			return
+		}
+
+		return o.i.Scale(factor)
+
+	default:
+		return fmt.Errorf("Output: invalid event opcode: %d", m.Opcode())
+	}
+}
+
+// BindOutput binds global, which must be a wl_output, at the
+// lower of its advertised version and maxVersion, and returns the proxy.
+// maxVersion is capped at 2, the version known to this package.
+func BindOutput(registry proto.Registry, global proto.Global, maxVersion uint32, i ClientOutputImplementation) (*ClientOutput, error) {
+	if maxVersion > 2 {
+		maxVersion = 2
+	}
+	return proto.BindGlobal(registry, global, maxVersion, func(version uint32) *ClientOutput {
+		return NewClient(registry.Conn()).NewOutput(version, i)
+	})
+}
+
+type ServerOutputImplementation interface {
+}
+
+type ServerOutput struct {
+	c       *proto.Conn
+	id      proto.ObjectId
+	version uint32
+	i       ServerOutputImplementation
+}
+
+// NewOutput creates an object at the given version, which is the
+// version it was bound at or the version of the object that created it.
+func (c Server) NewOutput(version uint32, i ServerOutputImplementation) *ServerOutput {
+	return c.NewOutputWithId(c.c.NextId(), version, i)
+}
+
+// NewOutputWithId registers an object created by the other end
+// of the connection under the id it has chosen.
+func (c Server) NewOutputWithId(id proto.ObjectId, version uint32, i ServerOutputImplementation) *ServerOutput {
+	o := &ServerOutput{
+		c:       c.c,
+		id:      id,
+		version: version,
+		i:       i,
+	}
+	c.c.AddObject(o.id, o)
+	return o
+}
+
+func (o *ServerOutput) Id() proto.ObjectId {
+	return o.id
+}
+
+func (o *ServerOutput) Version() uint32 {
+	return o.version
+}
+
+func (o *ServerOutput) Conn() *proto.Conn {
+	return o.c
+}
+
+func (o *ServerOutput) Interface() *proto.Interface {
+	return OutputInterface
+}
+
+func (o *ServerOutput) Handle(m *proto.Message) (err error) {
+	switch m.Opcode() {
+
+	default:
+		return fmt.Errorf("Output: invalid request opcode: %d", m.Opcode())
+	}
+}
+
+// The geometry event describes geometric properties of the output.
+// The event is sent when binding to the output object and whenever
+// any of the properties change.
+func (o *ServerOutput) Geometry(x int32, y int32, physicalWidth int32, physicalHeight int32, subpixel OutputSubpixel, make string, model string, transform OutputTransform) error {
+	m := proto.NewMessage(o.id, 0)
+
+	if err := m.WriteInt(x); err != nil {
+		return err
+	}
+
+	if err := m.WriteInt(y); err != nil {
+		return err
+	}
+
+	if err := m.WriteInt(physicalWidth); err != nil {
+		return err
+	}
+
+	if err := m.WriteInt(physicalHeight); err != nil {
+		return err
+	}
+
+	if err := m.WriteInt(int32(subpixel)); err != nil {
+		return err
+	}
+
+	if err := m.WriteString(make); err != nil {
+		return err
+	}
+
+	if err := m.WriteString(model); err != nil {
+		return err
+	}
+
+	if err := m.WriteInt(int32(transform)); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// The mode event describes an available mode for the output.
+//
+// The event is sent when binding to the output object and there
+// will always be one mode, the current mode.  The event is sent
+// again if an output changes mode, for the mode that is now
+// current.  In other words, the current mode is always the last
+// mode that was received with the current flag set.
+//
+// The size of a mode is given in physical hardware units of
+// the output device. This is not necessarily the same as
+// the output size in the global compositor space. For instance,
+// the output may be scaled, as described in wl_output.scale,
+// or transformed , as described in wl_output.transform.
+func (o *ServerOutput) Mode(flags OutputMode, width int32, height int32, refresh int32) error {
+	m := proto.NewMessage(o.id, 1)
+
+	if err := m.WriteUint(uint32(flags)); err != nil {
+		return err
+	}
+
+	if err := m.WriteInt(width); err != nil {
+		return err
+	}
+
+	if err := m.WriteInt(height); err != nil {
+		return err
+	}
+
+	if err := m.WriteInt(refresh); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// This event is sent after all other properties has been
+// sent after binding to the output object and after any
+// other property changes done after that. This allows
+// changes to the output properties to be seen as
+// atomic, even if they happen via multiple events.
+//
+// The event is not sent to clients that bound a version older than 2.
+func (o *ServerOutput) Done() error {
+	if o.version < OUTPUT_DONE_SINCE_VERSION {
+		return nil
+	}
+	m := proto.NewMessage(o.id, 2)
+
+	return o.c.WriteMessage(m)
+}
+
+// This event contains scaling geometry information
+// that is not in the geometry event. It may be sent after
+// binding the output object or if the output scale changes
+// later. If it is not sent, the client should assume a
+// scale of 1.
+//
+// A scale larger than 1 means that the compositor will
+// automatically scale surface buffers by this amount
+// when rendering. This is used for very high resolution
+// displays where applications rendering at the native
+// resolution would be too small to be legible.
+//
+// It is intended that scaling aware clients track the
+// current output of a surface, and if it is on a scaled
+// output it should use wl_surface.set_buffer_scale with
+// the scale of the output. That way the compositor can
+// avoid scaling the surface, and the client can supply
+// a higher detail image.
+//
+// The event is not sent to clients that bound a version older than 2.
+func (o *ServerOutput) Scale(factor int32) error {
+	if o.version < OUTPUT_SCALE_SINCE_VERSION {
+		return nil
+	}
+	m := proto.NewMessage(o.id, 3)
+
+	if err := m.WriteInt(factor); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// Region
+//
+// A region object describes an area.
+//
+// Region objects are used to describe the opaque and input
+// regions of a surface.
+//
+
+// Versions that introduced each request and event.
+const (
+	REGION_DESTROY_SINCE_VERSION  = 1
+	REGION_ADD_SINCE_VERSION      = 1
+	REGION_SUBTRACT_SINCE_VERSION = 1
+)
+
+var RegionInterface = &proto.Interface{
+	Name:    "wl_region",
+	Version: 1,
+	Requests: []proto.Signature{
+		{Name: "destroy", Since: 1, Args: []proto.ArgDesc{}},
+		{Name: "add", Since: 1, Args: []proto.ArgDesc{
+			{Name: "x", Type: proto.ArgInt, Interface: "", Nullable: false},
+			{Name: "y", Type: proto.ArgInt, Interface: "", Nullable: false},
+			{Name: "width", Type: proto.ArgInt, Interface: "", Nullable: false},
+			{Name: "height", Type: proto.ArgInt, Interface: "", Nullable: false},
+		}},
+		{Name: "subtract", Since: 1, Args: []proto.ArgDesc{
+			{Name: "x", Type: proto.ArgInt, Interface: "", Nullable: false},
+			{Name: "y", Type: proto.ArgInt, Interface: "", Nullable: false},
+			{Name: "width", Type: proto.ArgInt, Interface: "", Nullable: false},
+			{Name: "height", Type: proto.ArgInt, Interface: "", Nullable: false},
+		}},
+	},
+	Events: []proto.Signature{},
+}
+
+func init() {
+	proto.RegisterInterface(RegionInterface)
+}
+
+type ClientRegionImplementation interface {
+}
+
+// ClientRegionListener implements ClientRegionImplementation with a
+// function per event. Events whose function is nil are ignored, and fds
+// they carry are closed.
+type ClientRegionListener struct {
+}
+
+type ClientRegion struct {
+	c         *proto.Conn
+	id        proto.ObjectId
+	version   uint32
+	i         ClientRegionImplementation
+	destroyed bool
+}
+
+// NewRegion creates an object at the given version, which is the
+// version it was bound at or the version of the object that created it.
+func (c Client) NewRegion(version uint32, i ClientRegionImplementation) *ClientRegion {
+	return c.NewRegionWithId(c.c.NextId(), version, i)
+}
+
+// NewRegionWithId registers an object created by the other end
+// of the connection under the id it has chosen.
+func (c Client) NewRegionWithId(id proto.ObjectId, version uint32, i ClientRegionImplementation) *ClientRegion {
+	o := &ClientRegion{
+		c:       c.c,
+		id:      id,
+		version: version,
+		i:       i,
+	}
+	c.c.AddObject(o.id, o)
+	return o
+}
+
+func (o *ClientRegion) Id() proto.ObjectId {
+	return o.id
+}
+
+func (o *ClientRegion) Version() uint32 {
+	return o.version
+}
+
+func (o *ClientRegion) Conn() *proto.Conn {
+	return o.c
+}
+
+func (o *ClientRegion) Interface() *proto.Interface {
+	return RegionInterface
+}
+
+func (o *ClientRegion) Handle(m *proto.Message) (err error) {
+	switch m.Opcode() {
+
+	default:
+		return fmt.Errorf("Region: invalid event opcode: %d", m.Opcode())
+	}
+}
+
+// Destroy the region.  This will invalidate the object ID.
+func (o *ClientRegion) Destroy() error {
+	if o.destroyed {
+		return fmt.Errorf("wl_region@%d.destroy: %w", o.id, proto.ErrDestroyed)
+	}
+	m := proto.NewMessage(o.id, 0)
+
+	if err := o.c.WriteMessage(m); err != nil {
+		return err
+	}
+	o.destroyed = true
+	return o.c.DestroyObject(o.id)
+}
+
+// Add the specified rectangle to the region.
+func (o *ClientRegion) Add(x int32, y int32, width int32, height int32) error {
+	if o.destroyed {
+		return fmt.Errorf("wl_region@%d.add: %w", o.id, proto.ErrDestroyed)
+	}
+	m := proto.NewMessage(o.id, 1)
+
+	if err := m.WriteInt(x); err != nil {
+		return err
+	}
+
+	if err := m.WriteInt(y); err != nil {
+		return err
+	}
+
+	if err := m.WriteInt(width); err != nil {
+		return err
+	}
+
+	if err := m.WriteInt(height); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// Subtract the specified rectangle from the region.
+func (o *ClientRegion) Subtract(x int32, y int32, width int32, height int32) error {
+	if o.destroyed {
+		return fmt.Errorf("wl_region@%d.subtract: %w", o.id, proto.ErrDestroyed)
+	}
+	m := proto.NewMessage(o.id, 2)
+
+	if err := m.WriteInt(x); err != nil {
+		return err
+	}
+
+	if err := m.WriteInt(y); err != nil {
+		return err
+	}
+
+	if err := m.WriteInt(width); err != nil {
+		return err
+	}
+
+	if err := m.WriteInt(height); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+type ServerRegionImplementation interface {
+
+	//
+	// Destroy the region.  This will invalidate the object ID.
+	//
+	Destroy() error
+
+	//
+	// Add the specified rectangle to the region.
+	//
+	Add(x int32, y int32, width int32, height int32) error
+
+	//
+	// Subtract the specified rectangle from the region.
+	//
+	Subtract(x int32, y int32, width int32, height int32) error
+}
+
+type ServerRegion struct {
+	c         *proto.Conn
+	id        proto.ObjectId
+	version   uint32
+	i         ServerRegionImplementation
+	destroyed bool
+}
+
+// NewRegion creates an object at the given version, which is the
+// version it was bound at or the version of the object that created it.
+func (c Server) NewRegion(version uint32, i ServerRegionImplementation) *ServerRegion {
+	return c.NewRegionWithId(c.c.NextId(), version, i)
+}
+
+// NewRegionWithId registers an object created by the other end
+// of the connection under the id it has chosen.
+func (c Server) NewRegionWithId(id proto.ObjectId, version uint32, i ServerRegionImplementation) *ServerRegion {
+	o := &ServerRegion{
+		c:       c.c,
+		id:      id,
+		version: version,
+		i:       i,
+	}
+	c.c.AddObject(o.id, o)
+	return o
+}
+
+func (o *ServerRegion) Id() proto.ObjectId {
+	return o.id
+}
+
+func (o *ServerRegion) Version() uint32 {
+	return o.version
+}
+
+func (o *ServerRegion) Conn() *proto.Conn {
+	return o.c
+}
+
+func (o *ServerRegion) Interface() *proto.Interface {
+	return RegionInterface
+}
+
+func (o *ServerRegion) Handle(m *proto.Message) (err error) {
+	switch m.Opcode() {
+
+	case 0:
+
+		var ()
+
+		err = o.i.Destroy()
+		o.destroyed = true
+		if derr := o.c.DestroyObject(o.id); err == nil {
+			err = derr
+		}
+		return
+
+	case 1:
+
+		var (
+			x int32
+
+			y int32
+
+			width int32
+
+			height int32
+		)
+
+		if x, err = m.ReadInt(); err != nil {
+			return
+		}
+
+		if y, err = m.ReadInt(); err != nil {
+			return
+		}
+
+		if width, err = m.ReadInt(); err != nil {
+			return
+		}
+
+		if height, err = m.ReadInt(); err != nil {
+			return
+		}
+
+		return o.i.Add(x, y, width, height)
+
+	case 2:
+
+		var (
+			x int32
+
+			y int32
+
+			width int32
+
+			height int32
+		)
+
+		if x, err = m.ReadInt(); err != nil {
+			return
+		}
+
+		if y, err = m.ReadInt(); err != nil {
+			return
+		}
+
+		if width, err = m.ReadInt(); err != nil {
+			return
+		}
+
+		if height, err = m.ReadInt(); err != nil {
+			return
+		}
+
+		return o.i.Subtract(x, y, width, height)
+
+	default:
+		return fmt.Errorf("Region: invalid request opcode: %d", m.Opcode())
+	}
+}
+
+// Subcompositor
+//
+// The global interface exposing sub-surface compositing capabilities.
+// A wl_surface, that has sub-surfaces associated, is called the
+// parent surface. Sub-surfaces can be arbitrarily nested and create
+// a tree of sub-surfaces.
+//
+// The root surface in a tree of sub-surfaces is the main
+// surface. The main surface cannot be a sub-surface, because
+// sub-surfaces must always have a parent.
+//
+// A main surface with its sub-surfaces forms a (compound) window.
+// For window management purposes, this set of wl_surface objects is
+// to be considered as a single window, and it should also behave as
+// such.
+//
+// The aim of sub-surfaces is to offload some of the compositing work
+// within a window from clients to the compositor. A prime example is
+// a video player with decorations and video in separate wl_surface
+// objects. This should allow the compositor to pass YUV video buffer
+// processing to dedicated overlay hardware when possible.
+//
+
+type SubcompositorError uint32
+
+const (
+
+	// the to-be sub-surface is invalid
+	SUBCOMPOSITOR_ERROR_BAD_SURFACE SubcompositorError = 0
+)
+
+func (e SubcompositorError) String() string {
+	switch e {
+	case SUBCOMPOSITOR_ERROR_BAD_SURFACE:
+		return "bad_surface"
+	}
+	return fmt.Sprintf("SubcompositorError(%d)", uint32(e))
+}
+
+// Versions that introduced each request and event.
+const (
+	SUBCOMPOSITOR_DESTROY_SINCE_VERSION        = 1
+	SUBCOMPOSITOR_GET_SUBSURFACE_SINCE_VERSION = 1
+)
+
+var SubcompositorInterface = &proto.Interface{
+	Name:    "wl_subcompositor",
+	Version: 1,
+	Requests: []proto.Signature{
+		{Name: "destroy", Since: 1, Args: []proto.ArgDesc{}},
+		{Name: "get_subsurface", Since: 1, Args: []proto.ArgDesc{
+			{Name: "id", Type: proto.ArgNewId, Interface: "wl_subsurface", Nullable: false},
+			{Name: "surface", Type: proto.ArgObject, Interface: "wl_surface", Nullable: false},
+			{Name: "parent", Type: proto.ArgObject, Interface: "wl_surface", Nullable: false},
+		}},
+	},
+	Events: []proto.Signature{},
+}
+
+func init() {
+	proto.RegisterInterface(SubcompositorInterface)
+}
+
+type ClientSubcompositorImplementation interface {
+}
+
+// ClientSubcompositorListener implements ClientSubcompositorImplementation with a
+// function per event. Events whose function is nil are ignored, and fds
+// they carry are closed.
+type ClientSubcompositorListener struct {
+}
+
+type ClientSubcompositor struct {
+	c         *proto.Conn
+	id        proto.ObjectId
+	version   uint32
+	i         ClientSubcompositorImplementation
+	destroyed bool
+}
+
+// NewSubcompositor creates an object at the given version, which is the
+// version it was bound at or the version of the object that created it.
+func (c Client) NewSubcompositor(version uint32, i ClientSubcompositorImplementation) *ClientSubcompositor {
+	return c.NewSubcompositorWithId(c.c.NextId(), version, i)
+}
+
+// NewSubcompositorWithId registers an object created by the other end
+// of the connection under the id it has chosen.
+func (c Client) NewSubcompositorWithId(id proto.ObjectId, version uint32, i ClientSubcompositorImplementation) *ClientSubcompositor {
+	o := &ClientSubcompositor{
+		c:       c.c,
+		id:      id,
+		version: version,
+		i:       i,
+	}
+	c.c.AddObject(o.id, o)
+	return o
+}
+
+func (o *ClientSubcompositor) Id() proto.ObjectId {
+	return o.id
+}
+
+func (o *ClientSubcompositor) Version() uint32 {
+	return o.version
+}
+
+func (o *ClientSubcompositor) Conn() *proto.Conn {
+	return o.c
+}
+
+func (o *ClientSubcompositor) Interface() *proto.Interface {
+	return SubcompositorInterface
+}
+
+func (o *ClientSubcompositor) Handle(m *proto.Message) (err error) {
+	switch m.Opcode() {
+
+	default:
+		return fmt.Errorf("Subcompositor: invalid event opcode: %d", m.Opcode())
+	}
+}
+
+// BindSubcompositor binds global, which must be a wl_subcompositor, at the
+// lower of its advertised version and maxVersion, and returns the proxy.
+// maxVersion is capped at 1, the version known to this package.
+func BindSubcompositor(registry proto.Registry, global proto.Global, maxVersion uint32, i ClientSubcompositorImplementation) (*ClientSubcompositor, error) {
+	if maxVersion > 1 {
+		maxVersion = 1
+	}
+	return proto.BindGlobal(registry, global, maxVersion, func(version uint32) *ClientSubcompositor {
+		return NewClient(registry.Conn()).NewSubcompositor(version, i)
+	})
+}
+
+// Informs the server that the client will not be using this
+// protocol object anymore. This does not affect any other
+// objects, wl_subsurface objects included.
+func (o *ClientSubcompositor) Destroy() error {
+	if o.destroyed {
+		return fmt.Errorf("wl_subcompositor@%d.destroy: %w", o.id, proto.ErrDestroyed)
+	}
+	m := proto.NewMessage(o.id, 0)
+
+	if err := o.c.WriteMessage(m); err != nil {
+		return err
+	}
+	o.destroyed = true
+	return o.c.DestroyObject(o.id)
+}
+
+// Create a sub-surface interface for the given surface, and
+// associate it with the given parent surface. This turns a
+// plain wl_surface into a sub-surface.
+//
+// The to-be sub-surface must not already have a dedicated
+// purpose, like any shell surface type, cursor image, drag icon,
+// or sub-surface. Otherwise a protocol error is raised.
+func (o *ClientSubcompositor) GetSubsurface(i ClientSubsurfaceImplementation, surface *ClientSurface, parent *ClientSurface) (*ClientSubsurface, error) {
+	if o.destroyed {
+		return nil, fmt.Errorf("wl_subcompositor@%d.get_subsurface: %w", o.id, proto.ErrDestroyed)
+	}
+	var surfaceId proto.ObjectId
+	if surface != nil {
+		surfaceId = surface.Id()
+	} else {
+		return nil, fmt.Errorf("wl_subcompositor.get_subsurface: argument surface is null")
+	}
+	var parentId proto.ObjectId
+	if parent != nil {
+		parentId = parent.Id()
+	} else {
+		return nil, fmt.Errorf("wl_subcompositor.get_subsurface: argument parent is null")
+	}
+	obj := NewClient(o.c).NewSubsurface(o.version, i)
+	m := proto.NewMessage(o.id, 1)
+
+	if err := m.WriteNewId(obj.Id()); err != nil {
+		o.c.DeleteObject(obj.Id())
+		return nil, err
+	}
+
+	if err := m.WriteObjectId(surfaceId); err != nil {
+		o.c.DeleteObject(obj.Id())
+		return nil, err
+	}
+
+	if err := m.WriteObjectId(parentId); err != nil {
+		o.c.DeleteObject(obj.Id())
+		return nil, err
+	}
+
+	if err := o.c.WriteMessage(m); err != nil {
+		o.c.DeleteObject(obj.Id())
+		return nil, err
+	}
+	return obj, nil
+}
+
+type ServerSubcompositorImplementation interface {
+
+	//
+	// Informs the server that the client will not be using this
+	// protocol object anymore. This does not affect any other
+	// objects, wl_subsurface objects included.
+	//
+	Destroy() error
+
+	//
+	// Create a sub-surface interface for the given surface, and
+	// associate it with the given parent surface. This turns a
+	// plain wl_surface into a sub-surface.
+	//
+	// The to-be sub-surface must not already have a dedicated
+	// purpose, like any shell surface type, cursor image, drag icon,
+	// or sub-surface. Otherwise a protocol error is raised.
+	//
+	GetSubsurface(id proto.ObjectId, surface *ServerSurface, parent *ServerSurface) error
+}
+
+type ServerSubcompositor struct {
+	c         *proto.Conn
+	id        proto.ObjectId
+	version   uint32
+	i         ServerSubcompositorImplementation
+	destroyed bool
+}
+
+// NewSubcompositor creates an object at the given version, which is the
+// version it was bound at or the version of the object that created it.
+func (c Server) NewSubcompositor(version uint32, i ServerSubcompositorImplementation) *ServerSubcompositor {
+	return c.NewSubcompositorWithId(c.c.NextId(), version, i)
+}
+
+// NewSubcompositorWithId registers an object created by the other end
+// of the connection under the id it has chosen.
+func (c Server) NewSubcompositorWithId(id proto.ObjectId, version uint32, i ServerSubcompositorImplementation) *ServerSubcompositor {
+	o := &ServerSubcompositor{
+		c:       c.c,
+		id:      id,
+		version: version,
+		i:       i,
+	}
+	c.c.AddObject(o.id, o)
+	return o
+}
+
+func (o *ServerSubcompositor) Id() proto.ObjectId {
+	return o.id
+}
+
+func (o *ServerSubcompositor) Version() uint32 {
+	return o.version
+}
+
+func (o *ServerSubcompositor) Conn() *proto.Conn {
+	return o.c
+}
+
+func (o *ServerSubcompositor) Interface() *proto.Interface {
+	return SubcompositorInterface
+}
+
+func (o *ServerSubcompositor) Handle(m *proto.Message) (err error) {
+	switch m.Opcode() {
+
+	case 0:
+
+		var ()
+
+		err = o.i.Destroy()
+		o.destroyed = true
+		if derr := o.c.DestroyObject(o.id); err == nil {
+			err = derr
+		}
+		return
+
+	case 1:
+
+		var (
+			id proto.ObjectId
+
+			surface *ServerSurface
+
+			parent *ServerSurface
+		)
+
+		if id, err = m.ReadNewId(); err != nil {
+			return
+		}
+
+		var surfaceObj proto.Object
+		if surfaceObj, err = m.ReadObject(false); err != nil {
+			return fmt.Errorf("wl_subcompositor.get_subsurface: argument surface: %w", err)
+		}
+		if surfaceObj != nil {
+			var ok bool
+			if surface, ok = surfaceObj.(*ServerSurface); !ok {
+				return fmt.Errorf("wl_subcompositor.get_subsurface: argument surface: %T is not a wl_surface", surfaceObj)
+			}
+		}
+
+		var parentObj proto.Object
+		if parentObj, err = m.ReadObject(false); err != nil {
+			return fmt.Errorf("wl_subcompositor.get_subsurface: argument parent: %w", err)
+		}
+		if parentObj != nil {
+			var ok bool
+			if parent, ok = parentObj.(*ServerSurface); !ok {
+				return fmt.Errorf("wl_subcompositor.get_subsurface: argument parent: %T is not a wl_surface", parentObj)
+			}
+		}
+
+		return o.i.GetSubsurface(id, surface, parent)
+
+	default:
+		return fmt.Errorf("Subcompositor: invalid request opcode: %d", m.Opcode())
+	}
+}
+
+// Subsurface
+//
+// An additional interface to a wl_surface object, which has been
+// made a sub-surface. A sub-surface has one parent surface. A
+// sub-surface's size and position are not limited to that of the parent.
+// Particularly, a sub-surface is not automatically clipped to its
+// parent's area.
+//
+// A sub-surface becomes mapped, when a non-NULL wl_buffer is applied
+// and the parent surface is mapped. The order of which one happens
+// first is irrelevant. A sub-surface is hidden if the parent becomes
+// hidden, or if a NULL wl_buffer is applied. These rules apply
+// recursively through the tree of surfaces.
+//
+// The behaviour of wl_surface.commit request on a sub-surface
+// depends on the sub-surface's mode. The possible modes are
+// synchronized and desynchronized, see methods
+// wl_subsurface.set_sync and wl_subsurface.set_desync. Synchronized
+// mode caches the wl_surface state to be applied when the parent's
+// state gets applied, and desynchronized mode applies the pending
+// wl_surface state directly. A sub-surface is initially in the
+// synchronized mode.
+//
+// Sub-surfaces have also other kind of state, which is managed by
+// wl_subsurface requests, as opposed to wl_surface requests. This
+// state includes the sub-surface position relative to the parent
+// surface (wl_subsurface.set_position), and the stacking order of
+// the parent and its sub-surfaces (wl_subsurface.place_above and
+// .place_below). This state is applied when the parent surface's
+// wl_surface state is applied, regardless of the sub-surface's mode.
+// As the exception, set_sync and set_desync are effective immediately.
+//
+// The main surface can be thought to be always in desynchronized mode,
+// since it does not have a parent in the sub-surfaces sense.
+//
+// Even if a sub-surface is in desynchronized mode, it will behave as
+// in synchronized mode, if its parent surface behaves as in
+// synchronized mode. This rule is applied recursively throughout the
+// tree of surfaces. This means, that one can set a sub-surface into
+// synchronized mode, and then assume that all its child and grand-child
+// sub-surfaces are synchronized, too, without explicitly setting them.
+//
+// If the wl_surface associated with the wl_subsurface is destroyed, the
+// wl_subsurface object becomes inert. Note, that destroying either object
+// takes effect immediately. If you need to synchronize the removal
+// of a sub-surface to the parent surface update, unmap the sub-surface
+// first by attaching a NULL wl_buffer, update parent, and then destroy
+// the sub-surface.
+//
+// If the parent wl_surface object is destroyed, the sub-surface is
+// unmapped.
+//
+
+type SubsurfaceError uint32
+
+const (
+
+	// wl_surface is not a sibling or the parent
+	SUBSURFACE_ERROR_BAD_SURFACE SubsurfaceError = 0
+)
+
+func (e SubsurfaceError) String() string {
+	switch e {
+	case SUBSURFACE_ERROR_BAD_SURFACE:
+		return "bad_surface"
+	}
+	return fmt.Sprintf("SubsurfaceError(%d)", uint32(e))
+}
+
+// Versions that introduced each request and event.
+const (
+	SUBSURFACE_DESTROY_SINCE_VERSION      = 1
+	SUBSURFACE_SET_POSITION_SINCE_VERSION = 1
+	SUBSURFACE_PLACE_ABOVE_SINCE_VERSION  = 1
+	SUBSURFACE_PLACE_BELOW_SINCE_VERSION  = 1
+	SUBSURFACE_SET_SYNC_SINCE_VERSION     = 1
+	SUBSURFACE_SET_DESYNC_SINCE_VERSION   = 1
+)
+
+var SubsurfaceInterface = &proto.Interface{
+	Name:    "wl_subsurface",
+	Version: 1,
+	Requests: []proto.Signature{
+		{Name: "destroy", Since: 1, Args: []proto.ArgDesc{}},
+		{Name: "set_position", Since: 1, Args: []proto.ArgDesc{
+			{Name: "x", Type: proto.ArgInt, Interface: "", Nullable: false},
+			{Name: "y", Type: proto.ArgInt, Interface: "", Nullable: false},
+		}},
+		{Name: "place_above", Since: 1, Args: []proto.ArgDesc{
+			{Name: "sibling", Type: proto.ArgObject, Interface: "wl_surface", Nullable: false},
+		}},
+		{Name: "place_below", Since: 1, Args: []proto.ArgDesc{
+			{Name: "sibling", Type: proto.ArgObject, Interface: "wl_surface", Nullable: false},
+		}},
+		{Name: "set_sync", Since: 1, Args: []proto.ArgDesc{}},
+		{Name: "set_desync", Since: 1, Args: []proto.ArgDesc{}},
+	},
+	Events: []proto.Signature{},
+}
+
+func init() {
+	proto.RegisterInterface(SubsurfaceInterface)
+}
+
+type ClientSubsurfaceImplementation interface {
+}
+
+// ClientSubsurfaceListener implements ClientSubsurfaceImplementation with a
+// function per event. Events whose function is nil are ignored, and fds
+// they carry are closed.
+type ClientSubsurfaceListener struct {
+}
+
+type ClientSubsurface struct {
+	c         *proto.Conn
+	id        proto.ObjectId
+	version   uint32
+	i         ClientSubsurfaceImplementation
+	destroyed bool
+}
+
+// NewSubsurface creates an object at the given version, which is the
+// version it was bound at or the version of the object that created it.
+func (c Client) NewSubsurface(version uint32, i ClientSubsurfaceImplementation) *ClientSubsurface {
+	return c.NewSubsurfaceWithId(c.c.NextId(), version, i)
+}
+
+// NewSubsurfaceWithId registers an object created by the other end
+// of the connection under the id it has chosen.
+func (c Client) NewSubsurfaceWithId(id proto.ObjectId, version uint32, i ClientSubsurfaceImplementation) *ClientSubsurface {
+	o := &ClientSubsurface{
+		c:       c.c,
+		id:      id,
+		version: version,
+		i:       i,
+	}
+	c.c.AddObject(o.id, o)
+	return o
+}
+
+func (o *ClientSubsurface) Id() proto.ObjectId {
+	return o.id
+}
+
+func (o *ClientSubsurface) Version() uint32 {
+	return o.version
+}
+
+func (o *ClientSubsurface) Conn() *proto.Conn {
+	return o.c
+}
+
+func (o *ClientSubsurface) Interface() *proto.Interface {
+	return SubsurfaceInterface
+}
+
+func (o *ClientSubsurface) Handle(m *proto.Message) (err error) {
+	switch m.Opcode() {
+
+	default:
+		return fmt.Errorf("Subsurface: invalid event opcode: %d", m.Opcode())
+	}
+}
+
+// The sub-surface interface is removed from the wl_surface object
+// that was turned into a sub-surface with
+// wl_subcompositor.get_subsurface request. The wl_surface's association
+// to the parent is deleted, and the wl_surface loses its role as
+// a sub-surface. The wl_surface is unmapped.
+func (o *ClientSubsurface) Destroy() error {
+	if o.destroyed {
+		return fmt.Errorf("wl_subsurface@%d.destroy: %w", o.id, proto.ErrDestroyed)
+	}
+	m := proto.NewMessage(o.id, 0)
+
+	if err := o.c.WriteMessage(m); err != nil {
+		return err
+	}
+	o.destroyed = true
+	return o.c.DestroyObject(o.id)
+}
+
+// This schedules a sub-surface position change.
+// The sub-surface will be moved so, that its origin (top-left
+// corner pixel) will be at the location x, y of the parent surface
+// coordinate system. The coordinates are not restricted to the parent
+// surface area. Negative values are allowed.
+//
+// The next wl_surface.commit on the parent surface will reset
+// the sub-surface's position to the scheduled coordinates.
+//
+// If more than one set_position request is invoked by the client before
+// the commit of the parent surface, the position of a new request always
+// replaces the scheduled position from any previous request.
+//
+// The initial position is 0, 0.
+func (o *ClientSubsurface) SetPosition(x int32, y int32) error {
+	if o.destroyed {
+		return fmt.Errorf("wl_subsurface@%d.set_position: %w", o.id, proto.ErrDestroyed)
+	}
+	m := proto.NewMessage(o.id, 1)
+
+	if err := m.WriteInt(x); err != nil {
+		return err
+	}
+
+	if err := m.WriteInt(y); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// This sub-surface is taken from the stack, and put back just
+// above the reference surface, changing the z-order of the sub-surfaces.
+// The reference surface must be one of the sibling surfaces, or the
+// parent surface. Using any other surface, including this sub-surface,
+// will cause a protocol error.
+//
+// The z-order is double-buffered. Requests are handled in order and
+// applied immediately to a pending state, then committed to the active
+// state on the next commit of the parent surface.
+// See wl_surface.commit and wl_subcompositor.get_subsurface.
+//
+// A new sub-surface is initially added as the top-most in the stack
+// of its siblings and parent.
+func (o *ClientSubsurface) PlaceAbove(sibling *ClientSurface) error {
+	if o.destroyed {
+		return fmt.Errorf("wl_subsurface@%d.place_above: %w", o.id, proto.ErrDestroyed)
+	}
+	var siblingId proto.ObjectId
+	if sibling != nil {
+		siblingId = sibling.Id()
+	} else {
+		return fmt.Errorf("wl_subsurface.place_above: argument sibling is null")
+	}
+	m := proto.NewMessage(o.id, 2)
+
+	if err := m.WriteObjectId(siblingId); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// The sub-surface is placed just below of the reference surface.
+// See wl_subsurface.place_above.
+func (o *ClientSubsurface) PlaceBelow(sibling *ClientSurface) error {
+	if o.destroyed {
+		return fmt.Errorf("wl_subsurface@%d.place_below: %w", o.id, proto.ErrDestroyed)
+	}
+	var siblingId proto.ObjectId
+	if sibling != nil {
+		siblingId = sibling.Id()
+	} else {
+		return fmt.Errorf("wl_subsurface.place_below: argument sibling is null")
+	}
+	m := proto.NewMessage(o.id, 3)
+
+	if err := m.WriteObjectId(siblingId); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// Change the commit behaviour of the sub-surface to synchronized
+// mode, also described as the parent dependant mode.
+//
+// In synchronized mode, wl_surface.commit on a sub-surface will
+// accumulate the committed state in a cache, but the state will
+// not be applied and hence will not change the compositor output.
+// The cached state is applied to the sub-surface immediately after
+// the parent surface's state is applied. This ensures atomic
+// updates of the parent and all its synchronized sub-surfaces.
+// Applying the cached state will invalidate the cache, so further
+// parent surface commits do not (re-)apply old state.
+//
+// See wl_subsurface for the recursive effect of this mode.
+func (o *ClientSubsurface) SetSync() error {
+	if o.destroyed {
+		return fmt.Errorf("wl_subsurface@%d.set_sync: %w", o.id, proto.ErrDestroyed)
+	}
+	m := proto.NewMessage(o.id, 4)
+
+	return o.c.WriteMessage(m)
+}
+
+// Change the commit behaviour of the sub-surface to desynchronized
+// mode, also described as independent or freely running mode.
+//
+// In desynchronized mode, wl_surface.commit on a sub-surface will
+// apply the pending state directly, without caching, as happens
+// normally with a wl_surface. Calling wl_surface.commit on the
+// parent surface has no effect on the sub-surface's wl_surface
+// state. This mode allows a sub-surface to be updated on its own.
+//
+// If cached state exists when wl_surface.commit is called in
+// desynchronized mode, the pending state is added to the cached
+// state, and applied as whole. This invalidates the cache.
+//
+// Note: even if a sub-surface is set to desynchronized, a parent
+// sub-surface may override it to behave as synchronized. For details,
+// see wl_subsurface.
+//
+// If a surface's parent surface behaves as desynchronized, then
+// the cached state is applied on set_desync.
+func (o *ClientSubsurface) SetDesync() error {
+	if o.destroyed {
+		return fmt.Errorf("wl_subsurface@%d.set_desync: %w", o.id, proto.ErrDestroyed)
+	}
+	m := proto.NewMessage(o.id, 5)
+
+	return o.c.WriteMessage(m)
+}
+
+type ServerSubsurfaceImplementation interface {
+
+	//
+	// The sub-surface interface is removed from the wl_surface object
+	// that was turned into a sub-surface with
+	// wl_subcompositor.get_subsurface request. The wl_surface's association
+	// to the parent is deleted, and the wl_surface loses its role as
+	// a sub-surface. The wl_surface is unmapped.
+	//
+	Destroy() error
+
+	//
+	// This schedules a sub-surface position change.
+	// The sub-surface will be moved so, that its origin (top-left
+	// corner pixel) will be at the location x, y of the parent surface
+	// coordinate system. The coordinates are not restricted to the parent
+	// surface area. Negative values are allowed.
+	//
+	// The next wl_surface.commit on the parent surface will reset
+	// the sub-surface's position to the scheduled coordinates.
+	//
+	// If more than one set_position request is invoked by the client before
+	// the commit of the parent surface, the position of a new request always
+	// replaces the scheduled position from any previous request.
+	//
+	// The initial position is 0, 0.
+	//
+	SetPosition(x int32, y int32) error
+
+	//
+	// This sub-surface is taken from the stack, and put back just
+	// above the reference surface, changing the z-order of the sub-surfaces.
+	// The reference surface must be one of the sibling surfaces, or the
+	// parent surface. Using any other surface, including this sub-surface,
+	// will cause a protocol error.
+	//
+	// The z-order is double-buffered. Requests are handled in order and
+	// applied immediately to a pending state, then committed to the active
+	// state on the next commit of the parent surface.
+	// See wl_surface.commit and wl_subcompositor.get_subsurface.
+	//
+	// A new sub-surface is initially added as the top-most in the stack
+	// of its siblings and parent.
+	//
+	PlaceAbove(sibling *ServerSurface) error
+
+	//
+	// The sub-surface is placed just below of the reference surface.
+	// See wl_subsurface.place_above.
+	//
+	PlaceBelow(sibling *ServerSurface) error
+
+	//
+	// Change the commit behaviour of the sub-surface to synchronized
+	// mode, also described as the parent dependant mode.
+	//
+	// In synchronized mode, wl_surface.commit on a sub-surface will
+	// accumulate the committed state in a cache, but the state will
+	// not be applied and hence will not change the compositor output.
+	// The cached state is applied to the sub-surface immediately after
+	// the parent surface's state is applied. This ensures atomic
+	// updates of the parent and all its synchronized sub-surfaces.
+	// Applying the cached state will invalidate the cache, so further
+	// parent surface commits do not (re-)apply old state.
+	//
+	// See wl_subsurface for the recursive effect of this mode.
+	//
+	SetSync() error
+
+	//
+	// Change the commit behaviour of the sub-surface to desynchronized
+	// mode, also described as independent or freely running mode.
+	//
+	// In desynchronized mode, wl_surface.commit on a sub-surface will
+	// apply the pending state directly, without caching, as happens
+	// normally with a wl_surface. Calling wl_surface.commit on the
+	// parent surface has no effect on the sub-surface's wl_surface
+	// state. This mode allows a sub-surface to be updated on its own.
+	//
+	// If cached state exists when wl_surface.commit is called in
+	// desynchronized mode, the pending state is added to the cached
+	// state, and applied as whole. This invalidates the cache.
+	//
+	// Note: even if a sub-surface is set to desynchronized, a parent
+	// sub-surface may override it to behave as synchronized. For details,
+	// see wl_subsurface.
+	//
+	// If a surface's parent surface behaves as desynchronized, then
+	// the cached state is applied on set_desync.
+	//
+	SetDesync() error
+}
+
+type ServerSubsurface struct {
+	c         *proto.Conn
+	id        proto.ObjectId
+	version   uint32
+	i         ServerSubsurfaceImplementation
+	destroyed bool
+}
+
+// NewSubsurface creates an object at the given version, which is the
+// version it was bound at or the version of the object that created it.
+func (c Server) NewSubsurface(version uint32, i ServerSubsurfaceImplementation) *ServerSubsurface {
+	return c.NewSubsurfaceWithId(c.c.NextId(), version, i)
+}
+
+// NewSubsurfaceWithId registers an object created by the other end
+// of the connection under the id it has chosen.
+func (c Server) NewSubsurfaceWithId(id proto.ObjectId, version uint32, i ServerSubsurfaceImplementation) *ServerSubsurface {
+	o := &ServerSubsurface{
+		c:       c.c,
+		id:      id,
+		version: version,
+		i:       i,
+	}
+	c.c.AddObject(o.id, o)
+	return o
+}
+
+func (o *ServerSubsurface) Id() proto.ObjectId {
+	return o.id
+}
+
+func (o *ServerSubsurface) Version() uint32 {
+	return o.version
+}
+
+func (o *ServerSubsurface) Conn() *proto.Conn {
+	return o.c
+}
+
+func (o *ServerSubsurface) Interface() *proto.Interface {
+	return SubsurfaceInterface
+}
+
+func (o *ServerSubsurface) Handle(m *proto.Message) (err error) {
+	switch m.Opcode() {
+
+	case 0:
+
+		var ()
+
+		err = o.i.Destroy()
+		o.destroyed = true
+		if derr := o.c.DestroyObject(o.id); err == nil {
+			err = derr
+		}
+		return
+
+	case 1:
+
+		var (
+			x int32
+
+			y int32
+		)
+
+		if x, err = m.ReadInt(); err != nil {
+			return
+		}
+
+		if y, err = m.ReadInt(); err != nil {
+			return
+		}
+
+		return o.i.SetPosition(x, y)
+
+	case 2:
+
+		var (
+			sibling *ServerSurface
+		)
+
+		var siblingObj proto.Object
+		if siblingObj, err = m.ReadObject(false); err != nil {
+			return fmt.Errorf("wl_subsurface.place_above: argument sibling: %w", err)
+		}
+		if siblingObj != nil {
+			var ok bool
+			if sibling, ok = siblingObj.(*ServerSurface); !ok {
+				return fmt.Errorf("wl_subsurface.place_above: argument sibling: %T is not a wl_surface", siblingObj)
+			}
+		}
+
+		return o.i.PlaceAbove(sibling)
+
+	case 3:
+
+		var (
+			sibling *ServerSurface
+		)
+
+		var siblingObj proto.Object
+		if siblingObj, err = m.ReadObject(false); err != nil {
+			return fmt.Errorf("wl_subsurface.place_below: argument sibling: %w", err)
+		}
+		if siblingObj != nil {
+			var ok bool
+			if sibling, ok = siblingObj.(*ServerSurface); !ok {
+				return fmt.Errorf("wl_subsurface.place_below: argument sibling: %T is not a wl_surface", siblingObj)
+			}
+		}
+
+		return o.i.PlaceBelow(sibling)
+
+	case 4:
+
+		var ()
+
+		return o.i.SetSync()
+
+	case 5:
+
+		var ()
+
+		return o.i.SetDesync()
+
+	default:
+		return fmt.Errorf("Subsurface: invalid request opcode: %d", m.Opcode())
+	}
+}
@@ -0,0 +1,6 @@
+// Package xdg_shell implements the unstable xdg_shell protocol on top of
+// proto.Conn. It is generated from proto-generator/xdg-shell.xml and
+// refers to the core interfaces of package wayland.
+package xdg_shell
+
+//go:generate go run ../../proto-generator -pkg xdg_shell -trim-prefix xdg_,wl_ -import wayland=github.com/vasiliyl/playwand/proto/wayland -o xdg_shell.go ../../proto-generator/xdg-shell.xml ../../proto-generator/wayland.xml
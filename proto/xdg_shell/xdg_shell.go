@@ -0,0 +1,1918 @@
+// Code generated by proto-generator from xdg-shell.xml, wayland.xml. DO NOT EDIT.
+
+package xdg_shell
+
+import (
+	"fmt"
+
+	"github.com/vasiliyl/playwand/proto"
+	"github.com/vasiliyl/playwand/proto/wayland"
+)
+
+type Client struct {
+	c *proto.Conn
+}
+
+func NewClient(c *proto.Conn) Client {
+	return Client{c}
+}
+
+type Server struct {
+	c *proto.Conn
+}
+
+func NewServer(c *proto.Conn) Server {
+	return Server{c}
+}
+
+// Shell
+//
+// This interface is implemented by servers that provide
+// desktop-style user interfaces.
+//
+// It allows clients to associate a xdg_surface with
+// a basic surface.
+//
+
+// The 'current' member of this enum gives the version of the
+// protocol.  Implementations can compare this to the version
+// they implement using static_assert to ensure the protocol and
+// implementation versions match.
+type ShellVersion uint32
+
+const (
+
+	// Always the latest version
+	SHELL_VERSION_CURRENT ShellVersion = 3
+)
+
+func (e ShellVersion) String() string {
+	switch e {
+	case SHELL_VERSION_CURRENT:
+		return "current"
+	}
+	return fmt.Sprintf("ShellVersion(%d)", uint32(e))
+}
+
+// Versions that introduced each request and event.
+const (
+	SHELL_USE_UNSTABLE_VERSION_SINCE_VERSION = 1
+	SHELL_GET_XDG_SURFACE_SINCE_VERSION      = 1
+	SHELL_GET_XDG_POPUP_SINCE_VERSION        = 1
+	SHELL_PONG_SINCE_VERSION                 = 1
+	SHELL_PING_SINCE_VERSION                 = 1
+)
+
+var ShellInterface = &proto.Interface{
+	Name:    "xdg_shell",
+	Version: 1,
+	Requests: []proto.Signature{
+		{Name: "use_unstable_version", Since: 1, Args: []proto.ArgDesc{
+			{Name: "version", Type: proto.ArgInt, Interface: "", Nullable: false},
+		}},
+		{Name: "get_xdg_surface", Since: 1, Args: []proto.ArgDesc{
+			{Name: "id", Type: proto.ArgNewId, Interface: "xdg_surface", Nullable: false},
+			{Name: "surface", Type: proto.ArgObject, Interface: "wl_surface", Nullable: false},
+		}},
+		{Name: "get_xdg_popup", Since: 1, Args: []proto.ArgDesc{
+			{Name: "id", Type: proto.ArgNewId, Interface: "xdg_popup", Nullable: false},
+			{Name: "surface", Type: proto.ArgObject, Interface: "wl_surface", Nullable: false},
+			{Name: "parent", Type: proto.ArgObject, Interface: "wl_surface", Nullable: false},
+			{Name: "seat", Type: proto.ArgObject, Interface: "wl_seat", Nullable: false},
+			{Name: "serial", Type: proto.ArgUint, Interface: "", Nullable: false},
+			{Name: "x", Type: proto.ArgInt, Interface: "", Nullable: false},
+			{Name: "y", Type: proto.ArgInt, Interface: "", Nullable: false},
+			{Name: "flags", Type: proto.ArgUint, Interface: "", Nullable: false},
+		}},
+		{Name: "pong", Since: 1, Args: []proto.ArgDesc{
+			{Name: "serial", Type: proto.ArgUint, Interface: "", Nullable: false},
+		}},
+	},
+	Events: []proto.Signature{
+		{Name: "ping", Since: 1, Args: []proto.ArgDesc{
+			{Name: "serial", Type: proto.ArgUint, Interface: "", Nullable: false},
+		}},
+	},
+}
+
+func init() {
+	proto.RegisterInterface(ShellInterface)
+}
+
+type ClientShellImplementation interface {
+
+	//
+	// The ping event asks the client if it's still alive. Pass the
+	// serial specified in the event back to the compositor by sending
+	// a "pong" request back with the specified serial.
+	//
+	// Compositors can use this to determine if the client is still
+	// alive. It's unspecified what will happen if the client doesn't
+	// respond to the ping request, or in what timeframe. Clients should
+	// try to respond in a reasonable amount of time.
+	//
+	Ping(serial uint32) error
+}
+
+// ClientShellListener implements ClientShellImplementation with a
+// function per event. Events whose function is nil are ignored, and fds
+// they carry are closed.
+type ClientShellListener struct {
+	OnPing func(serial uint32) error
+}
+
+func (l ClientShellListener) Ping(serial uint32) error {
+	if l.OnPing == nil {
+		return nil
+	}
+	return l.OnPing(serial)
+}
+
+type ClientShell struct {
+	c       *proto.Conn
+	id      proto.ObjectId
+	version uint32
+	i       ClientShellImplementation
+}
+
+// NewShell creates an object at the given version, which is the
+// version it was bound at or the version of the object that created it.
+func (c Client) NewShell(version uint32, i ClientShellImplementation) *ClientShell {
+	return c.NewShellWithId(c.c.NextId(), version, i)
+}
+
+// NewShellWithId registers an object created by the other end
+// of the connection under the id it has chosen.
+func (c Client) NewShellWithId(id proto.ObjectId, version uint32, i ClientShellImplementation) *ClientShell {
+	o := &ClientShell{
+		c:       c.c,
+		id:      id,
+		version: version,
+		i:       i,
+	}
+	c.c.AddObject(o.id, o)
+	return o
+}
+
+func (o *ClientShell) Id() proto.ObjectId {
+	return o.id
+}
+
+func (o *ClientShell) Version() uint32 {
+	return o.version
+}
+
+func (o *ClientShell) Conn() *proto.Conn {
+	return o.c
+}
+
+func (o *ClientShell) Interface() *proto.Interface {
+	return ShellInterface
+}
+
+func (o *ClientShell) Handle(m *proto.Message) (err error) {
+	switch m.Opcode() {
+
+	case 0:
+
+		var (
+			serial uint32
+		)
+
+		if serial, err = m.ReadUint(); err != nil {
+			return
+		}
+
+		return o.i.Ping(serial)
+
+	default:
+		return fmt.Errorf("Shell: invalid event opcode: %d", m.Opcode())
+	}
+}
+
+// BindShell binds global, which must be a xdg_shell, at the
+// lower of its advertised version and maxVersion, and returns the proxy.
+// maxVersion is capped at 1, the version known to this package.
+func BindShell(registry proto.Registry, global proto.Global, maxVersion uint32, i ClientShellImplementation) (*ClientShell, error) {
+	if maxVersion > 1 {
+		maxVersion = 1
+	}
+	return proto.BindGlobal(registry, global, maxVersion, func(version uint32) *ClientShell {
+		return NewClient(registry.Conn()).NewShell(version, i)
+	})
+}
+
+// Negotiate the unstable version of the interface.  This
+// mechanism is in place to ensure client and server agree on the
+// unstable versions of the protocol that they speak or exit
+// cleanly if they don't agree.  This request will go away once
+// the xdg-shell protocol is stable.
+func (o *ClientShell) UseUnstableVersion(version int32) error {
+	m := proto.NewMessage(o.id, 0)
+
+	if err := m.WriteInt(version); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// Create a shell surface for an existing surface.
+//
+// Only one shell or popup surface can be associated with a given
+// surface.
+func (o *ClientShell) GetXdgSurface(i ClientSurfaceImplementation, surface *wayland.ClientSurface) (*ClientSurface, error) {
+	var surfaceId proto.ObjectId
+	if surface != nil {
+		surfaceId = surface.Id()
+	} else {
+		return nil, fmt.Errorf("xdg_shell.get_xdg_surface: argument surface is null")
+	}
+	obj := NewClient(o.c).NewSurface(o.version, i)
+	m := proto.NewMessage(o.id, 1)
+
+	if err := m.WriteNewId(obj.Id()); err != nil {
+		o.c.DeleteObject(obj.Id())
+		return nil, err
+	}
+
+	if err := m.WriteObjectId(surfaceId); err != nil {
+		o.c.DeleteObject(obj.Id())
+		return nil, err
+	}
+
+	if err := o.c.WriteMessage(m); err != nil {
+		o.c.DeleteObject(obj.Id())
+		return nil, err
+	}
+	return obj, nil
+}
+
+// Create a popup surface for an existing surface.
+//
+// Only one shell or popup surface can be associated with a given
+// surface.
+func (o *ClientShell) GetXdgPopup(i ClientPopupImplementation, surface *wayland.ClientSurface, parent *wayland.ClientSurface, seat *wayland.ClientSeat, serial uint32, x int32, y int32, flags uint32) (*ClientPopup, error) {
+	var surfaceId proto.ObjectId
+	if surface != nil {
+		surfaceId = surface.Id()
+	} else {
+		return nil, fmt.Errorf("xdg_shell.get_xdg_popup: argument surface is null")
+	}
+	var parentId proto.ObjectId
+	if parent != nil {
+		parentId = parent.Id()
+	} else {
+		return nil, fmt.Errorf("xdg_shell.get_xdg_popup: argument parent is null")
+	}
+	var seatId proto.ObjectId
+	if seat != nil {
+		seatId = seat.Id()
+	} else {
+		return nil, fmt.Errorf("xdg_shell.get_xdg_popup: argument seat is null")
+	}
+	obj := NewClient(o.c).NewPopup(o.version, i)
+	m := proto.NewMessage(o.id, 2)
+
+	if err := m.WriteNewId(obj.Id()); err != nil {
+		o.c.DeleteObject(obj.Id())
+		return nil, err
+	}
+
+	if err := m.WriteObjectId(surfaceId); err != nil {
+		o.c.DeleteObject(obj.Id())
+		return nil, err
+	}
+
+	if err := m.WriteObjectId(parentId); err != nil {
+		o.c.DeleteObject(obj.Id())
+		return nil, err
+	}
+
+	if err := m.WriteObjectId(seatId); err != nil {
+		o.c.DeleteObject(obj.Id())
+		return nil, err
+	}
+
+	if err := m.WriteUint(serial); err != nil {
+		o.c.DeleteObject(obj.Id())
+		return nil, err
+	}
+
+	if err := m.WriteInt(x); err != nil {
+		o.c.DeleteObject(obj.Id())
+		return nil, err
+	}
+
+	if err := m.WriteInt(y); err != nil {
+		o.c.DeleteObject(obj.Id())
+		return nil, err
+	}
+
+	if err := m.WriteUint(flags); err != nil {
+		o.c.DeleteObject(obj.Id())
+		return nil, err
+	}
+
+	if err := o.c.WriteMessage(m); err != nil {
+		o.c.DeleteObject(obj.Id())
+		return nil, err
+	}
+	return obj, nil
+}
+
+// A client must respond to a ping event with a pong request or
+// the client may be deemed unresponsive.
+func (o *ClientShell) Pong(serial uint32) error {
+	m := proto.NewMessage(o.id, 3)
+
+	if err := m.WriteUint(serial); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+type ServerShellImplementation interface {
+
+	//
+	// Negotiate the unstable version of the interface.  This
+	// mechanism is in place to ensure client and server agree on the
+	// unstable versions of the protocol that they speak or exit
+	// cleanly if they don't agree.  This request will go away once
+	// the xdg-shell protocol is stable.
+	//
+	UseUnstableVersion(version int32) error
+
+	//
+	// Create a shell surface for an existing surface.
+	//
+	// Only one shell or popup surface can be associated with a given
+	// surface.
+	//
+	GetXdgSurface(id proto.ObjectId, surface *wayland.ServerSurface) error
+
+	//
+	// Create a popup surface for an existing surface.
+	//
+	// Only one shell or popup surface can be associated with a given
+	// surface.
+	//
+	GetXdgPopup(id proto.ObjectId, surface *wayland.ServerSurface, parent *wayland.ServerSurface, seat *wayland.ServerSeat, serial uint32, x int32, y int32, flags uint32) error
+
+	//
+	// A client must respond to a ping event with a pong request or
+	// the client may be deemed unresponsive.
+	//
+	Pong(serial uint32) error
+}
+
+type ServerShell struct {
+	c       *proto.Conn
+	id      proto.ObjectId
+	version uint32
+	i       ServerShellImplementation
+}
+
+// NewShell creates an object at the given version, which is the
+// version it was bound at or the version of the object that created it.
+func (c Server) NewShell(version uint32, i ServerShellImplementation) *ServerShell {
+	return c.NewShellWithId(c.c.NextId(), version, i)
+}
+
+// NewShellWithId registers an object created by the other end
+// of the connection under the id it has chosen.
+func (c Server) NewShellWithId(id proto.ObjectId, version uint32, i ServerShellImplementation) *ServerShell {
+	o := &ServerShell{
+		c:       c.c,
+		id:      id,
+		version: version,
+		i:       i,
+	}
+	c.c.AddObject(o.id, o)
+	return o
+}
+
+func (o *ServerShell) Id() proto.ObjectId {
+	return o.id
+}
+
+func (o *ServerShell) Version() uint32 {
+	return o.version
+}
+
+func (o *ServerShell) Conn() *proto.Conn {
+	return o.c
+}
+
+func (o *ServerShell) Interface() *proto.Interface {
+	return ShellInterface
+}
+
+func (o *ServerShell) Handle(m *proto.Message) (err error) {
+	switch m.Opcode() {
+
+	case 0:
+
+		var (
+			version int32
+		)
+
+		if version, err = m.ReadInt(); err != nil {
+			return
+		}
+
+		return o.i.UseUnstableVersion(version)
+
+	case 1:
+
+		var (
+			id proto.ObjectId
+
+			surface *wayland.ServerSurface
+		)
+
+		if id, err = m.ReadNewId(); err != nil {
+			return
+		}
+
+		var surfaceObj proto.Object
+		if surfaceObj, err = m.ReadObject(false); err != nil {
+			return fmt.Errorf("xdg_shell.get_xdg_surface: argument surface: %w", err)
+		}
+		if surfaceObj != nil {
+			var ok bool
+			if surface, ok = surfaceObj.(*wayland.ServerSurface); !ok {
+				return fmt.Errorf("xdg_shell.get_xdg_surface: argument surface: %T is not a wl_surface", surfaceObj)
+			}
+		}
+
+		return o.i.GetXdgSurface(id, surface)
+
+	case 2:
+
+		var (
+			id proto.ObjectId
+
+			surface *wayland.ServerSurface
+
+			parent *wayland.ServerSurface
+
+			seat *wayland.ServerSeat
+
+			serial uint32
+
+			x int32
+
+			y int32
+
+			flags uint32
+		)
+
+		if id, err = m.ReadNewId(); err != nil {
+			return
+		}
+
+		var surfaceObj proto.Object
+		if surfaceObj, err = m.ReadObject(false); err != nil {
+			return fmt.Errorf("xdg_shell.get_xdg_popup: argument surface: %w", err)
+		}
+		if surfaceObj != nil {
+			var ok bool
+			if surface, ok = surfaceObj.(*wayland.ServerSurface); !ok {
+				return fmt.Errorf("xdg_shell.get_xdg_popup: argument surface: %T is not a wl_surface", surfaceObj)
+			}
+		}
+
+		var parentObj proto.Object
+		if parentObj, err = m.ReadObject(false); err != nil {
+			return fmt.Errorf("xdg_shell.get_xdg_popup: argument parent: %w", err)
+		}
+		if parentObj != nil {
+			var ok bool
+			if parent, ok = parentObj.(*wayland.ServerSurface); !ok {
+				return fmt.Errorf("xdg_shell.get_xdg_popup: argument parent: %T is not a wl_surface", parentObj)
+			}
+		}
+
+		var seatObj proto.Object
+		if seatObj, err = m.ReadObject(false); err != nil {
+			return fmt.Errorf("xdg_shell.get_xdg_popup: argument seat: %w", err)
+		}
+		if seatObj != nil {
+			var ok bool
+			if seat, ok = seatObj.(*wayland.ServerSeat); !ok {
+				return fmt.Errorf("xdg_shell.get_xdg_popup: argument seat: %T is not a wl_seat", seatObj)
+			}
+		}
+
+		if serial, err = m.ReadUint(); err != nil {
+			return
+		}
+
+		if x, err = m.ReadInt(); err != nil {
+			return
+		}
+
+		if y, err = m.ReadInt(); err != nil {
+			return
+		}
+
+		if flags, err = m.ReadUint(); err != nil {
+			return
+		}
+
+		return o.i.GetXdgPopup(id, surface, parent, seat, serial, x, y, flags)
+
+	case 3:
+
+		var (
+			serial uint32
+		)
+
+		if serial, err = m.ReadUint(); err != nil {
+			return
+		}
+
+		return o.i.Pong(serial)
+
+	default:
+		return fmt.Errorf("Shell: invalid request opcode: %d", m.Opcode())
+	}
+}
+
+// The ping event asks the client if it's still alive. Pass the
+// serial specified in the event back to the compositor by sending
+// a "pong" request back with the specified serial.
+//
+// Compositors can use this to determine if the client is still
+// alive. It's unspecified what will happen if the client doesn't
+// respond to the ping request, or in what timeframe. Clients should
+// try to respond in a reasonable amount of time.
+func (o *ServerShell) Ping(serial uint32) error {
+	m := proto.NewMessage(o.id, 0)
+
+	if err := m.WriteUint(serial); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// Surface
+//
+// An interface that may be implemented by a wl_surface, for
+// implementations that provide a desktop-style user interface.
+//
+// It provides requests to treat surfaces like windows, allowing to set
+// properties like maximized, fullscreen, minimized, and to move and resize
+// them, and associate metadata like title and app id.
+//
+// On the server side the object is automatically destroyed when
+// the related wl_surface is destroyed.  On client side,
+// xdg_surface.destroy() must be called before destroying
+// the wl_surface object.
+//
+
+// These values are used to indicate which edge of a surface
+// is being dragged in a resize operation. The server may
+// use this information to adapt its behavior, e.g. choose
+// an appropriate cursor image.
+type SurfaceResizeEdge uint32
+
+const (
+
+	//
+	SURFACE_RESIZE_EDGE_NONE SurfaceResizeEdge = 0
+
+	//
+	SURFACE_RESIZE_EDGE_TOP SurfaceResizeEdge = 1
+
+	//
+	SURFACE_RESIZE_EDGE_BOTTOM SurfaceResizeEdge = 2
+
+	//
+	SURFACE_RESIZE_EDGE_LEFT SurfaceResizeEdge = 4
+
+	//
+	SURFACE_RESIZE_EDGE_TOP_LEFT SurfaceResizeEdge = 5
+
+	//
+	SURFACE_RESIZE_EDGE_BOTTOM_LEFT SurfaceResizeEdge = 6
+
+	//
+	SURFACE_RESIZE_EDGE_RIGHT SurfaceResizeEdge = 8
+
+	//
+	SURFACE_RESIZE_EDGE_TOP_RIGHT SurfaceResizeEdge = 9
+
+	//
+	SURFACE_RESIZE_EDGE_BOTTOM_RIGHT SurfaceResizeEdge = 10
+)
+
+func (e SurfaceResizeEdge) String() string {
+	switch e {
+	case SURFACE_RESIZE_EDGE_NONE:
+		return "none"
+	case SURFACE_RESIZE_EDGE_TOP:
+		return "top"
+	case SURFACE_RESIZE_EDGE_BOTTOM:
+		return "bottom"
+	case SURFACE_RESIZE_EDGE_LEFT:
+		return "left"
+	case SURFACE_RESIZE_EDGE_TOP_LEFT:
+		return "top_left"
+	case SURFACE_RESIZE_EDGE_BOTTOM_LEFT:
+		return "bottom_left"
+	case SURFACE_RESIZE_EDGE_RIGHT:
+		return "right"
+	case SURFACE_RESIZE_EDGE_TOP_RIGHT:
+		return "top_right"
+	case SURFACE_RESIZE_EDGE_BOTTOM_RIGHT:
+		return "bottom_right"
+	}
+	return fmt.Sprintf("SurfaceResizeEdge(%d)", uint32(e))
+}
+
+// The different state values used on the surface. This is designed for
+// state values like maximized, fullscreen. It is paired with the
+// request_change_state event to ensure that both the client and the
+// compositor setting the state can be synchronized.
+//
+// States set in this way are double-buffered. They will get applied on
+// the next commit.
+//
+// Desktop environments may extend this enum by taking up a range of
+// values and documenting the range they chose in this description.
+// They are not required to document the values for the range that they
+// chose. Ideally, any good extensions from a desktop environment should
+// make its way into standardization into this enum.
+//
+// The current reserved ranges are:
+//
+// 0x0000 - 0x0FFF: xdg-shell core values, documented below.
+// 0x1000 - 0x1FFF: GNOME
+type SurfaceState uint32
+
+const (
+
+	// the surface is maximized
+	SURFACE_STATE_MAXIMIZED SurfaceState = 1
+
+	// the surface is fullscreen
+	SURFACE_STATE_FULLSCREEN SurfaceState = 2
+)
+
+func (e SurfaceState) String() string {
+	switch e {
+	case SURFACE_STATE_MAXIMIZED:
+		return "maximized"
+	case SURFACE_STATE_FULLSCREEN:
+		return "fullscreen"
+	}
+	return fmt.Sprintf("SurfaceState(%d)", uint32(e))
+}
+
+// Versions that introduced each request and event.
+const (
+	SURFACE_DESTROY_SINCE_VERSION              = 1
+	SURFACE_SET_TRANSIENT_FOR_SINCE_VERSION    = 1
+	SURFACE_SET_MARGIN_SINCE_VERSION           = 1
+	SURFACE_SET_TITLE_SINCE_VERSION            = 1
+	SURFACE_SET_APP_ID_SINCE_VERSION           = 1
+	SURFACE_MOVE_SINCE_VERSION                 = 1
+	SURFACE_RESIZE_SINCE_VERSION               = 1
+	SURFACE_REQUEST_CHANGE_STATE_SINCE_VERSION = 1
+	SURFACE_ACK_CHANGE_STATE_SINCE_VERSION     = 1
+	SURFACE_SET_MINIMIZED_SINCE_VERSION        = 1
+	SURFACE_CONFIGURE_SINCE_VERSION            = 1
+	SURFACE_CHANGE_STATE_SINCE_VERSION         = 1
+	SURFACE_ACTIVATED_SINCE_VERSION            = 1
+	SURFACE_DEACTIVATED_SINCE_VERSION          = 1
+	SURFACE_CLOSE_SINCE_VERSION                = 1
+)
+
+var SurfaceInterface = &proto.Interface{
+	Name:    "xdg_surface",
+	Version: 1,
+	Requests: []proto.Signature{
+		{Name: "destroy", Since: 1, Args: []proto.ArgDesc{}},
+		{Name: "set_transient_for", Since: 1, Args: []proto.ArgDesc{
+			{Name: "parent", Type: proto.ArgObject, Interface: "wl_surface", Nullable: true},
+		}},
+		{Name: "set_margin", Since: 1, Args: []proto.ArgDesc{
+			{Name: "left_margin", Type: proto.ArgInt, Interface: "", Nullable: false},
+			{Name: "right_margin", Type: proto.ArgInt, Interface: "", Nullable: false},
+			{Name: "top_margin", Type: proto.ArgInt, Interface: "", Nullable: false},
+			{Name: "bottom_margin", Type: proto.ArgInt, Interface: "", Nullable: false},
+		}},
+		{Name: "set_title", Since: 1, Args: []proto.ArgDesc{
+			{Name: "title", Type: proto.ArgString, Interface: "", Nullable: false},
+		}},
+		{Name: "set_app_id", Since: 1, Args: []proto.ArgDesc{
+			{Name: "app_id", Type: proto.ArgString, Interface: "", Nullable: false},
+		}},
+		{Name: "move", Since: 1, Args: []proto.ArgDesc{
+			{Name: "seat", Type: proto.ArgObject, Interface: "wl_seat", Nullable: false},
+			{Name: "serial", Type: proto.ArgUint, Interface: "", Nullable: false},
+		}},
+		{Name: "resize", Since: 1, Args: []proto.ArgDesc{
+			{Name: "seat", Type: proto.ArgObject, Interface: "wl_seat", Nullable: false},
+			{Name: "serial", Type: proto.ArgUint, Interface: "", Nullable: false},
+			{Name: "edges", Type: proto.ArgUint, Interface: "", Nullable: false},
+		}},
+		{Name: "request_change_state", Since: 1, Args: []proto.ArgDesc{
+			{Name: "state_type", Type: proto.ArgUint, Interface: "", Nullable: false},
+			{Name: "value", Type: proto.ArgUint, Interface: "", Nullable: false},
+			{Name: "serial", Type: proto.ArgUint, Interface: "", Nullable: false},
+		}},
+		{Name: "ack_change_state", Since: 1, Args: []proto.ArgDesc{
+			{Name: "state_type", Type: proto.ArgUint, Interface: "", Nullable: false},
+			{Name: "value", Type: proto.ArgUint, Interface: "", Nullable: false},
+			{Name: "serial", Type: proto.ArgUint, Interface: "", Nullable: false},
+		}},
+		{Name: "set_minimized", Since: 1, Args: []proto.ArgDesc{}},
+	},
+	Events: []proto.Signature{
+		{Name: "configure", Since: 1, Args: []proto.ArgDesc{
+			{Name: "width", Type: proto.ArgInt, Interface: "", Nullable: false},
+			{Name: "height", Type: proto.ArgInt, Interface: "", Nullable: false},
+		}},
+		{Name: "change_state", Since: 1, Args: []proto.ArgDesc{
+			{Name: "state_type", Type: proto.ArgUint, Interface: "", Nullable: false},
+			{Name: "value", Type: proto.ArgUint, Interface: "", Nullable: false},
+			{Name: "serial", Type: proto.ArgUint, Interface: "", Nullable: false},
+		}},
+		{Name: "activated", Since: 1, Args: []proto.ArgDesc{}},
+		{Name: "deactivated", Since: 1, Args: []proto.ArgDesc{}},
+		{Name: "close", Since: 1, Args: []proto.ArgDesc{}},
+	},
+}
+
+func init() {
+	proto.RegisterInterface(SurfaceInterface)
+}
+
+type ClientSurfaceImplementation interface {
+
+	//
+	// The configure event asks the client to resize its surface.
+	//
+	// The size is a hint, in the sense that the client is free to
+	// ignore it if it doesn't resize, pick a smaller size (to
+	// satisfy aspect ratio or resize in steps of NxM pixels).
+	//
+	// The client is free to dismiss all but the last configure
+	// event it received.
+	//
+	// The width and height arguments specify the size of the window
+	// in surface local coordinates.
+	//
+	Configure(width int32, height int32) error
+
+	//
+	// This event tells the client to change a surface's state. The client
+	// should respond with an ack_change_state request to the compositor to
+	// guarantee that the compositor knows that the client has seen it.
+	//
+	ChangeState(stateType uint32, value uint32, serial uint32) error
+
+	//
+	// The activated_set event is sent when this surface has been
+	// activated, which means that the surface has user attention.
+	// Window decorations should be updated accordingly. You should
+	// not use this event for anything but the style of decorations
+	// you display, use wl_keyboard.enter and wl_keyboard.leave for
+	// determining keyboard focus.
+	//
+	Activated() error
+
+	//
+	// The deactivate event is sent when this surface has been
+	// deactivated, which means that the surface lost user attention.
+	// Window decorations should be updated accordingly. You should
+	// not use this event for anything but the style of decorations
+	// you display, use wl_keyboard.enter and wl_keyboard.leave for
+	// determining keyboard focus.
+	//
+	Deactivated() error
+
+	//
+	// The close event is sent by the compositor when the user
+	// wants the surface to be closed. This should be equivalent to
+	// the user clicking the close button in client-side decorations,
+	// if your application has any...
+	//
+	// This is only a request that the user intends to close your
+	// window. The client may choose to ignore this request, or show
+	// a dialog to ask the user to save their data...
+	//
+	Close() error
+}
+
+// ClientSurfaceListener implements ClientSurfaceImplementation with a
+// function per event. Events whose function is nil are ignored, and fds
+// they carry are closed.
+type ClientSurfaceListener struct {
+	OnConfigure   func(width int32, height int32) error
+	OnChangeState func(stateType uint32, value uint32, serial uint32) error
+	OnActivated   func() error
+	OnDeactivated func() error
+	OnClose       func() error
+}
+
+func (l ClientSurfaceListener) Configure(width int32, height int32) error {
+	if l.OnConfigure == nil {
+		return nil
+	}
+	return l.OnConfigure(width, height)
+}
+
+func (l ClientSurfaceListener) ChangeState(stateType uint32, value uint32, serial uint32) error {
+	if l.OnChangeState == nil {
+		return nil
+	}
+	return l.OnChangeState(stateType, value, serial)
+}
+
+func (l ClientSurfaceListener) Activated() error {
+	if l.OnActivated == nil {
+		return nil
+	}
+	return l.OnActivated()
+}
+
+func (l ClientSurfaceListener) Deactivated() error {
+	if l.OnDeactivated == nil {
+		return nil
+	}
+	return l.OnDeactivated()
+}
+
+func (l ClientSurfaceListener) Close() error {
+	if l.OnClose == nil {
+		return nil
+	}
+	return l.OnClose()
+}
+
+type ClientSurface struct {
+	c         *proto.Conn
+	id        proto.ObjectId
+	version   uint32
+	i         ClientSurfaceImplementation
+	destroyed bool
+}
+
+// NewSurface creates an object at the given version, which is the
+// version it was bound at or the version of the object that created it.
+func (c Client) NewSurface(version uint32, i ClientSurfaceImplementation) *ClientSurface {
+	return c.NewSurfaceWithId(c.c.NextId(), version, i)
+}
+
+// NewSurfaceWithId registers an object created by the other end
+// of the connection under the id it has chosen.
+func (c Client) NewSurfaceWithId(id proto.ObjectId, version uint32, i ClientSurfaceImplementation) *ClientSurface {
+	o := &ClientSurface{
+		c:       c.c,
+		id:      id,
+		version: version,
+		i:       i,
+	}
+	c.c.AddObject(o.id, o)
+	return o
+}
+
+func (o *ClientSurface) Id() proto.ObjectId {
+	return o.id
+}
+
+func (o *ClientSurface) Version() uint32 {
+	return o.version
+}
+
+func (o *ClientSurface) Conn() *proto.Conn {
+	return o.c
+}
+
+func (o *ClientSurface) Interface() *proto.Interface {
+	return SurfaceInterface
+}
+
+func (o *ClientSurface) Handle(m *proto.Message) (err error) {
+	switch m.Opcode() {
+
+	case 0:
+
+		var (
+			width int32
+
+			height int32
+		)
+
+		if width, err = m.ReadInt(); err != nil {
+			return
+		}
+
+		if height, err = m.ReadInt(); err != nil {
+			return
+		}
+
+		return o.i.Configure(width, height)
+
+	case 1:
+
+		var (
+			stateType uint32
+
+			value uint32
+
+			serial uint32
+		)
+
+		if stateType, err = m.ReadUint(); err != nil {
+			return
+		}
+
+		if value, err = m.ReadUint(); err != nil {
+			return
+		}
+
+		if serial, err = m.ReadUint(); err != nil {
+			return
+		}
+
+		return o.i.ChangeState(stateType, value, serial)
+
+	case 2:
+
+		var ()
+
+		return o.i.Activated()
+
+	case 3:
+
+		var ()
+
+		return o.i.Deactivated()
+
+	case 4:
+
+		var ()
+
+		return o.i.Close()
+
+	default:
+		return fmt.Errorf("Surface: invalid event opcode: %d", m.Opcode())
+	}
+}
+
+// The xdg_surface interface is removed from the wl_surface object
+// that was turned into a xdg_surface with
+// xdg_shell.get_xdg_surface request. The xdg_surface properties,
+// like maximized and fullscreen, are lost. The wl_surface loses
+// its role as a xdg_surface. The wl_surface is unmapped.
+func (o *ClientSurface) Destroy() error {
+	if o.destroyed {
+		return fmt.Errorf("xdg_surface@%d.destroy: %w", o.id, proto.ErrDestroyed)
+	}
+	m := proto.NewMessage(o.id, 0)
+
+	if err := o.c.WriteMessage(m); err != nil {
+		return err
+	}
+	o.destroyed = true
+	return o.c.DestroyObject(o.id)
+}
+
+// Setting a surface as transient of another means that it is child
+// of another surface.
+//
+// Child surfaces are stacked above their parents, and will be
+// unmapped if the parent is unmapped too. They should not appear
+// on task bars and alt+tab.
+func (o *ClientSurface) SetTransientFor(parent *wayland.ClientSurface) error {
+	if o.destroyed {
+		return fmt.Errorf("xdg_surface@%d.set_transient_for: %w", o.id, proto.ErrDestroyed)
+	}
+	var parentId proto.ObjectId
+	if parent != nil {
+		parentId = parent.Id()
+	}
+	m := proto.NewMessage(o.id, 1)
+
+	if err := m.WriteObjectId(parentId); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// This tells the compositor what the visible size of the window
+// should be, so it can use it to determine what borders to use for
+// constrainment and alignment.
+//
+// CSD often has invisible areas for decoration purposes, like drop
+// shadows. These "shadow" drawings need to be subtracted out of the
+// normal boundaries of the window when computing where to place
+// windows (e.g. to set this window so it's centered on top of another,
+// or to put it to the left or right of the screen.)
+//
+// This value should change as little as possible at runtime, to
+// prevent flicker.
+//
+// This value is also ignored when the window is maximized or
+// fullscreen, and assumed to be 0.
+//
+// If never called, this value is assumed to be 0.
+func (o *ClientSurface) SetMargin(leftMargin int32, rightMargin int32, topMargin int32, bottomMargin int32) error {
+	if o.destroyed {
+		return fmt.Errorf("xdg_surface@%d.set_margin: %w", o.id, proto.ErrDestroyed)
+	}
+	m := proto.NewMessage(o.id, 2)
+
+	if err := m.WriteInt(leftMargin); err != nil {
+		return err
+	}
+
+	if err := m.WriteInt(rightMargin); err != nil {
+		return err
+	}
+
+	if err := m.WriteInt(topMargin); err != nil {
+		return err
+	}
+
+	if err := m.WriteInt(bottomMargin); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// Set a short title for the surface.
+//
+// This string may be used to identify the surface in a task bar,
+// window list, or other user interface elements provided by the
+// compositor.
+//
+// The string must be encoded in UTF-8.
+func (o *ClientSurface) SetTitle(title string) error {
+	if o.destroyed {
+		return fmt.Errorf("xdg_surface@%d.set_title: %w", o.id, proto.ErrDestroyed)
+	}
+	m := proto.NewMessage(o.id, 3)
+
+	if err := m.WriteString(title); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// Set an id for the surface.
+//
+// The app id identifies the general class of applications to which
+// the surface belongs.
+//
+// It should be the ID that appears in the new desktop entry
+// specification, the interface name.
+func (o *ClientSurface) SetAppId(appId string) error {
+	if o.destroyed {
+		return fmt.Errorf("xdg_surface@%d.set_app_id: %w", o.id, proto.ErrDestroyed)
+	}
+	m := proto.NewMessage(o.id, 4)
+
+	if err := m.WriteString(appId); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// Start a pointer-driven move of the surface.
+//
+// This request must be used in response to a button press event.
+// The server may ignore move requests depending on the state of
+// the surface (e.g. fullscreen or maximized).
+func (o *ClientSurface) Move(seat *wayland.ClientSeat, serial uint32) error {
+	if o.destroyed {
+		return fmt.Errorf("xdg_surface@%d.move: %w", o.id, proto.ErrDestroyed)
+	}
+	var seatId proto.ObjectId
+	if seat != nil {
+		seatId = seat.Id()
+	} else {
+		return fmt.Errorf("xdg_surface.move: argument seat is null")
+	}
+	m := proto.NewMessage(o.id, 5)
+
+	if err := m.WriteObjectId(seatId); err != nil {
+		return err
+	}
+
+	if err := m.WriteUint(serial); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// Start a pointer-driven resizing of the surface.
+//
+// This request must be used in response to a button press event.
+// The server may ignore resize requests depending on the state of
+// the surface (e.g. fullscreen or maximized).
+func (o *ClientSurface) Resize(seat *wayland.ClientSeat, serial uint32, edges SurfaceResizeEdge) error {
+	if o.destroyed {
+		return fmt.Errorf("xdg_surface@%d.resize: %w", o.id, proto.ErrDestroyed)
+	}
+	var seatId proto.ObjectId
+	if seat != nil {
+		seatId = seat.Id()
+	} else {
+		return fmt.Errorf("xdg_surface.resize: argument seat is null")
+	}
+	m := proto.NewMessage(o.id, 6)
+
+	if err := m.WriteObjectId(seatId); err != nil {
+		return err
+	}
+
+	if err := m.WriteUint(serial); err != nil {
+		return err
+	}
+
+	if err := m.WriteUint(uint32(edges)); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// This asks the compositor to change the state. If the compositor wants
+// to change the state, it will send a change_state event with the same
+// state_type, value, and serial, and the event flow continues as if it
+// it was initiated by the compositor.
+//
+// If the compositor does not want to change the state, it will send a
+// change_state to the client with the old value of the state.
+func (o *ClientSurface) RequestChangeState(stateType uint32, value uint32, serial uint32) error {
+	if o.destroyed {
+		return fmt.Errorf("xdg_surface@%d.request_change_state: %w", o.id, proto.ErrDestroyed)
+	}
+	m := proto.NewMessage(o.id, 7)
+
+	if err := m.WriteUint(stateType); err != nil {
+		return err
+	}
+
+	if err := m.WriteUint(value); err != nil {
+		return err
+	}
+
+	if err := m.WriteUint(serial); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// When a change_state event is received, a client should then ack it
+// using the ack_change_state request to ensure that the compositor
+// knows the client has seen the event.
+//
+// By this point, the state is confirmed, and the next attach should
+// contain the buffer drawn for the new state value.
+//
+// The values here need to be the same as the values in the cooresponding
+// change_state event.
+func (o *ClientSurface) AckChangeState(stateType uint32, value uint32, serial uint32) error {
+	if o.destroyed {
+		return fmt.Errorf("xdg_surface@%d.ack_change_state: %w", o.id, proto.ErrDestroyed)
+	}
+	m := proto.NewMessage(o.id, 8)
+
+	if err := m.WriteUint(stateType); err != nil {
+		return err
+	}
+
+	if err := m.WriteUint(value); err != nil {
+		return err
+	}
+
+	if err := m.WriteUint(serial); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// Minimize the surface.
+func (o *ClientSurface) SetMinimized() error {
+	if o.destroyed {
+		return fmt.Errorf("xdg_surface@%d.set_minimized: %w", o.id, proto.ErrDestroyed)
+	}
+	m := proto.NewMessage(o.id, 9)
+
+	return o.c.WriteMessage(m)
+}
+
+type ServerSurfaceImplementation interface {
+
+	//
+	// The xdg_surface interface is removed from the wl_surface object
+	// that was turned into a xdg_surface with
+	// xdg_shell.get_xdg_surface request. The xdg_surface properties,
+	// like maximized and fullscreen, are lost. The wl_surface loses
+	// its role as a xdg_surface. The wl_surface is unmapped.
+	//
+	Destroy() error
+
+	//
+	// Setting a surface as transient of another means that it is child
+	// of another surface.
+	//
+	// Child surfaces are stacked above their parents, and will be
+	// unmapped if the parent is unmapped too. They should not appear
+	// on task bars and alt+tab.
+	//
+	SetTransientFor(parent *wayland.ServerSurface) error
+
+	//
+	// This tells the compositor what the visible size of the window
+	// should be, so it can use it to determine what borders to use for
+	// constrainment and alignment.
+	//
+	// CSD often has invisible areas for decoration purposes, like drop
+	// shadows. These "shadow" drawings need to be subtracted out of the
+	// normal boundaries of the window when computing where to place
+	// windows (e.g. to set this window so it's centered on top of another,
+	// or to put it to the left or right of the screen.)
+	//
+	// This value should change as little as possible at runtime, to
+	// prevent flicker.
+	//
+	// This value is also ignored when the window is maximized or
+	// fullscreen, and assumed to be 0.
+	//
+	// If never called, this value is assumed to be 0.
+	//
+	SetMargin(leftMargin int32, rightMargin int32, topMargin int32, bottomMargin int32) error
+
+	//
+	// Set a short title for the surface.
+	//
+	// This string may be used to identify the surface in a task bar,
+	// window list, or other user interface elements provided by the
+	// compositor.
+	//
+	// The string must be encoded in UTF-8.
+	//
+	SetTitle(title string) error
+
+	//
+	// Set an id for the surface.
+	//
+	// The app id identifies the general class of applications to which
+	// the surface belongs.
+	//
+	// It should be the ID that appears in the new desktop entry
+	// specification, the interface name.
+	//
+	SetAppId(appId string) error
+
+	//
+	// Start a pointer-driven move of the surface.
+	//
+	// This request must be used in response to a button press event.
+	// The server may ignore move requests depending on the state of
+	// the surface (e.g. fullscreen or maximized).
+	//
+	Move(seat *wayland.ServerSeat, serial uint32) error
+
+	//
+	// Start a pointer-driven resizing of the surface.
+	//
+	// This request must be used in response to a button press event.
+	// The server may ignore resize requests depending on the state of
+	// the surface (e.g. fullscreen or maximized).
+	//
+	Resize(seat *wayland.ServerSeat, serial uint32, edges SurfaceResizeEdge) error
+
+	//
+	// This asks the compositor to change the state. If the compositor wants
+	// to change the state, it will send a change_state event with the same
+	// state_type, value, and serial, and the event flow continues as if it
+	// it was initiated by the compositor.
+	//
+	// If the compositor does not want to change the state, it will send a
+	// change_state to the client with the old value of the state.
+	//
+	RequestChangeState(stateType uint32, value uint32, serial uint32) error
+
+	//
+	// When a change_state event is received, a client should then ack it
+	// using the ack_change_state request to ensure that the compositor
+	// knows the client has seen the event.
+	//
+	// By this point, the state is confirmed, and the next attach should
+	// contain the buffer drawn for the new state value.
+	//
+	// The values here need to be the same as the values in the cooresponding
+	// change_state event.
+	//
+	AckChangeState(stateType uint32, value uint32, serial uint32) error
+
+	//
+	// Minimize the surface.
+	//
+	SetMinimized() error
+}
+
+type ServerSurface struct {
+	c         *proto.Conn
+	id        proto.ObjectId
+	version   uint32
+	i         ServerSurfaceImplementation
+	destroyed bool
+}
+
+// NewSurface creates an object at the given version, which is the
+// version it was bound at or the version of the object that created it.
+func (c Server) NewSurface(version uint32, i ServerSurfaceImplementation) *ServerSurface {
+	return c.NewSurfaceWithId(c.c.NextId(), version, i)
+}
+
+// NewSurfaceWithId registers an object created by the other end
+// of the connection under the id it has chosen.
+func (c Server) NewSurfaceWithId(id proto.ObjectId, version uint32, i ServerSurfaceImplementation) *ServerSurface {
+	o := &ServerSurface{
+		c:       c.c,
+		id:      id,
+		version: version,
+		i:       i,
+	}
+	c.c.AddObject(o.id, o)
+	return o
+}
+
+func (o *ServerSurface) Id() proto.ObjectId {
+	return o.id
+}
+
+func (o *ServerSurface) Version() uint32 {
+	return o.version
+}
+
+func (o *ServerSurface) Conn() *proto.Conn {
+	return o.c
+}
+
+func (o *ServerSurface) Interface() *proto.Interface {
+	return SurfaceInterface
+}
+
+func (o *ServerSurface) Handle(m *proto.Message) (err error) {
+	switch m.Opcode() {
+
+	case 0:
+
+		var ()
+
+		err = o.i.Destroy()
+		o.destroyed = true
+		if derr := o.c.DestroyObject(o.id); err == nil {
+			err = derr
+		}
+		return
+
+	case 1:
+
+		var (
+			parent *wayland.ServerSurface
+		)
+
+		var parentObj proto.Object
+		if parentObj, err = m.ReadObject(true); err != nil {
+			return fmt.Errorf("xdg_surface.set_transient_for: argument parent: %w", err)
+		}
+		if parentObj != nil {
+			var ok bool
+			if parent, ok = parentObj.(*wayland.ServerSurface); !ok {
+				return fmt.Errorf("xdg_surface.set_transient_for: argument parent: %T is not a wl_surface", parentObj)
+			}
+		}
+
+		return o.i.SetTransientFor(parent)
+
+	case 2:
+
+		var (
+			leftMargin int32
+
+			rightMargin int32
+
+			topMargin int32
+
+			bottomMargin int32
+		)
+
+		if leftMargin, err = m.ReadInt(); err != nil {
+			return
+		}
+
+		if rightMargin, err = m.ReadInt(); err != nil {
+			return
+		}
+
+		if topMargin, err = m.ReadInt(); err != nil {
+			return
+		}
+
+		if bottomMargin, err = m.ReadInt(); err != nil {
+			return
+		}
+
+		return o.i.SetMargin(leftMargin, rightMargin, topMargin, bottomMargin)
+
+	case 3:
+
+		var (
+			title string
+		)
+
+		if title, err = m.ReadString(); err != nil {
+			return
+		}
+
+		return o.i.SetTitle(title)
+
+	case 4:
+
+		var (
+			appId string
+		)
+
+		if appId, err = m.ReadString(); err != nil {
+			return
+		}
+
+		return o.i.SetAppId(appId)
+
+	case 5:
+
+		var (
+			seat *wayland.ServerSeat
+
+			serial uint32
+		)
+
+		var seatObj proto.Object
+		if seatObj, err = m.ReadObject(false); err != nil {
+			return fmt.Errorf("xdg_surface.move: argument seat: %w", err)
+		}
+		if seatObj != nil {
+			var ok bool
+			if seat, ok = seatObj.(*wayland.ServerSeat); !ok {
+				return fmt.Errorf("xdg_surface.move: argument seat: %T is not a wl_seat", seatObj)
+			}
+		}
+
+		if serial, err = m.ReadUint(); err != nil {
+			return
+		}
+
+		return o.i.Move(seat, serial)
+
+	case 6:
+
+		var (
+			seat *wayland.ServerSeat
+
+			serial uint32
+
+			edges SurfaceResizeEdge
+		)
+
+		var seatObj proto.Object
+		if seatObj, err = m.ReadObject(false); err != nil {
+			return fmt.Errorf("xdg_surface.resize: argument seat: %w", err)
+		}
+		if seatObj != nil {
+			var ok bool
+			if seat, ok = seatObj.(*wayland.ServerSeat); !ok {
+				return fmt.Errorf("xdg_surface.resize: argument seat: %T is not a wl_seat", seatObj)
+			}
+		}
+
+		if serial, err = m.ReadUint(); err != nil {
+			return
+		}
+
+		var edgesRaw uint32
+		if edgesRaw, err = m.ReadUint(); err != nil {
+			return
+		}
+		edges = SurfaceResizeEdge(edgesRaw)
+
+		return o.i.Resize(seat, serial, edges)
+
+	case 7:
+
+		var (
+			stateType uint32
+
+			value uint32
+
+			serial uint32
+		)
+
+		if stateType, err = m.ReadUint(); err != nil {
+			return
+		}
+
+		if value, err = m.ReadUint(); err != nil {
+			return
+		}
+
+		if serial, err = m.ReadUint(); err != nil {
+			return
+		}
+
+		return o.i.RequestChangeState(stateType, value, serial)
+
+	case 8:
+
+		var (
+			stateType uint32
+
+			value uint32
+
+			serial uint32
+		)
+
+		if stateType, err = m.ReadUint(); err != nil {
+			return
+		}
+
+		if value, err = m.ReadUint(); err != nil {
+			return
+		}
+
+		if serial, err = m.ReadUint(); err != nil {
+			return
+		}
+
+		return o.i.AckChangeState(stateType, value, serial)
+
+	case 9:
+
+		var ()
+
+		return o.i.SetMinimized()
+
+	default:
+		return fmt.Errorf("Surface: invalid request opcode: %d", m.Opcode())
+	}
+}
+
+// The configure event asks the client to resize its surface.
+//
+// The size is a hint, in the sense that the client is free to
+// ignore it if it doesn't resize, pick a smaller size (to
+// satisfy aspect ratio or resize in steps of NxM pixels).
+//
+// The client is free to dismiss all but the last configure
+// event it received.
+//
+// The width and height arguments specify the size of the window
+// in surface local coordinates.
+func (o *ServerSurface) Configure(width int32, height int32) error {
+	if o.destroyed {
+		return fmt.Errorf("xdg_surface@%d.configure: %w", o.id, proto.ErrDestroyed)
+	}
+	m := proto.NewMessage(o.id, 0)
+
+	if err := m.WriteInt(width); err != nil {
+		return err
+	}
+
+	if err := m.WriteInt(height); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// This event tells the client to change a surface's state. The client
+// should respond with an ack_change_state request to the compositor to
+// guarantee that the compositor knows that the client has seen it.
+func (o *ServerSurface) ChangeState(stateType uint32, value uint32, serial uint32) error {
+	if o.destroyed {
+		return fmt.Errorf("xdg_surface@%d.change_state: %w", o.id, proto.ErrDestroyed)
+	}
+	m := proto.NewMessage(o.id, 1)
+
+	if err := m.WriteUint(stateType); err != nil {
+		return err
+	}
+
+	if err := m.WriteUint(value); err != nil {
+		return err
+	}
+
+	if err := m.WriteUint(serial); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
+
+// The activated_set event is sent when this surface has been
+// activated, which means that the surface has user attention.
+// Window decorations should be updated accordingly. You should
+// not use this event for anything but the style of decorations
+// you display, use wl_keyboard.enter and wl_keyboard.leave for
+// determining keyboard focus.
+func (o *ServerSurface) Activated() error {
+	if o.destroyed {
+		return fmt.Errorf("xdg_surface@%d.activated: %w", o.id, proto.ErrDestroyed)
+	}
+	m := proto.NewMessage(o.id, 2)
+
+	return o.c.WriteMessage(m)
+}
+
+// The deactivate event is sent when this surface has been
+// deactivated, which means that the surface lost user attention.
+// Window decorations should be updated accordingly. You should
+// not use this event for anything but the style of decorations
+// you display, use wl_keyboard.enter and wl_keyboard.leave for
+// determining keyboard focus.
+func (o *ServerSurface) Deactivated() error {
+	if o.destroyed {
+		return fmt.Errorf("xdg_surface@%d.deactivated: %w", o.id, proto.ErrDestroyed)
+	}
+	m := proto.NewMessage(o.id, 3)
+
+	return o.c.WriteMessage(m)
+}
+
+// The close event is sent by the compositor when the user
+// wants the surface to be closed. This should be equivalent to
+// the user clicking the close button in client-side decorations,
+// if your application has any...
+//
+// This is only a request that the user intends to close your
+// window. The client may choose to ignore this request, or show
+// a dialog to ask the user to save their data...
+func (o *ServerSurface) Close() error {
+	if o.destroyed {
+		return fmt.Errorf("xdg_surface@%d.close: %w", o.id, proto.ErrDestroyed)
+	}
+	m := proto.NewMessage(o.id, 4)
+
+	return o.c.WriteMessage(m)
+}
+
+// Popup
+//
+// An interface that may be implemented by a wl_surface, for
+// implementations that provide a desktop-style popups/menus. A popup
+// surface is a transient surface with an added pointer grab.
+//
+// An existing implicit grab will be changed to owner-events mode,
+// and the popup grab will continue after the implicit grab ends
+// (i.e. releasing the mouse button does not cause the popup to be
+// unmapped).
+//
+// The popup grab continues until the window is destroyed or a mouse
+// button is pressed in any other clients window. A click in any of
+// the clients surfaces is reported as normal, however, clicks in
+// other clients surfaces will be discarded and trigger the callback.
+//
+// The x and y arguments specify the locations of the upper left
+// corner of the surface relative to the upper left corner of the
+// parent surface, in surface local coordinates.
+//
+// xdg_popup surfaces are always transient for another surface.
+//
+
+// Versions that introduced each request and event.
+const (
+	POPUP_DESTROY_SINCE_VERSION    = 1
+	POPUP_POPUP_DONE_SINCE_VERSION = 1
+)
+
+var PopupInterface = &proto.Interface{
+	Name:    "xdg_popup",
+	Version: 1,
+	Requests: []proto.Signature{
+		{Name: "destroy", Since: 1, Args: []proto.ArgDesc{}},
+	},
+	Events: []proto.Signature{
+		{Name: "popup_done", Since: 1, Args: []proto.ArgDesc{
+			{Name: "serial", Type: proto.ArgUint, Interface: "", Nullable: false},
+		}},
+	},
+}
+
+func init() {
+	proto.RegisterInterface(PopupInterface)
+}
+
+type ClientPopupImplementation interface {
+
+	//
+	// The popup_done event is sent out when a popup grab is broken,
+	// that is, when the users clicks a surface that doesn't belong
+	// to the client owning the popup surface.
+	//
+	PopupDone(serial uint32) error
+}
+
+// ClientPopupListener implements ClientPopupImplementation with a
+// function per event. Events whose function is nil are ignored, and fds
+// they carry are closed.
+type ClientPopupListener struct {
+	OnPopupDone func(serial uint32) error
+}
+
+func (l ClientPopupListener) PopupDone(serial uint32) error {
+	if l.OnPopupDone == nil {
+		return nil
+	}
+	return l.OnPopupDone(serial)
+}
+
+type ClientPopup struct {
+	c         *proto.Conn
+	id        proto.ObjectId
+	version   uint32
+	i         ClientPopupImplementation
+	destroyed bool
+}
+
+// NewPopup creates an object at the given version, which is the
+// version it was bound at or the version of the object that created it.
+func (c Client) NewPopup(version uint32, i ClientPopupImplementation) *ClientPopup {
+	return c.NewPopupWithId(c.c.NextId(), version, i)
+}
+
+// NewPopupWithId registers an object created by the other end
+// of the connection under the id it has chosen.
+func (c Client) NewPopupWithId(id proto.ObjectId, version uint32, i ClientPopupImplementation) *ClientPopup {
+	o := &ClientPopup{
+		c:       c.c,
+		id:      id,
+		version: version,
+		i:       i,
+	}
+	c.c.AddObject(o.id, o)
+	return o
+}
+
+func (o *ClientPopup) Id() proto.ObjectId {
+	return o.id
+}
+
+func (o *ClientPopup) Version() uint32 {
+	return o.version
+}
+
+func (o *ClientPopup) Conn() *proto.Conn {
+	return o.c
+}
+
+func (o *ClientPopup) Interface() *proto.Interface {
+	return PopupInterface
+}
+
+func (o *ClientPopup) Handle(m *proto.Message) (err error) {
+	switch m.Opcode() {
+
+	case 0:
+
+		var (
+			serial uint32
+		)
+
+		if serial, err = m.ReadUint(); err != nil {
+			return
+		}
+
+		return o.i.PopupDone(serial)
+
+	default:
+		return fmt.Errorf("Popup: invalid event opcode: %d", m.Opcode())
+	}
+}
+
+// The xdg_surface interface is removed from the wl_surface object
+// that was turned into a xdg_surface with
+// xdg_shell.get_xdg_surface request. The xdg_surface properties,
+// like maximized and fullscreen, are lost. The wl_surface loses
+// its role as a xdg_surface. The wl_surface is unmapped.
+func (o *ClientPopup) Destroy() error {
+	if o.destroyed {
+		return fmt.Errorf("xdg_popup@%d.destroy: %w", o.id, proto.ErrDestroyed)
+	}
+	m := proto.NewMessage(o.id, 0)
+
+	if err := o.c.WriteMessage(m); err != nil {
+		return err
+	}
+	o.destroyed = true
+	return o.c.DestroyObject(o.id)
+}
+
+type ServerPopupImplementation interface {
+
+	//
+	// The xdg_surface interface is removed from the wl_surface object
+	// that was turned into a xdg_surface with
+	// xdg_shell.get_xdg_surface request. The xdg_surface properties,
+	// like maximized and fullscreen, are lost. The wl_surface loses
+	// its role as a xdg_surface. The wl_surface is unmapped.
+	//
+	Destroy() error
+}
+
+type ServerPopup struct {
+	c         *proto.Conn
+	id        proto.ObjectId
+	version   uint32
+	i         ServerPopupImplementation
+	destroyed bool
+}
+
+// NewPopup creates an object at the given version, which is the
+// version it was bound at or the version of the object that created it.
+func (c Server) NewPopup(version uint32, i ServerPopupImplementation) *ServerPopup {
+	return c.NewPopupWithId(c.c.NextId(), version, i)
+}
+
+// NewPopupWithId registers an object created by the other end
+// of the connection under the id it has chosen.
+func (c Server) NewPopupWithId(id proto.ObjectId, version uint32, i ServerPopupImplementation) *ServerPopup {
+	o := &ServerPopup{
+		c:       c.c,
+		id:      id,
+		version: version,
+		i:       i,
+	}
+	c.c.AddObject(o.id, o)
+	return o
+}
+
+func (o *ServerPopup) Id() proto.ObjectId {
+	return o.id
+}
+
+func (o *ServerPopup) Version() uint32 {
+	return o.version
+}
+
+func (o *ServerPopup) Conn() *proto.Conn {
+	return o.c
+}
+
+func (o *ServerPopup) Interface() *proto.Interface {
+	return PopupInterface
+}
+
+func (o *ServerPopup) Handle(m *proto.Message) (err error) {
+	switch m.Opcode() {
+
+	case 0:
+
+		var ()
+
+		err = o.i.Destroy()
+		o.destroyed = true
+		if derr := o.c.DestroyObject(o.id); err == nil {
+			err = derr
+		}
+		return
+
+	default:
+		return fmt.Errorf("Popup: invalid request opcode: %d", m.Opcode())
+	}
+}
+
+// The popup_done event is sent out when a popup grab is broken,
+// that is, when the users clicks a surface that doesn't belong
+// to the client owning the popup surface.
+func (o *ServerPopup) PopupDone(serial uint32) error {
+	if o.destroyed {
+		return fmt.Errorf("xdg_popup@%d.popup_done: %w", o.id, proto.ErrDestroyed)
+	}
+	m := proto.NewMessage(o.id, 0)
+
+	if err := m.WriteUint(serial); err != nil {
+		return err
+	}
+
+	return o.c.WriteMessage(m)
+}
@@ -0,0 +1,69 @@
+package proto
+
+import "errors"
+
+// zombie takes the place of an object destroyed by this end of the
+// connection until the peer acknowledges the destruction. The peer may
+// have sent messages to the object before it learned about it; they are
+// discarded, and the zombie still knows how many fds each of them
+// carries, so that the fd queue stays in step.
+type zombie struct {
+	iface *Interface
+}
+
+func (z zombie) Handle(m *Message) error {
+	// DispatchMessage closes the fds nobody read
+	return nil
+}
+
+func (z zombie) Interface() *Interface {
+	return z.iface
+}
+
+// ErrDestroyed is returned for requests on proxies, and for events on
+// resources, after their destructor.
+var ErrDestroyed = errors.New("object destroyed")
+
+const displayDeleteId uint16 = 1
+
+// DestroyObject removes the object with the given id after its
+// destructor request has been sent or, on a server, handled.
+//
+// A client turns an object it created into a zombie: messages still
+// arriving for it are silently dropped until wl_display.delete_id
+// removes it for good. Objects the server created are removed at once,
+// as libwayland does, since the server frees their ids without
+// delete_id and may reuse them right away. A server removes the object
+// at once and, for ids the client allocated, sends wl_display.delete_id
+// so that the client can reuse the id.
+func (c *Conn) DestroyObject(id ObjectId) error {
+	if c.server {
+		c.DeleteObject(id)
+		if c.ids.contains(id) {
+			return nil
+		}
+		m := NewMessage(DisplayId, displayDeleteId)
+		m.WriteUint(uint32(id))
+		return c.WriteMessage(m)
+	}
+	if id >= serverIdMin {
+		c.DeleteObject(id)
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	obj, ok := c.objects[id]
+	if !ok {
+		return nil
+	}
+	if _, ok := obj.(zombie); ok {
+		return nil
+	}
+	var z zombie
+	if d, ok := obj.(described); ok {
+		z.iface = d.Interface()
+	}
+	c.objects[id] = z
+	return nil
+}
@@ -0,0 +1,141 @@
+package proto
+
+import "testing"
+
+func TestDestroyObject(t *testing.T) {
+	tests := []struct {
+		name       string
+		server     bool // whether the server end destroys the object
+		id         ObjectId
+		wantZombie bool
+		wantDelete bool // whether wl_display.delete_id is sent
+	}{
+		{"client destroys own object", false, 2, true, false},
+		{"client destroys server object", false, serverIdMin, false, false},
+		{"server destroys client object", true, 2, false, true},
+		{"server destroys own object", true, serverIdMin, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := testConns(t)
+			c, peer := client, server
+			if tt.server {
+				c, peer = server, client
+			}
+			c.AddObject(tt.id, new(testObject))
+
+			if err := c.DestroyObject(tt.id); err != nil {
+				t.Fatal(err)
+			}
+			c.mu.Lock()
+			obj, ok := c.objects[tt.id]
+			c.mu.Unlock()
+			if _, zombie := obj.(zombie); zombie != tt.wantZombie || ok != tt.wantZombie {
+				t.Errorf("after DestroyObject, object is %T, registered %v", obj, ok)
+			}
+
+			// a marker message follows whatever DestroyObject sent
+			if err := c.WriteMessage(NewMessage(3, 1)); err != nil {
+				t.Fatal(err)
+			}
+			if err := c.Flush(); err != nil {
+				t.Fatal(err)
+			}
+			deleted := false
+			for {
+				m, err := peer.ReadMessage()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if m.Object() == 3 {
+					break
+				}
+				id, _ := m.ReadUint()
+				if m.Object() != DisplayId || m.Opcode() != displayDeleteId || ObjectId(id) != tt.id {
+					t.Fatalf("unexpected message %s", m)
+				}
+				deleted = true
+			}
+			if deleted != tt.wantDelete {
+				t.Errorf("delete_id sent: %v, want %v", deleted, tt.wantDelete)
+			}
+		})
+	}
+}
+
+func TestZombie(t *testing.T) {
+	client, server := testConns(t)
+	serveSync(server)
+	ctx := testContext(t)
+
+	id := client.NextId()
+	o := new(testObject)
+	client.AddObject(id, o)
+	if err := client.DestroyObject(id); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.DestroyObject(id); err != nil {
+		t.Fatal(err)
+	}
+
+	// events the server sent before it learned about the destruction are
+	// dropped, fds included, and the id stays taken until delete_id
+	r, w := testPipe(t)
+	before := numFds(t)
+	m := NewMessage(id, 0)
+	m.WriteFd(uintptr(r))
+	m.WriteFd(uintptr(w))
+	if err := server.WriteMessage(m); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.WriteMessage(NewMessage(id, 1)); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Roundtrip(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(o.handled) != 0 {
+		t.Errorf("destroyed object handled opcodes %v", o.handled)
+	}
+	if after := numFds(t); after != before {
+		t.Errorf("%d fds open before the dropped event, %d after", before, after)
+	}
+	if next := client.NextId(); next == id {
+		t.Fatalf("id %d reused before delete_id", id)
+	}
+
+	if err := server.DestroyObject(id); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Dispatch(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if next := client.NextId(); next != id {
+		t.Errorf("NextId() = %d after delete_id, want %d", next, id)
+	}
+}
+
+// TestServerIdReuse destroys an object the server created, like a
+// wl_data_offer, which the server frees without delete_id.
+func TestServerIdReuse(t *testing.T) {
+	client, server := testConns(t)
+
+	id := server.NextId()
+	server.AddObject(id, new(testObject))
+	client.AddObject(id, new(testObject))
+	if err := client.DestroyObject(id); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.DestroyObject(id); err != nil {
+		t.Fatal(err)
+	}
+	if reused := server.NextId(); reused != id {
+		t.Fatalf("server NextId() = %#x, want %#x", reused, id)
+	}
+	if err := client.checkNewId(id); err != nil {
+		t.Errorf("new object with the reused id: %s", err)
+	}
+}
@@ -1,12 +1,14 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"image"
 	"image/draw"
 	"io/ioutil"
 	"os"
+	"sync"
 	"time"
 
 	"code.google.com/p/freetype-go/freetype"
@@ -23,20 +25,17 @@ import (
 	"github.com/vasiliyl/playwand/shm"
 )
 
-type global struct {
-	Name      uint32
-	Interface string
-	Version   uint32
-}
-
 type buffer struct {
-	wayland.ClientBuffer
+	*wayland.ClientBuffer
+	c    *clock
 	img  image.NRGBA
 	busy bool
 }
 
 func (b *buffer) Release() error {
+	b.c.bufMu.Lock()
 	b.busy = false
+	b.c.bufMu.Unlock()
 	return nil
 }
 
@@ -51,26 +50,33 @@ type clock struct {
 
 	wlc wayland.Client
 
-	display    wayland.ClientDisplay
-	registry   wayland.ClientRegistry
-	shm        wayland.ClientShm
-	compositor wayland.ClientCompositor
-	surface    wayland.ClientSurface
+	display    *wayland.ClientDisplay
+	registry   *wayland.ClientRegistry
+	shm        *wayland.ClientShm
+	compositor *wayland.ClientCompositor
+	surface    *wayland.ClientSurface
 
 	xdgc         xdg_shell.Client
-	shell        xdg_shell.ClientShell
-	shellSurface xdg_shell.ClientSurface
+	shell        *xdg_shell.ClientShell
+	shellSurface *xdg_shell.ClientSurface
+
+	// tickq holds the callbacks of roundtrips made by Tick, so that Tick
+	// can wait for them while events are dispatched by another goroutine
+	tickq *proto.EventQueue
 
+	bufMu   sync.Mutex
 	buffers [2]*buffer
 	bufSize int32
 	bufsMap []byte
 
-	shmGlobal, compositorGlobal, xdgShellGlobal global
+	shmGlobal, compositorGlobal, xdgShellGlobal proto.Global
 }
 
 const PADDING = 0
 
-func newClock(conn *proto.Conn, w, h int32, fn *freetype.Context, pt raster.Point, format string) (*clock, error) {
+// newClock sets up the clock's surface and buffers, giving up once ctx
+// is done.
+func newClock(ctx context.Context, conn *proto.Conn, w, h int32, fn *freetype.Context, pt raster.Point, format string) (*clock, error) {
 	c := &clock{
 		conn: conn,
 		w:    w, h: h,
@@ -78,13 +84,14 @@ func newClock(conn *proto.Conn, w, h int32, fn *freetype.Context, pt raster.Poin
 		fn:     fn, pt: pt, format: format,
 	}
 	c.bufSize = c.h * c.stride
+	c.tickq = conn.NewEventQueue()
 	c.fn.SetClip(image.Rect(0, 0, int(w), int(h)))
 
 	c.wlc = wayland.NewClient(conn)
 	c.xdgc = xdg_shell.NewClient(conn)
-	c.display = c.wlc.NewDisplay(c)
+	c.display = c.wlc.NewDisplayWithId(proto.DisplayId, 1, wayland.ClientDisplayListener{})
 
-	if err := c.getRegistry(); err != nil {
+	if err := c.getRegistry(ctx); err != nil {
 		return nil, err
 	}
 
@@ -96,7 +103,7 @@ func newClock(conn *proto.Conn, w, h int32, fn *freetype.Context, pt raster.Poin
 		return nil, err
 	}
 
-	if err := c.createBuffers(); err != nil {
+	if err := c.createBuffers(ctx); err != nil {
 		return nil, err
 	}
 
@@ -110,13 +117,13 @@ func (c *clock) Print() {
 	fmt.Printf("\tglobals: compositor<%d>, shm<%d>, xdg_shell<%d>\n", c.compositorGlobal.Name, c.shmGlobal.Name, c.xdgShellGlobal.Name)
 }
 
-func (c *clock) getRegistry() error {
-	c.registry = c.wlc.NewRegistry(c)
-	if err := c.display.GetRegistry(c.registry.Id()); err != nil {
+func (c *clock) getRegistry(ctx context.Context) error {
+	var err error
+	if c.registry, err = c.display.GetRegistry(wayland.ClientRegistryListener{OnGlobal: c.Global}); err != nil {
 		return errgo.Trace(err)
 	}
 
-	if err := c.sync(); err != nil {
+	if err := c.conn.Roundtrip(ctx); err != nil {
 		return errgo.Trace(err)
 	}
 
@@ -124,31 +131,26 @@ func (c *clock) getRegistry() error {
 }
 
 func (c *clock) createSurface() error {
-	g := c.compositorGlobal
-	c.compositor = c.wlc.NewCompositor(c)
-	if err := c.registry.Bind(g.Name, g.Interface, g.Version, c.compositor.Id()); err != nil {
+	var err error
+	if c.compositor, err = wayland.BindCompositor(c.registry, c.compositorGlobal, 3, c); err != nil {
 		return errgo.Trace(err)
 	}
 
-	c.surface = c.wlc.NewSurface(c)
-	if err := c.compositor.CreateSurface(c.surface.Id()); err != nil {
+	if c.surface, err = c.compositor.CreateSurface(wayland.ClientSurfaceListener{}); err != nil {
 		return errgo.Trace(err)
 	}
 	if err := c.surface.Damage(0, 0, c.w, c.h); err != nil {
 		return errgo.Trace(err)
 	}
 
-	g = c.xdgShellGlobal
-	c.shell = c.xdgc.NewShell(c)
-	if err := c.registry.Bind(g.Name, g.Interface, g.Version, c.shell.Id()); err != nil {
+	if c.shell, err = xdg_shell.BindShell(c.registry, c.xdgShellGlobal, 1, c); err != nil {
 		return errgo.Trace(err)
 	}
 	if err := c.shell.UseUnstableVersion(3); err != nil {
 		return errgo.Trace(err)
 	}
 
-	c.shellSurface = c.xdgc.NewSurface(c)
-	if err := c.shell.GetXdgSurface(c.shellSurface.Id(), c.surface.Id()); err != nil {
+	if c.shellSurface, err = c.shell.GetXdgSurface(c, c.surface); err != nil {
 		return errgo.Trace(err)
 	}
 
@@ -193,15 +195,14 @@ func (c *clock) Deactivated() error {
 	return nil
 }
 
-func (c *clock) createBuffers() error {
-	g := c.shmGlobal
-	c.shm = c.wlc.NewShm(c)
-	if err := c.registry.Bind(g.Name, g.Interface, g.Version, c.shm.Id()); err != nil {
+func (c *clock) createBuffers(ctx context.Context) error {
+	var err error
+	if c.shm, err = wayland.BindShm(c.registry, c.shmGlobal, 1, wayland.ClientShmListener{}); err != nil {
 		return errgo.Trace(err)
 	}
 
 	// collect shm formats
-	if err := c.sync(); err != nil {
+	if err := c.conn.Roundtrip(ctx); err != nil {
 		return errgo.Trace(err)
 	}
 
@@ -221,25 +222,24 @@ func (c *clock) createBuffers() error {
 		return errgo.Trace(err)
 	}
 
-	shmPool := c.wlc.NewShmPool(c)
-	if err := c.shm.CreatePool(shmPool.Id(), shmO.Fd(), poolSize); err != nil {
+	shmPool, err := c.shm.CreatePool(c, shmO.Fd(), poolSize)
+	if err != nil {
 		return errgo.Trace(err)
 	}
 
 	for i := range c.buffers {
-		buf := new(buffer)
+		buf := &buffer{c: c}
 		buf.img.Rect = image.Rect(0, 0, int(c.w), int(c.h))
 		buf.img.Stride = int(c.w) * 4
 		buf.img.Pix = c.bufsMap[i*int(c.bufSize) : (i+1)*int(c.bufSize)]
 
-		buf.ClientBuffer = c.wlc.NewBuffer(buf)
-		if err := shmPool.CreateBuffer(
-			buf.Id(),           // Id
-			int32(i)*c.bufSize, // Offset
-			c.w,                // Width
-			c.h,                // Height
-			c.stride,           // Stride
-			0,                  // Format
+		if buf.ClientBuffer, err = shmPool.CreateBuffer(
+			buf,                         // Implementation
+			int32(i)*c.bufSize,          // Offset
+			c.w,                         // Width
+			c.h,                         // Height
+			c.stride,                    // Stride
+			wayland.SHM_FORMAT_ARGB8888, // Format
 		); err != nil {
 			return errgo.Trace(err)
 		}
@@ -250,97 +250,47 @@ func (c *clock) createBuffers() error {
 	//    return errgo.Trace(err)
 	//}
 
-	if err := c.sync(); err != nil {
+	if err := c.conn.Roundtrip(ctx); err != nil {
 		return errgo.Trace(err)
 	}
 
 	return nil
 }
 
-// wayland.Shm events
-func (c *clock) Format(_ uint32) error {
-	return nil
-}
-
-type callback struct {
-	done bool
-}
-
-func (cb *callback) Done(s uint32) error {
-	cb.done = true
-	return nil
-}
-
-func (c *clock) sync() error {
-	cb := new(callback)
-	scb := c.wlc.NewCallback(cb)
-	if err := c.display.Sync(scb.Id()); err != nil {
-		return errgo.Trace(err)
-	}
-	for !cb.done {
-		if err := c.conn.Next(); err != nil {
-			return errgo.Trace(err)
-		}
-	}
-	return nil
-}
-
-// wayland.Display events
-func (c *clock) Error(obj proto.ObjectId, _ uint32, msg string) error {
-	return errgo.New("Display error: object %d: %s", obj, msg)
-}
-
-func (c *clock) DeleteId(id uint32) error {
-	c.conn.DeleteObject(proto.ObjectId(id))
-	return nil
-}
-
 // wayland.Registry events
 func (c *clock) Global(name uint32, iface string, version uint32) error {
 	switch iface {
 	case "wl_compositor":
-		c.compositorGlobal = global{name, iface, version}
+		c.compositorGlobal = proto.Global{Name: name, Interface: iface, Version: version}
 
 	case "wl_shm":
-		c.shmGlobal = global{name, iface, version}
+		c.shmGlobal = proto.Global{Name: name, Interface: iface, Version: version}
 
 	case "xdg_shell":
-		c.xdgShellGlobal = global{name, iface, version}
+		c.xdgShellGlobal = proto.Global{Name: name, Interface: iface, Version: version}
 	}
 
 	return nil
 }
 
-func (c *clock) GlobalRemove(name uint32) error {
-	return nil
-}
-
-// wayland.Surface events
-func (c *clock) Enter(_ proto.ObjectId) error {
-	return nil
-}
-
-func (c *clock) Leave(_ proto.ObjectId) error {
-	return nil
-}
-
 func (c *clock) Tick(t time.Time) error {
 	c.t = t
 	fmt.Printf("tick: %s\n", c.t.Format(c.format))
 
+	c.bufMu.Lock()
 	buf := c.freeBuf()
-	c.paint(buf, t)
 	buf.busy = true
+	c.bufMu.Unlock()
+	c.paint(buf, t)
 
-	if err := c.surface.Attach(buf.Id(), 0, 0); err != nil {
+	if err := c.surface.Attach(buf.ClientBuffer, 0, 0); err != nil {
 		return errgo.Trace(err)
 	}
 	if err := c.surface.Damage(0, 0, c.w, c.h); err != nil {
 		return errgo.Trace(err)
 	}
 
-	//frame := c.wlc.NewCallback(c)
-	//if err := c.surface.Frame(frame.Id()); err != nil {
+	//if _, err := c.surface.Frame(c); err != nil {
 	//    return errgo.Trace(err)
 	//}
 
@@ -348,7 +298,10 @@ func (c *clock) Tick(t time.Time) error {
 		return errgo.Trace(err)
 	}
 
-	if err := c.sync(); err != nil {
+	// the frame must be on screen before the next tick
+	ctx, cancel := context.WithTimeout(context.Background(), *tickDuration)
+	defer cancel()
+	if err := c.tickq.Roundtrip(ctx); err != nil {
 		return errgo.Trace(err)
 	}
 
@@ -360,6 +313,7 @@ func (c *clock) Done(serial uint32) error {
 	return nil
 }
 
+// freeBuf must be called with bufMu held.
 func (c *clock) freeBuf() *buffer {
 	for _, buf := range c.buffers {
 		if !buf.busy {
@@ -436,24 +390,19 @@ func main() {
 	}
 	defer conn.Close()
 
-	c, err := newClock(conn, int32(*width), int32(*height), ctx, pt, *format)
+	setup, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	c, err := newClock(setup, conn, int32(*width), int32(*height), ctx, pt, *format)
+	cancel()
 
 	ticker := time.Tick(*tickDuration)
 
-	wlMsg := make(chan *proto.Message)
 	wlErr := make(chan error, 1)
-	barrier := make(chan bool)
 	go func() {
 		for {
-			msg, err := conn.ReadMessage()
-			if err != nil {
+			if _, err := conn.Dispatch(context.Background()); err != nil {
 				wlErr <- err
-				close(wlMsg)
-				close(wlErr)
 				return
 			}
-			wlMsg <- msg
-			<-barrier
 		}
 	}()
 
@@ -468,12 +417,6 @@ mainloop:
 				break mainloop
 			}
 
-		case msg := <-wlMsg:
-			if err = conn.Dispatch(msg); err != nil {
-				break mainloop
-			}
-			barrier <- true
-
 		case err = <-wlErr:
 			break mainloop
 		}
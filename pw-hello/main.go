@@ -2,12 +2,14 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"flag"
 	"fmt"
 	"image"
 	"image/color"
 	"log"
 	"os"
+	"time"
 
 	_ "image/jpeg"
 	_ "image/png"
@@ -21,12 +23,6 @@ import (
 	"github.com/vasiliyl/playwand/shm"
 )
 
-type global struct {
-	Name      uint32
-	Interface string
-	Version   uint32
-}
-
 type hello struct {
 	imgPath    string
 	imgW, imgH int32
@@ -37,17 +33,17 @@ type hello struct {
 
 	wlClient wayland.Client
 
-	display    wayland.ClientDisplay
-	registry   wayland.ClientRegistry
-	shm        wayland.ClientShm
-	shmPool    wayland.ClientShmPool
-	compositor wayland.ClientCompositor
-	surface    wayland.ClientSurface
-	buffer     wayland.ClientBuffer
+	display    *wayland.ClientDisplay
+	registry   *wayland.ClientRegistry
+	shm        *wayland.ClientShm
+	shmPool    *wayland.ClientShmPool
+	compositor *wayland.ClientCompositor
+	surface    *wayland.ClientSurface
+	buffer     *wayland.ClientBuffer
 
 	xdgClient    xdg_shell.Client
-	shell        xdg_shell.ClientShell
-	shellSurface xdg_shell.ClientSurface
+	shell        *xdg_shell.ClientShell
+	shellSurface *xdg_shell.ClientSurface
 
 	//registryId              proto.ObjectId
 	//shmId, shmPoolId        proto.ObjectId
@@ -55,8 +51,8 @@ type hello struct {
 	//shellId, shellSurfaceId proto.ObjectId
 	//bufferId                proto.ObjectId
 
-	globals    []global
-	shmFormats []uint32
+	globals    []proto.Global
+	shmFormats []wayland.ShmFormat
 }
 
 func newHello(c *proto.Conn, imgPath string) *hello {
@@ -66,7 +62,7 @@ func newHello(c *proto.Conn, imgPath string) *hello {
 		wlClient:  wayland.NewClient(c),
 		xdgClient: xdg_shell.NewClient(c),
 	}
-	h.display = h.wlClient.NewDisplay(h)
+	h.display = h.wlClient.NewDisplayWithId(proto.DisplayId, 1, wayland.ClientDisplayListener{})
 	return h
 }
 
@@ -87,22 +83,14 @@ func (h *hello) String() string {
 	return b.String()
 }
 
-// wayland.Display events
-func (h *hello) Error(_ proto.ObjectId, _ uint32, msg string) error {
-	return errgo.New("Display error: %s", msg)
-}
-
-func (h *hello) DeleteId(id uint32) error {
-	h.c.DeleteObject(proto.ObjectId(id))
-	return nil
-}
-
-func (h *hello) Go() error {
+// Go sets up the window, giving up once ctx is done, and then handles
+// events until the connection fails.
+func (h *hello) Go(ctx context.Context) error {
 	if err := h.loadImage(); err != nil {
 		return errgo.Trace(err)
 	}
 
-	if err := h.getRegistry(); err != nil {
+	if err := h.getRegistry(ctx); err != nil {
 		return errgo.Trace(err)
 	}
 	if err := h.bindCompositor(); err != nil {
@@ -112,11 +100,11 @@ func (h *hello) Go() error {
 		return errgo.Trace(err)
 	}
 
-	if err := h.createShellSurface(); err != nil {
+	if err := h.createShellSurface(ctx); err != nil {
 		return errgo.Trace(err)
 	}
 
-	if err := h.bindShm(); err != nil {
+	if err := h.bindShm(ctx); err != nil {
 		return errgo.Trace(err)
 	}
 	if err := h.createShmPool(); err != nil {
@@ -175,13 +163,13 @@ func (h *hello) loadImage() error {
 	return nil
 }
 
-func (h *hello) getRegistry() error {
-	h.registry = h.wlClient.NewRegistry(h)
-	if err := h.display.GetRegistry(h.registry.Id()); err != nil {
+func (h *hello) getRegistry(ctx context.Context) error {
+	var err error
+	if h.registry, err = h.display.GetRegistry(wayland.ClientRegistryListener{OnGlobal: h.Global}); err != nil {
 		return errgo.Trace(err)
 	}
 
-	if err := h.sync(); err != nil {
+	if err := h.c.Roundtrip(ctx); err != nil {
 		return errgo.Trace(err)
 	}
 
@@ -190,19 +178,15 @@ func (h *hello) getRegistry() error {
 
 // wayland.Registry events
 func (h *hello) Global(name uint32, interface_ string, version uint32) error {
-	h.globals = append(h.globals, global{name, interface_, version})
-	return nil
-}
-
-func (h *hello) GlobalRemove(_ uint32) error {
+	h.globals = append(h.globals, proto.Global{Name: name, Interface: interface_, Version: version})
 	return nil
 }
 
 func (h *hello) bindCompositor() error {
 	for _, g := range h.globals {
 		if g.Interface == "wl_compositor" {
-			h.compositor = h.wlClient.NewCompositor(h)
-			if err := h.registry.Bind(g.Name, g.Interface, g.Version, h.compositor.Id()); err != nil {
+			var err error
+			if h.compositor, err = wayland.BindCompositor(h.registry, g, 3, h); err != nil {
 				return errgo.Trace(err)
 			}
 
@@ -213,28 +197,19 @@ func (h *hello) bindCompositor() error {
 }
 
 func (h *hello) createSurface() error {
-	h.surface = h.wlClient.NewSurface(h)
-	if err := h.compositor.CreateSurface(h.surface.Id()); err != nil {
+	var err error
+	if h.surface, err = h.compositor.CreateSurface(wayland.ClientSurfaceListener{}); err != nil {
 		return errgo.Trace(err)
 	}
 	return nil
 }
 
-// wayland.Surface events
-func (h *hello) Enter(_ proto.ObjectId) error {
-	return nil
-}
-
-func (h *hello) Leave(_ proto.ObjectId) error {
-	return nil
-}
-
-func (h *hello) createShellSurface() error {
+func (h *hello) createShellSurface(ctx context.Context) error {
 	// bind xdg_shell
 	for _, g := range h.globals {
 		if g.Interface == "xdg_shell" {
-			h.shell = h.xdgClient.NewShell(h)
-			if err := h.registry.Bind(g.Name, g.Interface, g.Version, h.shell.Id()); err != nil {
+			var err error
+			if h.shell, err = xdg_shell.BindShell(h.registry, g, 1, h); err != nil {
 				return errgo.Trace(err)
 			}
 
@@ -248,13 +223,13 @@ bound:
 		return errgo.Trace(err)
 	}
 
-	if err := h.sync(); err != nil {
+	if err := h.c.Roundtrip(ctx); err != nil {
 		return errgo.Trace(err)
 	}
 
 	// create shell surface
-	h.shellSurface = h.xdgClient.NewSurface(h)
-	if err := h.shell.GetXdgSurface(h.shellSurface.Id(), h.surface.Id()); err != nil {
+	var err error
+	if h.shellSurface, err = h.shell.GetXdgSurface(xdg_shell.ClientSurfaceListener{}, h.surface); err != nil {
 		return errgo.Trace(err)
 	}
 
@@ -274,32 +249,11 @@ func (h *hello) Ping(serial uint32) error {
 	return nil
 }
 
-// xdg_shell.ShellSurface events
-func (h *hello) Activated() error {
-	return nil
-}
-
-func (h *hello) ChangeState(_, _, _ uint32) error {
-	return nil
-}
-
-func (h *hello) Close() error {
-	return nil
-}
-
-func (h *hello) Configure(_, _ int32) error {
-	return nil
-}
-
-func (h *hello) Deactivated() error {
-	return nil
-}
-
-func (h *hello) bindShm() error {
+func (h *hello) bindShm(ctx context.Context) error {
 	for _, g := range h.globals {
 		if g.Interface == "wl_shm" {
-			h.shm = h.wlClient.NewShm(h)
-			if err := h.registry.Bind(g.Name, g.Interface, g.Version, h.shm.Id()); err != nil {
+			var err error
+			if h.shm, err = wayland.BindShm(h.registry, g, 1, wayland.ClientShmListener{OnFormat: h.Format}); err != nil {
 				return errgo.Trace(err)
 			}
 			goto formats
@@ -309,47 +263,48 @@ func (h *hello) bindShm() error {
 
 formats:
 	// collect shm formats
-	if err := h.sync(); err != nil {
+	if err := h.c.Roundtrip(ctx); err != nil {
 		return errgo.Trace(err)
 	}
-	return nil
+	for _, f := range h.shmFormats {
+		if f == wayland.SHM_FORMAT_ARGB8888 {
+			return nil
+		}
+	}
+	return errgo.New("wl_shm does not support ARGB8888")
 }
 
 // wayland.Shm events
-func (h *hello) Format(_ uint32) error {
+func (h *hello) Format(format wayland.ShmFormat) error {
+	h.shmFormats = append(h.shmFormats, format)
 	return nil
 }
 
 func (h *hello) createShmPool() error {
-	h.shmPool = h.wlClient.NewShmPool(h)
-	if err := h.shm.CreatePool(h.shmPool.Id(), h.imgShm.Fd(), int32(len(h.imgMap))); err != nil {
+	var err error
+	if h.shmPool, err = h.shm.CreatePool(h, h.imgShm.Fd(), int32(len(h.imgMap))); err != nil {
 		return errgo.Trace(err)
 	}
 	return nil
 }
 
 func (h *hello) createBuffer() error {
-	h.buffer = h.wlClient.NewBuffer(h)
-	if err := h.shmPool.CreateBuffer(
-		h.buffer.Id(), // Id
-		0,             // Offset
-		h.imgW,        // Width
-		h.imgH,        // Height
-		h.imgW*4,      // Stride
-		0,             // Format
+	var err error
+	if h.buffer, err = h.shmPool.CreateBuffer(
+		wayland.ClientBufferListener{}, // Implementation
+		0,                              // Offset
+		h.imgW,                         // Width
+		h.imgH,                         // Height
+		h.imgW*4,                       // Stride
+		wayland.SHM_FORMAT_ARGB8888,    // Format
 	); err != nil {
 		return errgo.Trace(err)
 	}
 	return nil
 }
 
-// wayland.Buffer events
-func (h *hello) Release() error {
-	return nil
-}
-
 func (h *hello) attach() error {
-	if err := h.surface.Attach(h.buffer.Id(), 0, 0); err != nil {
+	if err := h.surface.Attach(h.buffer, 0, 0); err != nil {
 		return errgo.Trace(err)
 	}
 	if err := h.surface.Damage(0, 0, h.imgW, h.imgH); err != nil {
@@ -363,35 +318,12 @@ func (h *hello) attach() error {
 
 func (h *hello) loop() error {
 	for {
-		if err := h.c.Next(); err != nil {
+		if _, err := h.c.Dispatch(context.Background()); err != nil {
 			return errgo.Trace(err)
 		}
 	}
 }
 
-type callback struct {
-	done bool
-}
-
-func (cb *callback) Done(_ uint32) error {
-	cb.done = true
-	return nil
-}
-
-func (h *hello) sync() error {
-	cb := new(callback)
-	scb := h.wlClient.NewCallback(cb)
-	if err := h.display.Sync(scb.Id()); err != nil {
-		return errgo.Trace(err)
-	}
-	for !cb.done {
-		if err := h.c.Next(); err != nil {
-			return errgo.Trace(err)
-		}
-	}
-	return nil
-}
-
 func main() {
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "Usage: %s <image>\n", os.Args[0])
@@ -410,7 +342,9 @@ func main() {
 	defer c.Close()
 
 	h := newHello(c, flag.Arg(0))
-	if err := h.Go(); err != nil {
+	setup, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := h.Go(setup); err != nil {
 		log.Fatal(errgo.DetailedErrorStack(err, errgo.Default))
 	}
 }
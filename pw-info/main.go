@@ -1,8 +1,10 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/errgo/errgo"
 
@@ -10,12 +12,6 @@ import (
 	"github.com/vasiliyl/playwand/proto/wayland"
 )
 
-type global struct {
-	Name      uint32
-	Interface string
-	Version   uint32
-}
-
 type geometry struct {
 	X, Y int32
 	W, H int32
@@ -26,11 +22,11 @@ type info struct {
 
 	wlClient wayland.Client
 
-	display  wayland.ClientDisplay
-	registry wayland.ClientRegistry
-	output   wayland.ClientOutput
+	display  *wayland.ClientDisplay
+	registry *wayland.ClientRegistry
+	output   *wayland.ClientOutput
 
-	globals    []global
+	globals    []proto.Global
 	geometries []geometry
 }
 
@@ -39,16 +35,16 @@ func newInfo(c *proto.Conn) *info {
 		c:        c,
 		wlClient: wayland.NewClient(c),
 	}
-	i.display = i.wlClient.NewDisplay(i)
+	i.display = i.wlClient.NewDisplayWithId(proto.DisplayId, 1, wayland.ClientDisplayListener{})
 	return i
 }
 
-func (i *info) Go() error {
-	if err := i.getRegistry(); err != nil {
+func (i *info) Go(ctx context.Context) error {
+	if err := i.getRegistry(ctx); err != nil {
 		return errgo.Trace(err)
 	}
 
-	if err := i.bindOutput(); err != nil {
+	if err := i.bindOutput(ctx); err != nil {
 		return errgo.Trace(err)
 	}
 
@@ -66,23 +62,13 @@ func (i *info) Print() {
 	}
 }
 
-// wayland.Display events
-func (i *info) Error(_ proto.ObjectId, _ uint32, msg string) error {
-	return errgo.New("Display error: %s", msg)
-}
-
-func (i *info) DeleteId(id uint32) error {
-	i.c.DeleteObject(proto.ObjectId(id))
-	return nil
-}
-
-func (i *info) getRegistry() error {
-	i.registry = i.wlClient.NewRegistry(i)
-	if err := i.display.GetRegistry(i.registry.Id()); err != nil {
+func (i *info) getRegistry(ctx context.Context) error {
+	var err error
+	if i.registry, err = i.display.GetRegistry(wayland.ClientRegistryListener{OnGlobal: i.Global}); err != nil {
 		return errgo.Trace(err)
 	}
 
-	if err := i.sync(); err != nil {
+	if err := i.c.Roundtrip(ctx); err != nil {
 		return errgo.Trace(err)
 	}
 
@@ -91,16 +77,12 @@ func (i *info) getRegistry() error {
 
 // wayland.Registry events
 func (i *info) Global(name uint32, interface_ string, version uint32) error {
-	i.globals = append(i.globals, global{Name: name, Interface: interface_, Version: version})
-	return nil
-}
-
-func (i *info) GlobalRemove(_ uint32) error {
+	i.globals = append(i.globals, proto.Global{Name: name, Interface: interface_, Version: version})
 	return nil
 }
 
-func (i *info) bindOutput() error {
-	var og global
+func (i *info) bindOutput(ctx context.Context) error {
+	var og proto.Global
 	for _, g := range i.globals {
 		if g.Interface == "wl_output" {
 			og = g
@@ -110,12 +92,12 @@ func (i *info) bindOutput() error {
 	return errgo.New("no output registered")
 
 bind:
-	i.output = i.wlClient.NewOutput(i)
-	if err := i.registry.Bind(og.Name, og.Interface, og.Version, i.output.Id()); err != nil {
+	var err error
+	if i.output, err = wayland.BindOutput(i.registry, og, 2, wayland.ClientOutputListener{OnGeometry: i.Geometry}); err != nil {
 		return errgo.Trace(err)
 	}
 
-	if err := i.sync(); err != nil {
+	if err := i.c.Roundtrip(ctx); err != nil {
 		return errgo.Trace(err)
 	}
 
@@ -123,48 +105,11 @@ bind:
 }
 
 // wayland.Output events
-func (i *info) Geometry(x, y, w, h int32, _ int32, _, _ string, _ int32) error {
+func (i *info) Geometry(x, y, w, h int32, _ wayland.OutputSubpixel, _, _ string, _ wayland.OutputTransform) error {
 	i.geometries = append(i.geometries, geometry{})
 	return nil
 }
 
-func (i *info) Mode(_ uint32, _, _, _ int32) error {
-	return nil
-}
-
-func (i *info) Done() error {
-	return nil
-}
-
-func (i *info) Scale(_ int32) error {
-	return nil
-}
-
-type callback struct {
-	done bool
-}
-
-func (cb *callback) Done(_ uint32) error {
-	cb.done = true
-	return nil
-}
-
-func (i *info) sync() error {
-	cb := new(callback)
-	scb := i.wlClient.NewCallback(cb)
-	log.Printf("scb.Id() = %d", scb.Id())
-	if err := i.display.Sync(scb.Id()); err != nil {
-		return errgo.Trace(err)
-	}
-	for !cb.done {
-		if err := i.c.Next(); err != nil {
-			log.Printf("scb.Id() = %d, err = %s", scb.Id(), err)
-			return errgo.Trace(err)
-		}
-	}
-	return nil
-}
-
 func main() {
 	c, err := proto.Dial()
 	if err != nil {
@@ -174,7 +119,10 @@ func main() {
 
 	i := newInfo(c)
 
-	if err := i.Go(); err != nil {
+	// a compositor that has not answered in 5 seconds is hung
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := i.Go(ctx); err != nil {
 		log.Fatalf("\n%s\n", errgo.DetailedErrorStack(err, errgo.Default))
 	}
 
@@ -1,7 +1,9 @@
 package main
 
 import (
+	"fmt"
 	"log"
+	"syscall"
 
 	"github.com/vasiliyl/playwand/proto"
 )
@@ -159,12 +161,28 @@ func proxy(src, dst *proto.Conn, name string) {
 			break
 		}
 
+		// proxy registers no objects, so received fds stay queued;
+		// forward them with the message they arrived with
+		fds := src.TakeFds()
+		for _, fd := range fds {
+			msg.WriteFd(uintptr(fd))
+		}
+
 		log.Printf("%s: %s", name, msg)
 
-		if err := dst.WriteMessage(msg); err != nil {
+		err = dst.WriteMessage(msg)
+		for _, fd := range fds {
+			syscall.Close(fd)
+		}
+		if err != nil {
 			log.Printf("%s: WriteMessage: %s", name, err)
 			break
 		}
+
+		if err := dst.Flush(); err != nil {
+			log.Printf("%s: Flush: %s", name, err)
+			break
+		}
 	}
 	src.Close()
 	dst.Close()
@@ -172,11 +190,12 @@ func proxy(src, dst *proto.Conn, name string) {
 
 func main() {
 	log.SetFlags(log.Llongfile)
-	l, err := proto.ListenPath("/run/user/1000/wayland-1")
+	l, err := proto.ListenAuto()
 	if err != nil {
 		log.Fatal(err)
 	}
 	defer l.Close()
+	log.Printf("listening on %s", l.Name())
 
 	for {
 		c, err := l.Accept()
@@ -185,6 +204,13 @@ func main() {
 			break
 		}
 
+		client := "unknown client"
+		if cr, err := c.Credentials(); err == nil {
+			client = fmt.Sprintf("%s[%d]", cr.Executable(), cr.Pid)
+			c.SetUserData(cr)
+		}
+		log.Printf("%s connected", client)
+
 		s, err := proto.Dial()
 		if err != nil {
 			log.Print(err)
@@ -192,7 +218,7 @@ func main() {
 			break
 		}
 
-		go proxy(c, s, "c->s")
-		go proxy(s, c, "s->c")
+		go proxy(c, s, client+" c->s")
+		go proxy(s, c, client+" s->c")
 	}
 }